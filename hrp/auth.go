@@ -0,0 +1,503 @@
+package hrp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuthType is the authentication scheme of AuthConfig.
+type AuthType string
+
+// supported authentication schemes, AuthNone disables config auth for one step.
+const (
+	AuthNone   AuthType = "none"
+	AuthBasic  AuthType = "basic"
+	AuthDigest AuthType = "digest"
+	AuthBearer AuthType = "bearer"
+	AuthSigV4  AuthType = "sigv4"
+	AuthHMAC   AuthType = "hmac"
+)
+
+// AuthConfig represents authentication scheme of HTTP requests.
+// step auth overrides config auth, set type none to disable config auth for one step.
+type AuthConfig struct {
+	Type AuthType `json:"type" yaml:"type"` // basic, digest, bearer, sigv4, hmac, none
+	// basic & digest
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	// bearer
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+	// AWS Signature Version 4
+	AccessKey    string `json:"access_key,omitempty" yaml:"access_key,omitempty"`
+	SecretKey    string `json:"secret_key,omitempty" yaml:"secret_key,omitempty"`
+	SessionToken string `json:"session_token,omitempty" yaml:"session_token,omitempty"`
+	Region       string `json:"region,omitempty" yaml:"region,omitempty"`
+	Service      string `json:"service,omitempty" yaml:"service,omitempty"`
+	// HMAC HTTP signatures, e.g. Signature keyId="xxx",algorithm="hmac-sha256",headers="...",signature="xxx"
+	KeyID     string   `json:"key_id,omitempty" yaml:"key_id,omitempty"`
+	Secret    string   `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Algorithm string   `json:"algorithm,omitempty" yaml:"algorithm,omitempty"` // hmac-sha256(default), hmac-sha1, hmac-sha512
+	Headers   []string `json:"headers,omitempty" yaml:"headers,omitempty"`     // signed headers, default: (request-target) host date [digest]
+}
+
+// NewBasicAuth returns auth config of HTTP basic authentication.
+func NewBasicAuth(username, password string) *AuthConfig {
+	return &AuthConfig{Type: AuthBasic, Username: username, Password: password}
+}
+
+// NewDigestAuth returns auth config of HTTP digest authentication.
+func NewDigestAuth(username, password string) *AuthConfig {
+	return &AuthConfig{Type: AuthDigest, Username: username, Password: password}
+}
+
+// NewBearerAuth returns auth config of bearer token.
+func NewBearerAuth(token string) *AuthConfig {
+	return &AuthConfig{Type: AuthBearer, Token: token}
+}
+
+// NewSigV4Auth returns auth config of AWS Signature Version 4.
+func NewSigV4Auth(accessKey, secretKey, region, service string) *AuthConfig {
+	return &AuthConfig{
+		Type: AuthSigV4, AccessKey: accessKey, SecretKey: secretKey,
+		Region: region, Service: service,
+	}
+}
+
+// NewHMACAuth returns auth config of HMAC HTTP signatures.
+func NewHMACAuth(keyID, secret string) *AuthConfig {
+	return &AuthConfig{Type: AuthHMAC, KeyID: keyID, Secret: secret}
+}
+
+// selectAuth returns step auth if specified, otherwise config auth.
+func selectAuth(config, step *AuthConfig) *AuthConfig {
+	if step != nil && step.Type != "" {
+		if step.Type == AuthNone {
+			return nil
+		}
+		return step
+	}
+	if config == nil || config.Type == "" || config.Type == AuthNone {
+		return nil
+	}
+	return config
+}
+
+// parse parses variables and functions in auth settings, e.g. token: $token
+func (a *AuthConfig) parse(parser *Parser, variables map[string]interface{}) (*AuthConfig, error) {
+	if a == nil {
+		return nil, nil
+	}
+	parsed := *a
+	for _, field := range []*string{
+		&parsed.Username, &parsed.Password, &parsed.Token,
+		&parsed.AccessKey, &parsed.SecretKey, &parsed.SessionToken, &parsed.Region, &parsed.Service,
+		&parsed.KeyID, &parsed.Secret,
+	} {
+		if *field == "" {
+			continue
+		}
+		value, err := parser.ParseString(*field, variables)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse %s auth failed", a.Type)
+		}
+		*field = convertString(value)
+	}
+	return &parsed, nil
+}
+
+// apply sets authentication headers of prepared request, body is the prepared request body.
+// digest auth is applied when server responds with challenge, see doRequestWithAuth.
+func (a *AuthConfig) apply(req *http.Request, body []byte) error {
+	switch a.Type {
+	case AuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case AuthBearer:
+		if a.Token == "" {
+			return errors.New("missing token for bearer auth")
+		}
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case AuthDigest:
+		return nil
+	case AuthSigV4:
+		return signSigV4(req, body, a, time.Now().UTC())
+	case AuthHMAC:
+		return signHMAC(req, body, a, time.Now().UTC())
+	default:
+		return errors.Errorf("unsupported auth type: %s", a.Type)
+	}
+	return nil
+}
+
+// doRequestWithAuth sends request, and resends it with digest credentials
+// if server responds with digest challenge.
+func doRequestWithAuth(client *http.Client, req *http.Request, auth *AuthConfig, body []byte) (*http.Request, *http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil || auth == nil || auth.Type != AuthDigest || resp.StatusCode != http.StatusUnauthorized {
+		return req, resp, err
+	}
+	challenge, ok := parseDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok {
+		return req, resp, nil
+	}
+	// drain response body to reuse connection
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	authorization, err := challenge.authorize(auth.Username, auth.Password,
+		req.Method, req.URL.RequestURI(), body, newCnonce(), 1)
+	if err != nil {
+		return req, nil, err
+	}
+	authReq := req.Clone(req.Context())
+	if body != nil {
+		authReq.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	authReq.Header.Set("Authorization", authorization)
+	resp, err = client.Do(authReq)
+	return authReq, resp, err
+}
+
+// digestChallenge represents HTTP digest challenge in WWW-Authenticate header, RFC 7616.
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+func parseDigestChallenge(headers []string) (*digestChallenge, bool) {
+	for _, header := range headers {
+		if len(header) < 7 || !strings.EqualFold(header[:7], "Digest ") {
+			continue
+		}
+		params := parseAuthParams(header[7:])
+		c := &digestChallenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+		}
+		// prefer auth over auth-int
+		for _, qop := range strings.Split(params["qop"], ",") {
+			qop = strings.TrimSpace(qop)
+			if qop == "auth" || (qop == "auth-int" && c.qop == "") {
+				c.qop = qop
+			}
+		}
+		return c, true
+	}
+	return nil, false
+}
+
+// parseAuthParams parses comma separated auth params, values may be quoted strings.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return params
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " ")
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			if i < len(s) {
+				i++ // skip closing quote
+			}
+			s = s[i:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[key] = value
+	}
+}
+
+func (c *digestChallenge) authorize(username, password, method, uri string, body []byte,
+	cnonce string, nc int,
+) (string, error) {
+	var newHash func() hash.Hash
+	algorithm := strings.ToUpper(c.algorithm)
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	case "SHA-512-256":
+		newHash = sha512.New512_256
+	default:
+		return "", errors.Errorf("unsupported digest algorithm: %s", c.algorithm)
+	}
+	h := func(s string) string {
+		hasher := newHash()
+		hasher.Write([]byte(s))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	ha1 := h(username + ":" + c.realm + ":" + password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+	if c.qop == "auth-int" {
+		ha2 = h(method + ":" + uri + ":" + h(string(body)))
+	}
+
+	var response string
+	ncValue := fmt.Sprintf("%08x", nc)
+	if c.qop == "" {
+		response = h(ha1 + ":" + c.nonce + ":" + ha2)
+	} else {
+		response = h(strings.Join([]string{ha1, c.nonce, ncValue, cnonce, c.qop, ha2}, ":"))
+	}
+
+	params := []string{
+		fmt.Sprintf(`username="%s"`, username),
+		fmt.Sprintf(`realm="%s"`, c.realm),
+		fmt.Sprintf(`nonce="%s"`, c.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+	}
+	if c.algorithm != "" {
+		params = append(params, "algorithm="+c.algorithm)
+	}
+	if c.qop != "" {
+		params = append(params, "qop="+c.qop, "nc="+ncValue, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	params = append(params, fmt.Sprintf(`response="%s"`, response))
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf(`opaque="%s"`, c.opaque))
+	}
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+func newCnonce() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// signSigV4 signs request with AWS Signature Version 4, signature is set in Authorization header.
+// reference: https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func signSigV4(req *http.Request, body []byte, auth *AuthConfig, now time.Time) error {
+	if auth.AccessKey == "" || auth.SecretKey == "" {
+		return errors.New("missing access_key or secret_key for sigv4 auth")
+	}
+	if auth.Region == "" || auth.Service == "" {
+		return errors.New("missing region or service for sigv4 auth")
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := hexSHA256(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if auth.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", auth.SessionToken)
+	}
+	if auth.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	// canonical headers: host, content-type and x-amz-* headers
+	headers := map[string]string{"host": req.Host}
+	if req.Host == "" {
+		headers["host"] = req.URL.Host
+	}
+	for key, values := range req.Header {
+		key = strings.ToLower(key)
+		if key == "content-type" || strings.HasPrefix(key, "x-amz-") {
+			trimmed := make([]string, len(values))
+			for i, v := range values {
+				trimmed[i] = strings.Join(strings.Fields(v), " ")
+			}
+			headers[key] = strings.Join(trimmed, ",")
+		}
+	}
+	signedHeaders := make([]string, 0, len(headers))
+	for key := range headers {
+		signedHeaders = append(signedHeaders, key)
+	}
+	sort.Strings(signedHeaders)
+	var canonicalHeaders strings.Builder
+	for _, key := range signedHeaders {
+		canonicalHeaders.WriteString(key + ":" + headers[key] + "\n")
+	}
+
+	// URI path is encoded twice except for S3
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if auth.Service != "s3" {
+		path = sigV4Escape(path, false)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		sigV4CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, auth.Region, auth.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, scope, hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSum(sha256.New, []byte("AWS4"+auth.SecretKey), []byte(date))
+	for _, s := range []string{auth.Region, auth.Service, "aws4_request"} {
+		key = hmacSum(sha256.New, key, []byte(s))
+	}
+	signature := hex.EncodeToString(hmacSum(sha256.New, key, []byte(stringToSign)))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		auth.AccessKey, scope, strings.Join(signedHeaders, ";"), signature))
+	return nil
+}
+
+func sigV4CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(key, true)+"="+sigV4Escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// sigV4Escape escapes string with RFC 3986 unreserved characters kept, slash is kept if not encodeSlash.
+func sigV4Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// signHMAC signs request with HMAC HTTP signatures, signature is set in Authorization header.
+// reference: https://datatracker.ietf.org/doc/html/draft-cavage-http-signatures-12
+func signHMAC(req *http.Request, body []byte, auth *AuthConfig, now time.Time) error {
+	if auth.KeyID == "" || auth.Secret == "" {
+		return errors.New("missing key_id or secret for hmac auth")
+	}
+	var newHash func() hash.Hash
+	algorithm := strings.ToLower(auth.Algorithm)
+	switch algorithm {
+	case "", "hmac-sha256":
+		algorithm = "hmac-sha256"
+		newHash = sha256.New
+	case "hmac-sha1":
+		newHash = sha1.New
+	case "hmac-sha512":
+		newHash = sha512.New
+	default:
+		return errors.Errorf("unsupported hmac algorithm: %s", auth.Algorithm)
+	}
+
+	signedHeaders := auth.Headers
+	if len(signedHeaders) == 0 {
+		signedHeaders = []string{"(request-target)", "host", "date"}
+		if len(body) > 0 {
+			signedHeaders = append(signedHeaders, "digest")
+		}
+	}
+
+	var lines []string
+	for _, name := range signedHeaders {
+		name = strings.ToLower(name)
+		var value string
+		switch name {
+		case "(request-target)":
+			value = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case "host":
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		case "date":
+			if req.Header.Get("Date") == "" {
+				req.Header.Set("Date", now.Format(http.TimeFormat))
+			}
+			value = req.Header.Get("Date")
+		case "digest":
+			if req.Header.Get("Digest") == "" {
+				sum := sha256.Sum256(body)
+				req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+			}
+			value = req.Header.Get("Digest")
+		default:
+			values := req.Header.Values(name)
+			if len(values) == 0 {
+				return errors.Errorf("missing signed header %s for hmac auth", name)
+			}
+			value = strings.Join(values, ", ")
+		}
+		lines = append(lines, name+": "+value)
+	}
+
+	signature := base64.StdEncoding.EncodeToString(
+		hmacSum(newHash, []byte(auth.Secret), []byte(strings.Join(lines, "\n"))))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		`Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		auth.KeyID, algorithm, strings.ToLower(strings.Join(signedHeaders, " ")), signature))
+	return nil
+}
+
+func hmacSum(newHash func() hash.Hash, key, data []byte) []byte {
+	mac := hmac.New(newHash, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package hrp
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestAuthorize(t *testing.T) {
+	// example from RFC 2617 section 3.5
+	challenge, ok := parseDigestChallenge([]string{
+		`Basic realm="ignored"`,
+		`Digest realm="testrealm@host.com", qop="auth,auth-int", ` +
+			`nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`,
+	})
+	if !assert.True(t, ok) || !assert.Equal(t, "auth", challenge.qop) {
+		t.Fatal()
+	}
+	authorization, err := challenge.authorize("Mufasa", "Circle Of Life",
+		"GET", "/dir/index.html", nil, "0a4f113b", 1)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	if !assert.Contains(t, authorization, `response="6629fae49393a05397450978507c4ef1"`) ||
+		!assert.Contains(t, authorization, "nc=00000001") ||
+		!assert.Contains(t, authorization, `opaque="5ccc069c403ebaf9f0171e9517f40e41"`) {
+		t.Fatal()
+	}
+
+	challenge.algorithm = "SHA-1"
+	_, err = challenge.authorize("Mufasa", "Circle Of Life", "GET", "/", nil, "0a4f113b", 1)
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+}
+
+func TestSignSigV4(t *testing.T) {
+	// get-vanilla from AWS Signature Version 4 test suite
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	auth := NewSigV4Auth("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service")
+	if !assert.Nil(t, signSigV4(req, nil, auth, now)) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date")) {
+		t.Fatal()
+	}
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if !assert.Equal(t, expected, req.Header.Get("Authorization")) {
+		t.Fatal()
+	}
+
+	if !assert.Equal(t, "a=1&a=2&b=x%20y&c=%2F", sigV4CanonicalQuery(map[string][]string{
+		"b": {"x y"}, "a": {"2", "1"}, "c": {"/"},
+	})) {
+		t.Fatal()
+	}
+
+	// missing credentials
+	if !assert.Error(t, signSigV4(req, nil, &AuthConfig{Type: AuthSigV4}, now)) {
+		t.Fatal()
+	}
+}
+
+func TestSignHMAC(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://example.com/foo?param=value", nil)
+	body := []byte(`{"hello": "world"}`)
+	now := time.Date(2014, 1, 5, 21, 31, 40, 0, time.UTC)
+	if !assert.Nil(t, signHMAC(req, body, NewHMACAuth("test-key", "secret"), now)) {
+		t.Fatal()
+	}
+
+	digest := sha256.Sum256(body)
+	signingString := strings.Join([]string{
+		"(request-target): post /foo?param=value",
+		"host: example.com",
+		"date: Sun, 05 Jan 2014 21:31:40 GMT",
+		"digest: SHA-256=" + base64.StdEncoding.EncodeToString(digest[:]),
+	}, "\n")
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(signingString))
+	expected := fmt.Sprintf(`Signature keyId="test-key",algorithm="hmac-sha256",`+
+		`headers="(request-target) host date digest",signature="%s"`,
+		base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	if !assert.Equal(t, expected, req.Header.Get("Authorization")) {
+		t.Fatal()
+	}
+
+	// missing signed header
+	auth := NewHMACAuth("test-key", "secret")
+	auth.Headers = []string{"x-request-id"}
+	if !assert.Error(t, signHMAC(req, body, auth, now)) {
+		t.Fatal()
+	}
+}
+
+func newAuthServer() *httptest.Server {
+	const nonce = "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	h := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.Trim(r.URL.Path, "/") {
+		case "basic":
+			username, password, ok := r.BasicAuth()
+			if !ok || username != "user" || password != "passwd" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "bearer":
+			if r.Header.Get("Authorization") != "Bearer token123" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "digest":
+			params := parseAuthParams(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "))
+			ha1 := h("user:hrp:passwd")
+			ha2 := h(r.Method + ":" + r.URL.RequestURI())
+			expected := h(strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+			if params["response"] != expected || params["uri"] != r.URL.RequestURI() {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="hrp", qop="auth", nonce="%s"`, nonce))
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRunRequestWithAuth(t *testing.T) {
+	ts := newAuthServer()
+	defer ts.Close()
+
+	testcase := &TestCase{
+		Config: NewConfig("auth").
+			SetBaseURL(ts.URL).
+			WithVariables(map[string]interface{}{"token": "token123"}).
+			SetAuth(NewBearerAuth("$token")),
+		TestSteps: []IStep{
+			NewStep("config bearer auth").
+				GET("/bearer").
+				Validate().
+				AssertEqual("status_code", 200, "check status code"),
+			NewStep("step basic auth").
+				POST("/basic").
+				SetAuth(NewBasicAuth("user", "passwd")).
+				WithBody(map[string]interface{}{"foo": "bar"}).
+				Validate().
+				AssertEqual("status_code", 200, "check status code"),
+			NewStep("step digest auth").
+				POST("/digest").
+				WithParams(map[string]interface{}{"foo": "bar"}).
+				SetAuth(NewDigestAuth("user", "passwd")).
+				WithBody(map[string]interface{}{"foo": "bar"}).
+				Validate().
+				AssertEqual("status_code", 200, "check status code"),
+			NewStep("disable config auth").
+				GET("/bearer").
+				SetAuth(&AuthConfig{Type: AuthNone}).
+				Validate().
+				AssertEqual("status_code", 401, "check status code"),
+		},
+	}
+
+	caseRunner, _ := NewRunner(t).NewCaseRunner(testcase)
+	sessionRunner := caseRunner.NewSession()
+	if err := sessionRunner.Start(nil); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	if !assert.Len(t, summary.Records, 4) {
+		t.Fatal()
+	}
+	// auth settings are not recorded, digest authorization is recorded in request headers
+	request := summary.Records[2].Data.(*SessionData).ReqResps.Request.(map[string]interface{})
+	if !assert.NotContains(t, request, "auth") ||
+		!assert.Contains(t, request["headers"].(map[string]string)["Authorization"], "Digest ") {
+		t.Fatal()
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
 	"github.com/httprunner/httprunner/v4/hrp/internal/code"
+	"github.com/httprunner/httprunner/v4/hrp/internal/env"
 	"github.com/httprunner/httprunner/v4/hrp/internal/json"
 	"github.com/httprunner/httprunner/v4/hrp/internal/sdk"
 	"github.com/httprunner/httprunner/v4/hrp/pkg/boomer"
@@ -30,6 +31,16 @@ func NewStandaloneBoomer(spawnCount int64, spawnRate float64) *HRPBoomer {
 	return b
 }
 
+func NewArrivalRateBoomer(rate, preAllocatedVUs, maxVUs int64) *HRPBoomer {
+	b := &HRPBoomer{
+		Boomer:       boomer.NewArrivalRateBoomer(rate, preAllocatedVUs, maxVUs),
+		pluginsMutex: new(sync.RWMutex),
+	}
+
+	b.hrpRunner = NewRunner(nil)
+	return b
+}
+
 func NewMasterBoomer(masterBindHost string, masterBindPort int) *HRPBoomer {
 	b := &HRPBoomer{
 		Boomer:       boomer.NewMasterBoomer(masterBindHost, masterBindPort),
@@ -65,6 +76,10 @@ func (b *HRPBoomer) InitBoomer() {
 	if b.GetProfile().PrometheusPushgatewayURL != "" {
 		b.AddOutput(boomer.NewPrometheusPusherOutput(b.GetProfile().PrometheusPushgatewayURL, "hrp", b.GetMode()))
 	}
+	// report is generated by master in distributed mode
+	if b.GetProfile().GenReport && b.GetMode() != "worker" {
+		b.AddOutput(boomer.NewReportOutput(env.ResultsPath, b.GetProfile))
+	}
 	b.SetSpawnCount(b.GetProfile().SpawnCount)
 	b.SetSpawnRate(b.GetProfile().SpawnRate)
 	b.SetRunTime(b.GetProfile().RunTime)
@@ -72,6 +87,10 @@ func (b *HRPBoomer) InitBoomer() {
 		b.SetLoopCount(b.GetProfile().LoopCount)
 	}
 	b.SetRateLimiter(b.GetProfile().MaxRPS, b.GetProfile().RequestIncreaseRate)
+	if err := b.SetStages(b.GetProfile().Stages); err != nil {
+		log.Error().Err(err).Msg("invalid stages")
+		os.Exit(code.GetErrorCode(err))
+	}
 	b.SetDisableKeepAlive(b.GetProfile().DisableKeepalive)
 	b.SetDisableCompression(b.GetProfile().DisableCompression)
 	b.SetClientTransport()
@@ -107,6 +126,9 @@ func (b *HRPBoomer) Run(testcases ...ITestCase) {
 			}
 			return true
 		})
+
+		// close grpc connections after load testing stopped
+		b.hrpRunner.closeGRPCConns()
 	}()
 
 	taskSlice := b.ConvertTestCasesToBoomerTasks(testcases...)
@@ -122,6 +144,8 @@ func (b *HRPBoomer) ConvertTestCasesToBoomerTasks(testcases ...ITestCase) (taskS
 		os.Exit(code.GetErrorCode(err))
 	}
 
+	b.setThresholds(testCases)
+
 	for _, testcase := range testCases {
 		rendezvousList := initRendezvous(testcase, int64(b.GetSpawnCount()))
 		task := b.convertBoomerTask(testcase, rendezvousList)
@@ -155,6 +179,7 @@ func (b *HRPBoomer) TestCasesToBytes(testcases ...ITestCase) []byte {
 		log.Error().Err(err).Msg("failed to load testcases")
 		os.Exit(code.GetErrorCode(err))
 	}
+	b.setThresholds(testCases)
 	tcs := b.ParseTestCases(testCases)
 	testCasesBytes, err := json.Marshal(tcs)
 	if err != nil {
@@ -173,6 +198,21 @@ func (b *HRPBoomer) BytesToTCases(testCasesBytes []byte) []*TCase {
 	return testcase
 }
 
+// setThresholds sets thresholds of profile and config of testcases, which are checked during load testing.
+func (b *HRPBoomer) setThresholds(testCases []*TestCase) {
+	var thresholds []*boomer.Threshold
+	if profile := b.GetProfile(); profile != nil {
+		thresholds = append(thresholds, profile.Thresholds...)
+	}
+	for _, testcase := range testCases {
+		thresholds = append(thresholds, testcase.Config.Thresholds...)
+	}
+	if err := b.SetThresholds(thresholds); err != nil {
+		log.Error().Err(err).Msg("invalid thresholds")
+		os.Exit(code.GetErrorCode(err))
+	}
+}
+
 func (b *HRPBoomer) Quit() {
 	b.Boomer.Quit()
 }
@@ -354,7 +394,9 @@ func (b *HRPBoomer) convertBoomerTask(testcase *TestCase, rendezvousList []*Rend
 
 			mutex.Lock()
 			if parametersIterator.HasNext() {
-				sessionRunner.InitWithParameters(parametersIterator.Next())
+				parameters, parametersIndex := parametersIterator.next()
+				sessionRunner.InitWithParameters(parameters)
+				sessionRunner.setParametersIndex(parametersIndex)
 			}
 			mutex.Unlock()
 
@@ -375,6 +417,20 @@ func (b *HRPBoomer) convertBoomerTask(testcase *TestCase, rendezvousList []*Rend
 				once.Do(func() {
 					b.Boomer.ResetStartTime()
 				})
+				// check step conditions, skipped step will not be recorded
+				skipReason, err := sessionRunner.checkStepCondition(step.Struct())
+				if err != nil {
+					b.RecordFailure(string(step.Type()), stepName, 0, err.Error())
+					testcaseSuccess = false
+					transactionSuccess = false
+					if b.hrpRunner.failfast {
+						log.Error().Err(err).Msg("abort running due to failfast setting")
+						break
+					}
+					continue
+				} else if skipReason != "" {
+					continue
+				}
 				stepResult, err := step.Run(sessionRunner)
 				// update step result name with parsed step name
 				stepResult.Name = stepName
@@ -1,8 +1,16 @@
 package hrp
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/code"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/boomer"
 )
 
 func TestBoomerStandaloneRun(t *testing.T) {
@@ -32,3 +40,46 @@ func TestBoomerStandaloneRun(t *testing.T) {
 	time.Sleep(5 * time.Second)
 	b.Quit()
 }
+
+func TestBoomerThresholds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	testcase := &TestCase{
+		Config: NewConfig("thresholds").
+			SetBaseURL(server.URL).
+			SetThresholds(&boomer.Threshold{
+				Conditions:    []string{"fail_ratio < 1%"},
+				AbortOnBreach: true,
+			}),
+		TestSteps: []IStep{
+			NewStep("get").
+				GET("/get").
+				Validate().
+				AssertEqual("status_code", 200, "check status code"),
+		},
+	}
+
+	b := NewStandaloneBoomer(1, 1)
+	done := make(chan bool)
+	go func() {
+		b.Run(testcase)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		b.Quit()
+		t.Fatal("load testing should be aborted when threshold is breached")
+	}
+
+	results := b.GetThresholdResults()
+	if !assert.Len(t, results, 1) || !assert.False(t, results[0].Passed) || !assert.True(t, results[0].Aborted) {
+		t.Fatal()
+	}
+	if err := b.CheckThresholds(); !assert.Equal(t, code.ThresholdsBreached, errors.Cause(err)) {
+		t.Fatal()
+	}
+}
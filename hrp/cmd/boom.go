@@ -11,6 +11,7 @@ import (
 
 	"github.com/httprunner/httprunner/v4/hrp"
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/env"
 	"github.com/httprunner/httprunner/v4/hrp/internal/sdk"
 	"github.com/httprunner/httprunner/v4/hrp/pkg/boomer"
 )
@@ -22,7 +23,8 @@ var boomCmd = &cobra.Command{
 	Long:  `run yaml/json testcase files for load test`,
 	Example: `  $ hrp boom demo.json	# run specified json testcase file
   $ hrp boom demo.yaml	# run specified yaml testcase file
-  $ hrp boom examples/	# run testcases in specified folder`,
+  $ hrp boom examples/	# run testcases in specified folder
+  $ hrp boom demo.yaml --arrival-rate 100 --max-vus 50	# start 100 iterations per second with at most 50 users`,
 	Args: cobra.MinimumNArgs(0),
 	PreRun: func(cmd *cobra.Command, args []string) {
 		boomer.SetUlimit(10240) // ulimit -n 10240
@@ -63,6 +65,8 @@ var boomCmd = &cobra.Command{
 			hrpBoomer = hrp.NewMasterBoomer(boomArgs.masterBindHost, boomArgs.masterBindPort)
 		} else if boomArgs.worker {
 			hrpBoomer = hrp.NewWorkerBoomer(boomArgs.masterHost, boomArgs.masterPort)
+		} else if boomArgs.ArrivalRate > 0 {
+			hrpBoomer = hrp.NewArrivalRateBoomer(boomArgs.ArrivalRate, boomArgs.PreAllocatedVUs, boomArgs.MaxVUs)
 		} else {
 			hrpBoomer = hrp.NewStandaloneBoomer(boomArgs.SpawnCount, boomArgs.SpawnRate)
 		}
@@ -83,22 +87,26 @@ var boomCmd = &cobra.Command{
 			if boomArgs.autoStart {
 				hrpBoomer.InitBoomer()
 			} else {
+				// report is generated for runs started by HTTP API with gen-report enabled
+				hrpBoomer.AddOutput(boomer.NewReportOutput(env.ResultsPath, hrpBoomer.GetProfile))
 				go hrpBoomer.StartServer(ctx, boomArgs.masterHttpAddress)
 			}
 			go hrpBoomer.PollTestCases(ctx)
 			hrpBoomer.RunMaster()
+			return hrpBoomer.CheckThresholds()
 		case "worker":
 			if boomArgs.ignoreQuit {
 				hrpBoomer.SetIgnoreQuit()
 			}
 			go hrpBoomer.PollTasks(ctx)
 			hrpBoomer.RunWorker()
-		case "standalone":
+		case "standalone", "arrival-rate":
 			if venv != "" {
 				hrpBoomer.SetPython3Venv(venv)
 			}
 			hrpBoomer.InitBoomer()
 			hrpBoomer.Run(paths...)
+			return hrpBoomer.CheckThresholds()
 		}
 		return nil
 	},
@@ -127,6 +135,9 @@ func init() {
 
 	boomCmd.Flags().Int64Var(&boomArgs.MaxRPS, "max-rps", 0, "Max RPS that boomer can generate, disabled by default.")
 	boomCmd.Flags().StringVar(&boomArgs.RequestIncreaseRate, "request-increase-rate", "-1", "Request increase rate, disabled by default.")
+	boomCmd.Flags().Int64Var(&boomArgs.ArrivalRate, "arrival-rate", 0, "Start iterations at the specified rate per second with open-model executor, disabled by default.")
+	boomCmd.Flags().Int64Var(&boomArgs.PreAllocatedVUs, "pre-allocated-vus", 1, "The number of users to pre-allocate for arrival rate. Only used with --arrival-rate.")
+	boomCmd.Flags().Int64Var(&boomArgs.MaxVUs, "max-vus", 0, "The max number of users for arrival rate, iterations are dropped when all users are busy. Only used with --arrival-rate. Defaults to --pre-allocated-vus.")
 	boomCmd.Flags().Int64Var(&boomArgs.SpawnCount, "spawn-count", 1, "The number of users to spawn for load testing")
 	boomCmd.Flags().Float64Var(&boomArgs.SpawnRate, "spawn-rate", 1, "The rate for spawning users")
 	boomCmd.Flags().Int64Var(&boomArgs.RunTime, "run-time", 0, "Stop after the specified amount of time(s), Only used  --autostart. Defaults to run forever.")
@@ -139,6 +150,7 @@ func init() {
 	boomCmd.Flags().BoolVar(&boomArgs.DisableConsoleOutput, "disable-console-output", false, "Disable console output.")
 	boomCmd.Flags().BoolVar(&boomArgs.DisableCompression, "disable-compression", false, "Disable compression")
 	boomCmd.Flags().BoolVar(&boomArgs.DisableKeepalive, "disable-keepalive", false, "Disable keepalive")
+	boomCmd.Flags().BoolVarP(&boomArgs.GenReport, "gen-report", "g", false, "Generate HTML and JSON report to results directory at the end of load testing")
 	boomCmd.Flags().StringVar(&boomArgs.profile, "profile", "", "profile for load testing")
 	boomCmd.Flags().BoolVar(&boomArgs.master, "master", false, "master of distributed testing")
 	boomCmd.Flags().StringVar(&boomArgs.masterBindHost, "master-bind-host", "127.0.0.1", "Interfaces (hostname, ip) that hrp master should bind to. Only used when running with --master. Defaults to * (all available interfaces).")
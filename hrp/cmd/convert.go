@@ -29,6 +29,10 @@ var convertCmd = &cobra.Command{
 			fromType = convert.FromTypePostman
 		} else if fromHARFlag {
 			fromType = convert.FromTypeHAR
+		} else if fromSwaggerFlag {
+			fromType = convert.FromTypeSwagger
+		} else if fromJMeterFlag {
+			fromType = convert.FromTypeJMeter
 		} else if fromCurlFlag {
 			fromType = convert.FromTypeCurl
 		} else {
@@ -98,6 +102,8 @@ var (
 	fromPostmanFlag bool
 	fromHARFlag     bool
 	fromCurlFlag    bool
+	fromSwaggerFlag bool
+	fromJMeterFlag  bool
 
 	toJSONFlag   bool
 	toYAMLFlag   bool
@@ -112,6 +118,8 @@ func init() {
 	convertCmd.Flags().BoolVar(&fromHARFlag, "from-har", false, "load from HAR format")
 	convertCmd.Flags().BoolVar(&fromPostmanFlag, "from-postman", false, "load from postman format")
 	convertCmd.Flags().BoolVar(&fromCurlFlag, "from-curl", false, "load from curl format")
+	convertCmd.Flags().BoolVar(&fromSwaggerFlag, "from-swagger", false, "load from swagger 2.0 or openapi 3.x format, one case per tag or path")
+	convertCmd.Flags().BoolVar(&fromJMeterFlag, "from-jmeter", false, "load from jmeter test plan (.jmx) format")
 
 	convertCmd.Flags().BoolVar(&toJSONFlag, "to-json", true, "convert to JSON case scripts")
 	convertCmd.Flags().BoolVar(&toYAMLFlag, "to-yaml", false, "convert to YAML case scripts")
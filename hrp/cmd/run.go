@@ -13,7 +13,11 @@ var runCmd = &cobra.Command{
 	Long:  `run yaml/json testcase files for API test`,
 	Example: `  $ hrp run demo.json	# run specified json testcase file
   $ hrp run demo.yaml	# run specified yaml testcase file
-  $ hrp run examples/	# run testcases in specified folder`,
+  $ hrp run examples/	# run testcases in specified folder
+  $ hrp run examples/ --parallel 8	# run testcases in specified folder with 8 workers
+  $ hrp run examples/ --update-snapshots	# rewrite response snapshots
+  $ hrp run examples/ --report junit,html	# generate JUnit XML and HTML reports
+  $ hrp run examples/ --allure-dir allure-results	# export allure results`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var paths []hrp.ITestCase
@@ -35,6 +39,10 @@ var (
 	saveTests         bool
 	genHTMLReport     bool
 	caseTimeout       float32
+	parallel          int
+	updateSnapshots   bool
+	reportFormats     []string
+	allureDir         string
 )
 
 func init() {
@@ -47,13 +55,20 @@ func init() {
 	runCmd.Flags().BoolVarP(&saveTests, "save-tests", "s", false, "save tests summary")
 	runCmd.Flags().BoolVarP(&genHTMLReport, "gen-html-report", "g", false, "generate html report")
 	runCmd.Flags().Float32Var(&caseTimeout, "case-timeout", 3600, "set testcase timeout (seconds)")
+	runCmd.Flags().IntVar(&parallel, "parallel", 1, "run testcases and parameters concurrently with specified number of workers")
+	runCmd.Flags().StringSliceVar(&reportFormats, "report", []string{}, "generate reports in specified formats, e.g. junit,tap,html,json,allure")
+	runCmd.Flags().StringVar(&allureDir, "allure-dir", "", "export allure results to specified dir")
+	runCmd.Flags().BoolVar(&updateSnapshots, "update-snapshots", false, "rewrite response snapshots with current responses")
 }
 
 func makeHRPRunner() *hrp.HRPRunner {
 	runner := hrp.NewRunner(nil).
 		SetFailfast(!continueOnFailure).
 		SetSaveTests(saveTests).
-		SetCaseTimeout(caseTimeout)
+		SetCaseTimeout(caseTimeout).
+		SetConcurrency(parallel).
+		SetUpdateSnapshots(updateSnapshots).
+		SetReportFormats(reportFormats...)
 	if genHTMLReport {
 		runner.GenHTMLReport()
 	}
@@ -72,5 +87,8 @@ func makeHRPRunner() *hrp.HRPRunner {
 	if proxyUrl != "" {
 		runner.SetProxyUrl(proxyUrl)
 	}
+	if allureDir != "" {
+		runner.SetAllureDir(allureDir)
+	}
 	return runner
 }
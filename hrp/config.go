@@ -4,6 +4,7 @@ import (
 	"reflect"
 
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/boomer"
 	"github.com/httprunner/httprunner/v4/hrp/pkg/uixt"
 )
 
@@ -35,8 +36,14 @@ type TConfig struct {
 	CaseTimeout       float32                `json:"case_timeout,omitempty" yaml:"case_timeout,omitempty"`       // testcase timeout in seconds
 	Export            []string               `json:"export,omitempty" yaml:"export,omitempty"`
 	Weight            int                    `json:"weight,omitempty" yaml:"weight,omitempty"`
-	Path              string                 `json:"path,omitempty" yaml:"path,omitempty"`     // testcase file path
-	PluginSetting     *PluginConfig          `json:"plugin,omitempty" yaml:"plugin,omitempty"` // plugin config
+	Path              string                 `json:"path,omitempty" yaml:"path,omitempty"`             // testcase file path
+	PluginSetting     *PluginConfig          `json:"plugin,omitempty" yaml:"plugin,omitempty"`         // plugin config
+	OpenAPI           string                 `json:"openapi,omitempty" yaml:"openapi,omitempty"`       // OpenAPI/Swagger spec path for contract validation
+	TLS               *TLSConfig             `json:"tls,omitempty" yaml:"tls,omitempty"`               // TLS settings for HTTPS requests, e.g. CA bundle and client certificate
+	Auth              *AuthConfig            `json:"auth,omitempty" yaml:"auth,omitempty"`             // auth scheme for all request steps, e.g. bearer token
+	Proxies           map[string]string      `json:"proxies,omitempty" yaml:"proxies,omitempty"`       // proxies for all request steps, keys: http, https, all, no_proxy
+	OAuth2            *OAuth2Config          `json:"oauth2,omitempty" yaml:"oauth2,omitempty"`         // OAuth2 token management for request steps
+	Thresholds        []*boomer.Threshold    `json:"thresholds,omitempty" yaml:"thresholds,omitempty"` // pass/fail thresholds for load testing
 }
 
 // WithVariables sets variables for current testcase.
@@ -81,6 +88,40 @@ func (c *TConfig) SetRequestTimeout(seconds float32) *TConfig {
 	return c
 }
 
+// SetTLS sets TLS settings for HTTPS requests of current testcase, e.g. CA bundle and client certificate for mutual TLS.
+func (c *TConfig) SetTLS(tlsConfig *TLSConfig) *TConfig {
+	c.TLS = tlsConfig
+	return c
+}
+
+// SetAuth sets auth scheme for all request steps of current testcase, e.g. NewBearerAuth("$token").
+// auth settings are parsed with step variables, thus extracted variables can be referenced.
+func (c *TConfig) SetAuth(auth *AuthConfig) *TConfig {
+	c.Auth = auth
+	return c
+}
+
+// SetProxies sets proxies for all request steps of current testcase, supports http, https and socks5 proxy urls,
+// e.g. {"http": "http://proxy.corp.com:8080", "https": "http://proxy.corp.com:8080", "no_proxy": ".internal.com"}
+func (c *TConfig) SetProxies(proxies map[string]string) *TConfig {
+	c.Proxies = proxies
+	return c
+}
+
+// SetOAuth2 sets OAuth2 settings of current testcase, access token is fetched once per session,
+// refreshed when expired or rejected with 401, and injected into request steps without other auth.
+func (c *TConfig) SetOAuth2(oauth2 *OAuth2Config) *TConfig {
+	c.OAuth2 = oauth2
+	return c
+}
+
+// SetOpenAPI sets OpenAPI/Swagger spec file path, relative to project root dir.
+// when set, each request step is validated against the matching operation in spec.
+func (c *TConfig) SetOpenAPI(path string) *TConfig {
+	c.OpenAPI = path
+	return c
+}
+
 // SetCaseTimeout sets testcase timeout in seconds.
 func (c *TConfig) SetCaseTimeout(seconds float32) *TConfig {
 	c.CaseTimeout = seconds
@@ -99,6 +140,12 @@ func (c *TConfig) SetWeight(weight int) *TConfig {
 	return c
 }
 
+// SetThresholds sets pass/fail thresholds for current testcase, which are checked in load testing.
+func (c *TConfig) SetThresholds(thresholds ...*boomer.Threshold) *TConfig {
+	c.Thresholds = thresholds
+	return c
+}
+
 func (c *TConfig) SetWebSocket(times, interval, timeout, size int64) *TConfig {
 	c.WebSocketSetting = &WebSocketConfig{
 		ReconnectionTimes:    times,
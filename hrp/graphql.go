@@ -0,0 +1,241 @@
+package hrp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/graphql"
+)
+
+// GraphQLRequest represents GraphQL operation of HTTP request, which is sent as JSON body
+// {"query", "variables", "operationName"}, or as URL params for GET requests.
+type GraphQLRequest struct {
+	Query          string                 `json:"query,omitempty" yaml:"query,omitempty"`                     // query document, $xxx are GraphQL variables and not parsed
+	QueryFile      string                 `json:"query_file,omitempty" yaml:"query_file,omitempty"`           // file path of query document, relative to project root dir
+	Variables      map[string]interface{} `json:"variables,omitempty" yaml:"variables,omitempty"`             // GraphQL variables, could reference step variables
+	OperationName  string                 `json:"operation_name,omitempty" yaml:"operation_name,omitempty"`   // required if query document has multiple operations
+	AllowErrors    bool                   `json:"allow_errors,omitempty" yaml:"allow_errors,omitempty"`       // do not fail step if response has errors
+	ValidateSchema bool                   `json:"validate_schema,omitempty" yaml:"validate_schema,omitempty"` // validate query against schema from introspection before sending
+}
+
+// NewGraphQL returns GraphQL request of query document and variables.
+func NewGraphQL(query string, variables map[string]interface{}) *GraphQLRequest {
+	return &GraphQLRequest{Query: query, Variables: variables}
+}
+
+// graphqlFieldTags are searched in response of GraphQL request, e.g. data.user.name
+var graphqlFieldTags = []string{"data", "errors"}
+
+// prepareGraphQL builds GraphQL payload, query document is not parsed with step variables
+// since GraphQL variables use the same $xxx syntax.
+func (r *requestBuilder) prepareGraphQL(stepVariables map[string]interface{}) error {
+	gql := r.stepRequest.GraphQL
+	if r.stepRequest.Body != nil {
+		return errors.New("graphql and body should not be set at the same time")
+	}
+
+	query := gql.Query
+	if gql.QueryFile != "" {
+		queryFile, err := r.parser.ParseString(gql.QueryFile, stepVariables)
+		if err != nil {
+			return errors.Wrap(err, "parse graphql query file failed")
+		}
+		path := convertString(queryFile)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.rootDir, path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "read graphql query file failed")
+		}
+		query = string(content)
+	}
+	if strings.TrimSpace(query) == "" {
+		return errors.New("graphql query is empty")
+	}
+
+	payload := map[string]interface{}{"query": query}
+	if len(gql.Variables) > 0 {
+		variables, err := r.parser.Parse(gql.Variables, stepVariables)
+		if err != nil {
+			return errors.Wrap(err, "parse graphql variables failed")
+		}
+		payload["variables"] = variables
+	}
+	if gql.OperationName != "" {
+		operationName, err := r.parser.ParseString(gql.OperationName, stepVariables)
+		if err != nil {
+			return errors.Wrap(err, "parse graphql operation name failed")
+		}
+		payload["operationName"] = convertString(operationName)
+	}
+	r.graphqlPayload = payload
+
+	// GraphQL over HTTP GET, variables are JSON encoded
+	if r.req.Method == http.MethodGet {
+		params := r.req.URL.Query()
+		params.Set("query", query)
+		if variables, ok := payload["variables"]; ok {
+			variablesJSON, err := json.Marshal(variables)
+			if err != nil {
+				return errors.Wrap(err, "marshal graphql variables failed")
+			}
+			params.Set("variables", string(variablesJSON))
+		}
+		if operationName, ok := payload["operationName"]; ok {
+			params.Set("operationName", operationName.(string))
+		}
+		r.req.URL.RawQuery = params.Encode()
+		r.requestMap["url"] = r.req.URL.String()
+		return nil
+	}
+
+	dataBytes, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "marshal graphql payload failed")
+	}
+	if r.req.Header.Get("Content-Type") == "" {
+		r.req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	r.requestMap["body"] = payload
+	r.body = dataBytes
+	r.req.Body = io.NopCloser(bytes.NewReader(dataBytes))
+	r.req.ContentLength = int64(len(dataBytes))
+	return nil
+}
+
+// validateGraphQL validates prepared GraphQL query against schema of endpoint before sending.
+func (r *HRPRunner) validateGraphQL(client *http.Client, rb *requestBuilder) error {
+	schema, err := r.getGraphQLSchema(client, rb.req)
+	if err != nil {
+		return err
+	}
+	query, _ := rb.graphqlPayload["query"].(string)
+	operationName, _ := rb.graphqlPayload["operationName"].(string)
+	variables, _ := rb.graphqlPayload["variables"].(map[string]interface{})
+	violations := graphql.Validate(schema, query, operationName, variables)
+	if len(violations) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, violation := range violations {
+		log.Error().Str("url", rb.req.URL.String()).Msg(violation.Error())
+		messages = append(messages, violation.Error())
+	}
+	return errors.Errorf("graphql query validation failed: %s", strings.Join(messages, "; "))
+}
+
+// getGraphQLSchema returns schema of GraphQL endpoint loaded by introspection query with request headers,
+// schemas are loaded once and shared by all session runners.
+func (r *HRPRunner) getGraphQLSchema(client *http.Client, req *http.Request) (*ast.Schema, error) {
+	endpoint := *req.URL
+	endpoint.RawQuery = ""
+	endpoint.Fragment = ""
+	key := endpoint.String()
+	r.graphqlMutex.Lock()
+	defer r.graphqlMutex.Unlock()
+	if schema, ok := r.graphqlSchemas[key]; ok {
+		return schema, nil
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"query":         graphql.IntrospectionQuery,
+		"operationName": "IntrospectionQuery",
+	})
+	introspectionReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, key, bytes.NewReader(payload))
+	if err != nil {
+		return nil, errors.Wrap(err, "create graphql introspection request failed")
+	}
+	introspectionReq.Header = req.Header.Clone()
+	introspectionReq.Header.Set("Content-Type", "application/json; charset=utf-8")
+	introspectionReq.Host = req.Host
+	resp, err := client.Do(introspectionReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "do graphql introspection request failed")
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read graphql introspection response failed")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("graphql introspection failed with status code %d", resp.StatusCode)
+	}
+	schema, err := graphql.LoadSchema(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "load graphql schema failed")
+	}
+	if r.graphqlSchemas == nil {
+		r.graphqlSchemas = make(map[string]*ast.Schema)
+	}
+	r.graphqlSchemas[key] = schema
+	log.Info().Str("url", key).Int("types", len(schema.Types)).Msg("load graphql schema by introspection")
+	return schema, nil
+}
+
+// ValidateGraphQL fails validation if GraphQL response has errors,
+// the result is appended to validation results as an extra validator.
+func (v *responseObject) ValidateGraphQL() error {
+	meta, ok := v.respObjMeta.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	body, _ := meta["body"].(map[string]interface{})
+	gqlErrors, _ := body["errors"].([]interface{})
+	validResult := &ValidationResult{
+		Validator: Validator{
+			Check:   "body.errors",
+			Assert:  "graphql_no_errors",
+			Message: "check GraphQL response has no errors",
+		},
+		CheckValue:  gqlErrors,
+		CheckResult: "pass",
+	}
+	v.validationResults = append(v.validationResults, validResult)
+	if len(gqlErrors) == 0 {
+		return nil
+	}
+
+	validResult.CheckResult = "fail"
+	v.t.Fail()
+	var messages []string
+	for _, gqlError := range gqlErrors {
+		log.Error().Interface("error", gqlError).Msg("graphql response error")
+		if e, ok := gqlError.(map[string]interface{}); ok {
+			messages = append(messages, convertString(e["message"]))
+		}
+	}
+	return errors.Errorf("graphql response has errors: %s", strings.Join(messages, "; "))
+}
+
+// graphqlRespObjMeta returns response with data and errors of GraphQL response body
+// at top level, thus they could be searched directly, e.g. data.user.name
+func (v *responseObject) graphqlRespObjMeta() interface{} {
+	meta, ok := v.respObjMeta.(map[string]interface{})
+	if !ok {
+		return v.respObjMeta
+	}
+	body, ok := meta["body"].(map[string]interface{})
+	if !ok {
+		return v.respObjMeta
+	}
+	result := make(map[string]interface{}, len(meta)+2)
+	for k, value := range meta {
+		result[k] = value
+	}
+	for _, tag := range graphqlFieldTags {
+		if value, ok := body[tag]; ok {
+			result[tag] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,214 @@
+package hrp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const graphqlIntrospection = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": null,
+  "subscriptionType": null,
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}, "defaultValue": null}
+      ], "type": {"kind": "OBJECT", "name": "User", "ofType": null}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}},
+      {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String", "ofType": null}}
+    ]},
+    {"kind": "SCALAR", "name": "ID", "fields": null},
+    {"kind": "SCALAR", "name": "String", "fields": null}
+  ]
+}}}`
+
+// newGraphQLServer starts GraphQL server which resolves user by id variable,
+// queries and introspection queries are counted separately.
+func newGraphQLServer(t *testing.T) (server *httptest.Server, queries, introspections *int32) {
+	queries, introspections = new(int32), new(int32)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Query         string                 `json:"query"`
+			Variables     map[string]interface{} `json:"variables"`
+			OperationName string                 `json:"operationName"`
+		}
+		if r.Method == http.MethodGet {
+			payload.Query = r.URL.Query().Get("query")
+			payload.OperationName = r.URL.Query().Get("operationName")
+			_ = json.Unmarshal([]byte(r.URL.Query().Get("variables")), &payload.Variables)
+		} else {
+			if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(payload.Query, "__schema") {
+			atomic.AddInt32(introspections, 1)
+			_, _ = w.Write([]byte(graphqlIntrospection))
+			return
+		}
+		atomic.AddInt32(queries, 1)
+		id, _ := payload.Variables["id"].(string)
+		if id != "1" {
+			_, _ = w.Write([]byte(`{"data": {"user": null}, "errors": [{"message": "user ` + id + ` not found", "path": ["user"]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"user": {"id": "1", "name": "hrp", "operation": "` + payload.OperationName + `"}}}`))
+	}))
+	return server, queries, introspections
+}
+
+func TestRunGraphQLRequest(t *testing.T) {
+	server, queries, introspections := newGraphQLServer(t)
+	defer server.Close()
+
+	queryFile := filepath.Join(t.TempDir(), "user.graphql")
+	err := os.WriteFile(queryFile, []byte(`
+query GetUser($id: ID!) { user(id: $id) { id name } }
+query GetUserName($id: ID!) { user(id: $id) { name } }
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcase := &TestCase{
+		Config: NewConfig("graphql").
+			SetBaseURL(server.URL).
+			WithVariables(map[string]interface{}{"uid": "1", "query_file": queryFile}),
+		TestSteps: []IStep{
+			NewStep("query with variables").
+				POST("/graphql").
+				SetGraphQL(NewGraphQL(`query ($id: ID!) { user(id: $id) { id name } }`,
+					map[string]interface{}{"id": "$uid"})).
+				Extract().
+				WithJmesPath("data.user.name", "user_name").
+				Validate().
+				AssertEqual("status_code", 200, "check status code").
+				AssertEqual("data.user.id", "1", "check user id").
+				AssertEqual("body.data.user.name", "hrp", "check user name in body"),
+			NewStep("query file with operation name").
+				POST("/graphql").
+				SetGraphQL(&GraphQLRequest{
+					QueryFile:      "$query_file",
+					OperationName:  "GetUserName",
+					Variables:      map[string]interface{}{"id": "1"},
+					ValidateSchema: true,
+				}).
+				Validate().
+				AssertEqual("data.user.operation", "GetUserName", "check operation name"),
+			NewStep("query over GET").
+				GET("/graphql").
+				SetGraphQL(&GraphQLRequest{
+					Query:          `query GetUser($id: ID!) { user(id: $id) { name } }`,
+					Variables:      map[string]interface{}{"id": "1"},
+					ValidateSchema: true,
+				}).
+				Validate().
+				AssertEqual("data.user.name", "$user_name", "check user name"),
+			NewStep("allow errors").
+				POST("/graphql").
+				SetGraphQL(&GraphQLRequest{
+					Query:       `query ($id: ID!) { user(id: $id) { name } }`,
+					Variables:   map[string]interface{}{"id": "2"},
+					AllowErrors: true,
+				}).
+				Validate().
+				AssertEqual("errors[0].message", "user 2 not found", "check error message").
+				AssertEqual("data.user", nil, "check null user"),
+		},
+	}
+	caseRunner, err := NewRunner(t).NewCaseRunner(testcase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionRunner := caseRunner.NewSession()
+	if err := sessionRunner.Start(nil); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	if !assert.Equal(t, "hrp", summary.Records[0].ExportVars["user_name"]) ||
+		!assert.Equal(t, int32(4), atomic.LoadInt32(queries)) ||
+		!assert.Equal(t, int32(1), atomic.LoadInt32(introspections)) {
+		t.Fatal()
+	}
+	reqResps := summary.Records[0].Data.(*SessionData).ReqResps
+	request := reqResps.Request.(map[string]interface{})
+	if !assert.Equal(t, map[string]interface{}{"id": "1"}, request["body"].(map[string]interface{})["variables"]) ||
+		!assert.NotContains(t, request, "graphql") {
+		t.Fatal()
+	}
+}
+
+func TestRunGraphQLRequestFailed(t *testing.T) {
+	server, queries, _ := newGraphQLServer(t)
+	defer server.Close()
+
+	testData := []struct {
+		step     IStep
+		expected string
+	}{
+		{
+			// response errors fail step by default
+			step: NewStep("response errors").
+				POST(server.URL + "/graphql").
+				SetGraphQL(NewGraphQL(`query ($id: ID!) { user(id: $id) { name } }`,
+					map[string]interface{}{"id": "2"})),
+			expected: "graphql response has errors: user 2 not found",
+		},
+		{
+			// invalid query is not sent
+			step: NewStep("invalid query").
+				POST(server.URL + "/graphql").
+				SetGraphQL(&GraphQLRequest{
+					Query:          `{ user(id: "1") { nmae } }`,
+					ValidateSchema: true,
+				}),
+			expected: `graphql query validation failed: query:1: Cannot query field "nmae" on type "User".`,
+		},
+		{
+			// variables are validated against variable definitions
+			step: NewStep("invalid variables").
+				POST(server.URL + "/graphql").
+				SetGraphQL(&GraphQLRequest{
+					Query:          `query ($id: ID!) { user(id: $id) { name } }`,
+					ValidateSchema: true,
+				}),
+			expected: `graphql query validation failed: input: variable.id must be defined`,
+		},
+		{
+			step: NewStep("graphql with body").
+				POST(server.URL + "/graphql").
+				WithBody(map[string]interface{}{"query": "{ user { id } }"}).
+				SetGraphQL(NewGraphQL(`{ user { id } }`, nil)),
+			expected: "graphql and body should not be set at the same time",
+		},
+	}
+	for _, data := range testData {
+		testcase := &TestCase{
+			Config:    NewConfig("graphql"),
+			TestSteps: []IStep{data.step},
+		}
+		err := NewRunner(nil).Run(testcase)
+		if !assert.Error(t, err) || !assert.Contains(t, err.Error(), data.expected) {
+			t.Fatal()
+		}
+	}
+	if !assert.Equal(t, int32(1), atomic.LoadInt32(queries)) {
+		t.Fatal()
+	}
+}
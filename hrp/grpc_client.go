@@ -0,0 +1,248 @@
+package hrp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/protoparse"
+)
+
+// parseGRPCTarget returns server address and whether to use TLS, e.g.
+// localhost:50051 and grpc://localhost:50051 use plaintext, grpcs://example.com uses TLS on port 443.
+func parseGRPCTarget(target string) (address string, secure bool, err error) {
+	if !strings.Contains(target, "://") {
+		return target, false, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", false, errors.Wrap(err, "parse grpc target failed")
+	}
+	switch u.Scheme {
+	case "grpc", "http":
+	case "grpcs", "https":
+		secure = true
+	default:
+		return "", false, errors.Errorf("unsupported grpc target scheme: %s", u.Scheme)
+	}
+	address = u.Host
+	if u.Port() == "" {
+		if secure {
+			address += ":443"
+		} else {
+			address += ":80"
+		}
+	}
+	return address, secure, nil
+}
+
+// getGRPCConn returns gRPC client connection of server, connections are created once and shared by all session runners.
+func (r *HRPRunner) getGRPCConn(address string, secure bool, profile *clientProfile, rootDir string) (*grpc.ClientConn, error) {
+	key := fmt.Sprintf("%s|%v|%s", address, secure, profile.key(rootDir))
+	r.grpcMutex.Lock()
+	defer r.grpcMutex.Unlock()
+	if conn, ok := r.grpcConns[key]; ok {
+		return conn, nil
+	}
+
+	creds := insecure.NewCredentials()
+	if secure {
+		tlsConfig, err := profile.tls.load(rootDir, profile.verify)
+		if err != nil {
+			return nil, errors.Wrap(err, "load tls config failed")
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, errors.Wrap(err, "dial grpc server failed")
+	}
+	if r.grpcConns == nil {
+		r.grpcConns = make(map[string]*grpc.ClientConn)
+	}
+	r.grpcConns[key] = conn
+	log.Info().Str("address", address).Bool("secure", secure).Msg("create grpc connection")
+	return conn, nil
+}
+
+// closeGRPCConns closes gRPC connections after runner or boomer finishes,
+// connections are created again if runner is reused.
+func (r *HRPRunner) closeGRPCConns() {
+	r.grpcMutex.Lock()
+	defer r.grpcMutex.Unlock()
+	for key, conn := range r.grpcConns {
+		if err := conn.Close(); err != nil {
+			log.Warn().Err(err).Str("target", conn.Target()).Msg("close grpc connection failed")
+		}
+		delete(r.grpcConns, key)
+	}
+}
+
+// getGRPCFiles returns file descriptors to resolve gRPC service, loaded from proto files if specified,
+// otherwise from server reflection. Descriptors are loaded once and shared by all session runners.
+func (r *HRPRunner) getGRPCFiles(conn *grpc.ClientConn, service string, protoFiles, importPaths []string,
+) (*protoregistry.Files, error) {
+	key := "reflection|" + conn.Target() + "|" + service
+	if len(protoFiles) > 0 {
+		key = "files|" + strings.Join(protoFiles, ",") + "|" + strings.Join(importPaths, ",")
+	}
+	r.grpcMutex.Lock()
+	defer r.grpcMutex.Unlock()
+	if files, ok := r.grpcFiles[key]; ok {
+		return files, nil
+	}
+
+	var fds []*descriptorpb.FileDescriptorProto
+	var err error
+	if len(protoFiles) > 0 {
+		fds, err = loadProtoFiles(protoFiles, importPaths)
+	} else {
+		fds, err = reflectProtoFiles(conn, service)
+	}
+	if err != nil {
+		return nil, err
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: fds})
+	if err != nil {
+		return nil, errors.Wrap(err, "create grpc file descriptors failed")
+	}
+	if r.grpcFiles == nil {
+		r.grpcFiles = make(map[string]*protoregistry.Files)
+	}
+	r.grpcFiles[key] = files
+	return files, nil
+}
+
+// loadProtoFiles parses .proto files, or reads descriptor sets generated by
+// protoc --include_imports --descriptor_set_out, e.g. .protoset and .pb files.
+// Proto file names are resolved relative to import paths, the file dir is used as import path if not specified.
+func loadProtoFiles(protoFiles, importPaths []string) ([]*descriptorpb.FileDescriptorProto, error) {
+	var fds []*descriptorpb.FileDescriptorProto
+	loaded := make(map[string]bool)
+	appendFiles := func(files []*descriptorpb.FileDescriptorProto) {
+		for _, fd := range files {
+			if !loaded[fd.GetName()] {
+				loaded[fd.GetName()] = true
+				fds = append(fds, fd)
+			}
+		}
+	}
+	for _, protoFile := range protoFiles {
+		if filepath.Ext(protoFile) != ".proto" {
+			content, err := os.ReadFile(protoFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "read descriptor set failed")
+			}
+			fdSet := &descriptorpb.FileDescriptorSet{}
+			if err := proto.Unmarshal(content, fdSet); err != nil {
+				return nil, errors.Wrapf(err, "unmarshal descriptor set %s failed", protoFile)
+			}
+			appendFiles(fdSet.File)
+			continue
+		}
+
+		parser := &protoparse.Parser{ImportPaths: importPaths}
+		filename := filepath.Base(protoFile)
+		if len(importPaths) == 0 {
+			parser.ImportPaths = []string{filepath.Dir(protoFile)}
+		} else {
+			for _, importPath := range importPaths {
+				if rel, err := filepath.Rel(importPath, protoFile); err == nil && !strings.HasPrefix(rel, "..") {
+					filename = rel
+					break
+				}
+			}
+		}
+		files, err := parser.ParseFiles(filename)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse proto files failed")
+		}
+		appendFiles(files)
+	}
+	return fds, nil
+}
+
+// reflectProtoFiles requests file descriptors of service and its dependencies from server reflection.
+func reflectProtoFiles(conn *grpc.ClientConn, service string) ([]*descriptorpb.FileDescriptorProto, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout*time.Millisecond)
+	defer cancel()
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "request grpc server reflection failed")
+	}
+	defer stream.CloseSend()
+
+	request := func(req *rpb.ServerReflectionRequest) ([]*descriptorpb.FileDescriptorProto, error) {
+		if err := stream.Send(req); err != nil {
+			return nil, errors.Wrap(err, "send grpc reflection request failed")
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, errors.Wrap(err, "receive grpc reflection response failed")
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return nil, errors.Errorf("grpc reflection error: %s", errResp.GetErrorMessage())
+		}
+		var fds []*descriptorpb.FileDescriptorProto
+		for _, content := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(content, fd); err != nil {
+				return nil, errors.Wrap(err, "unmarshal grpc reflection file descriptor failed")
+			}
+			fds = append(fds, fd)
+		}
+		return fds, nil
+	}
+
+	fds, err := request(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// request missing dependencies, well-known types are taken from linked descriptors if server does not have them
+	loaded := make(map[string]bool)
+	for _, fd := range fds {
+		loaded[fd.GetName()] = true
+	}
+	for i := 0; i < len(fds); i++ {
+		for _, dep := range fds[i].Dependency {
+			if loaded[dep] {
+				continue
+			}
+			loaded[dep] = true
+			depFiles, err := request(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				wkt, findErr := protoregistry.GlobalFiles.FindFileByPath(dep)
+				if findErr != nil {
+					return nil, err
+				}
+				depFiles = []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(wkt)}
+			}
+			for _, depFile := range depFiles {
+				if depFile.GetName() == dep || !loaded[depFile.GetName()] {
+					loaded[depFile.GetName()] = true
+					fds = append(fds, depFile)
+				}
+			}
+		}
+	}
+	return fds, nil
+}
@@ -0,0 +1,137 @@
+package hrp
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/http2"
+)
+
+// clientProfile represents HTTP client settings of request step which may differ from default clients.
+type clientProfile struct {
+	verify  bool              // verify server certificate
+	tls     *TLSConfig        // CA bundle and client certificate
+	proxies map[string]string // http, https, all and no_proxy
+}
+
+func (p *clientProfile) isDefault() bool {
+	return p == nil || (!p.verify && p.tls.isEmpty() && len(p.proxies) == 0)
+}
+
+// key returns identifier of profile, file paths are resolved to identify TLS profile.
+func (p *clientProfile) key(rootDir string) string {
+	tlsConfig := p.tls
+	if tlsConfig == nil {
+		tlsConfig = &TLSConfig{}
+	}
+	return fmt.Sprintf("%v|%s|%s|%s|%s|%s|%s", p.verify,
+		resolvePathIfSet(rootDir, tlsConfig.CACert), resolvePathIfSet(rootDir, tlsConfig.ClientCert),
+		resolvePathIfSet(rootDir, tlsConfig.ClientKey), tlsConfig.Password, tlsConfig.ServerName,
+		proxiesKey(p.proxies))
+}
+
+// httpClients holds HTTP clients with the same profile.
+type httpClients struct {
+	httpClient  *http.Client
+	http2Client *http.Client
+}
+
+// getHTTPClient returns HTTP client for profile, default clients skip verifying server certificate,
+// clients with other profiles are created once and reused by all session runners.
+func (r *HRPRunner) getHTTPClient(useHTTP2 bool, profile *clientProfile, rootDir string) (*http.Client, error) {
+	if profile.isDefault() {
+		if useHTTP2 {
+			return r.http2Client, nil
+		}
+		return r.httpClient, nil
+	}
+	if useHTTP2 && len(profile.proxies) > 0 {
+		return nil, errors.New("proxies are not supported for HTTP/2 requests")
+	}
+
+	key := profile.key(rootDir)
+	r.clientsMutex.Lock()
+	defer r.clientsMutex.Unlock()
+	clients, ok := r.clients[key]
+	if !ok {
+		var err error
+		clients, err = r.newHTTPClients(profile, rootDir)
+		if err != nil {
+			return nil, err
+		}
+		if r.clients == nil {
+			r.clients = make(map[string]*httpClients)
+		}
+		r.clients[key] = clients
+		logger := log.Info().Bool("verify", profile.verify).Str("proxies", redactProxies(profile.proxies))
+		if profile.tls != nil {
+			logger = logger.Str("caCert", profile.tls.CACert).Str("clientCert", profile.tls.ClientCert)
+		}
+		logger.Msg("create http client for profile")
+	}
+	if useHTTP2 {
+		return clients.http2Client, nil
+	}
+	return clients.httpClient, nil
+}
+
+// getHTTPClient returns HTTP client of session runner, which shares transport with clients of runner,
+// while cookies of concurrent session are kept in session and request timeout of testcase is applied.
+func (r *SessionRunner) getHTTPClient(useHTTP2 bool, profile *clientProfile) (*http.Client, error) {
+	client, err := r.caseRunner.hrpRunner.getHTTPClient(useHTTP2, profile, r.caseRunner.rootDir)
+	if err != nil {
+		return nil, err
+	}
+	sessionClient := *client
+	if r.cookieJar != nil {
+		sessionClient.Jar = r.cookieJar
+	}
+	if r.caseRunner.requestTimeout != 0 {
+		sessionClient.Timeout = r.caseRunner.requestTimeout
+	}
+	return &sessionClient, nil
+}
+
+// newHTTPClients creates HTTP clients with profile, other transport settings are copied from default clients.
+func (r *HRPRunner) newHTTPClients(profile *clientProfile, rootDir string) (*httpClients, error) {
+	config, err := profile.tls.load(rootDir, profile.verify)
+	if err != nil {
+		return nil, errors.Wrap(err, "load tls config failed")
+	}
+
+	var transport *http.Transport
+	if t, ok := r.httpClient.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = &http.Transport{}
+	}
+	transport.TLSClientConfig = config
+	if len(profile.proxies) > 0 {
+		transport.Proxy, err = newProxyFunc(profile.proxies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	http2Transport := &http2.Transport{
+		TLSClientConfig: config.Clone(),
+	}
+	if t, ok := r.http2Client.Transport.(*http2.Transport); ok {
+		http2Transport.DisableCompression = t.DisableCompression
+	}
+
+	return &httpClients{
+		httpClient: &http.Client{
+			Transport: transport,
+			Jar:       r.httpClient.Jar,
+			Timeout:   r.httpClient.Timeout,
+		},
+		http2Client: &http.Client{
+			Transport: http2Transport,
+			Jar:       r.http2Client.Jar,
+			Timeout:   r.http2Client.Timeout,
+		},
+	}, nil
+}
@@ -0,0 +1,52 @@
+package hrp
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHTTPClientWithProfile(t *testing.T) {
+	tlsDir, _ := filepath.Abs("../examples/data/tls")
+	r := NewRunner(nil)
+
+	// default client skips verifying server certificate
+	client, err := r.getHTTPClient(false, &clientProfile{}, "")
+	if !assert.Nil(t, err) || !assert.Equal(t, r.httpClient, client) {
+		t.Fatal()
+	}
+
+	// clients are reused for the same profile
+	client1, err := r.getHTTPClient(false, &clientProfile{tls: &TLSConfig{CACert: "ca.pem"}}, tlsDir)
+	if !assert.Nil(t, err) || !assert.NotEqual(t, r.httpClient, client1) {
+		t.Fatal()
+	}
+	client2, _ := r.getHTTPClient(false, &clientProfile{tls: &TLSConfig{CACert: "ca.pem"}}, tlsDir)
+	if !assert.Equal(t, client1, client2) {
+		t.Fatal()
+	}
+	transport := client1.Transport.(*http.Transport)
+	if !assert.False(t, transport.TLSClientConfig.InsecureSkipVerify) || !assert.Nil(t, transport.Proxy) {
+		t.Fatal()
+	}
+
+	// proxies differ in profile
+	proxies := map[string]string{"all": "socks5://127.0.0.1:1080"}
+	client3, err := r.getHTTPClient(false, &clientProfile{tls: &TLSConfig{CACert: "ca.pem"}, proxies: proxies}, tlsDir)
+	if !assert.Nil(t, err) || !assert.NotEqual(t, client1, client3) ||
+		!assert.NotNil(t, client3.Transport.(*http.Transport).Proxy) {
+		t.Fatal()
+	}
+	_, err = r.getHTTPClient(true, &clientProfile{proxies: proxies}, tlsDir)
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+
+	// invalid client cert
+	_, err = r.getHTTPClient(false, &clientProfile{tls: &TLSConfig{ClientCert: "client.p12", Password: "wrong"}}, tlsDir)
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+}
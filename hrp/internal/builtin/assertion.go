@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/jsonschema"
 )
 
 var Assertions = map[string]func(t assert.TestingT, actual interface{}, expected interface{}, msgAndArgs ...interface{}) bool{
@@ -47,6 +50,9 @@ var Assertions = map[string]func(t assert.TestingT, actual interface{}, expected
 	"string_equals":            StringEqual,
 	"equal_fold":               EqualFold,
 	"regex_match":              RegexMatch,
+	"assert_json_schema":       AssertJSONSchema,
+	"json_schema":              AssertJSONSchema,
+	"snapshot":                 MatchSnapshot,
 }
 
 func EqualValues(t assert.TestingT, actual, expected interface{}, msgAndArgs ...interface{}) bool {
@@ -185,6 +191,118 @@ func RegexMatch(t assert.TestingT, actual, expected interface{}, msgAndArgs ...i
 	return assert.Regexp(t, expected, actual, msgAndArgs)
 }
 
+// AssertJSONSchema checks if actual value is valid against JSON schema,
+// expected could be compiled *jsonschema.Schema, schema object or inline JSON string.
+func AssertJSONSchema(t assert.TestingT, actual, expected interface{}, msgAndArgs ...interface{}) bool {
+	violations, err := ValidateJSONSchema(actual, expected)
+	if err != nil {
+		return assert.Fail(t, err.Error(), msgAndArgs...)
+	}
+	if len(violations) == 0 {
+		return true
+	}
+	var messages []string
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+	return assert.Fail(t, fmt.Sprintf("JSON schema validation failed:\n%s",
+		strings.Join(messages, "\n")), msgAndArgs...)
+}
+
+// ValidateJSONSchema validates actual value against JSON schema, returns violations with JSON pointer paths.
+func ValidateJSONSchema(actual, schema interface{}) ([]*jsonschema.Violation, error) {
+	compiled, ok := schema.(*jsonschema.Schema)
+	if !ok {
+		if str, isString := schema.(string); isString {
+			// inline JSON schema
+			if err := json.Unmarshal([]byte(str), &schema); err != nil {
+				return nil, fmt.Errorf("invalid inline JSON schema: %v", err)
+			}
+		}
+		var err error
+		compiled, err = jsonschema.Compile(schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return compiled.Validate(actual), nil
+}
+
+// Snapshot is the expected value of snapshot assertion.
+type Snapshot struct {
+	Name   string      // snapshot name
+	Value  interface{} // stored snapshot value
+	Ignore []string    // paths of volatile fields to ignore, e.g. data.created_at, items.*.id
+}
+
+// MatchSnapshot checks if actual value equals to the stored snapshot, ignored paths are excluded.
+func MatchSnapshot(t assert.TestingT, actual, expected interface{}, msgAndArgs ...interface{}) bool {
+	snapshot, ok := expected.(*Snapshot)
+	if !ok {
+		return assert.Fail(t, fmt.Sprintf("expected should be *Snapshot, got %T", expected), msgAndArgs...)
+	}
+	actualValue, err := normalizeJSON(actual)
+	if err != nil {
+		return assert.Fail(t, fmt.Sprintf("normalize actual value failed: %v", err), msgAndArgs...)
+	}
+	expectedValue, err := normalizeJSON(snapshot.Value)
+	if err != nil {
+		return assert.Fail(t, fmt.Sprintf("normalize snapshot value failed: %v", err), msgAndArgs...)
+	}
+	for _, path := range snapshot.Ignore {
+		keys := strings.Split(path, ".")
+		actualValue = removePath(actualValue, keys)
+		expectedValue = removePath(expectedValue, keys)
+	}
+	return assert.Equal(t, expectedValue, actualValue,
+		append([]interface{}{fmt.Sprintf("snapshot %s mismatch", snapshot.Name)}, msgAndArgs...)...)
+}
+
+// normalizeJSON converts value to generic JSON types by JSON round trip.
+func normalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+// removePath removes the field located by keys, * matches all keys of object or all items of array.
+func removePath(value interface{}, keys []string) interface{} {
+	if len(keys) == 0 {
+		return value
+	}
+	key, rest := keys[0], keys[1:]
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			if key != "*" && key != k {
+				continue
+			}
+			if len(rest) == 0 {
+				delete(v, k)
+			} else {
+				v[k] = removePath(v[k], rest)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			if key != "*" && key != fmt.Sprint(i) {
+				continue
+			}
+			if len(rest) > 0 {
+				v[i] = removePath(v[i], rest)
+			} else {
+				// keep array length, replace ignored item with nil
+				v[i] = nil
+			}
+		}
+	}
+	return value
+}
+
 func convertInt(value interface{}) (int, error) {
 	switch v := value.(type) {
 	case int:
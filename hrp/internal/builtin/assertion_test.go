@@ -210,3 +210,43 @@ func TestRegexMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestAssertJSONSchema(t *testing.T) {
+	schema := `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}`
+	if !assert.True(t, AssertJSONSchema(t, map[string]interface{}{"id": 1}, schema)) {
+		t.Fatal()
+	}
+
+	violations, err := ValidateJSONSchema(map[string]interface{}{"id": "1"}, schema)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	if !assert.Len(t, violations, 1) || !assert.Equal(t, "#/id", violations[0].Path) {
+		t.Fatal()
+	}
+}
+
+func TestMatchSnapshot(t *testing.T) {
+	snapshot := &Snapshot{
+		Name: "user",
+		Value: map[string]interface{}{
+			"id":    1,
+			"name":  "leo",
+			"items": []interface{}{map[string]interface{}{"id": "a", "count": 1}},
+		},
+		Ignore: []string{"id", "items.*.id"},
+	}
+	actual := map[string]interface{}{
+		"id":    2,
+		"name":  "leo",
+		"items": []interface{}{map[string]interface{}{"id": "b", "count": 1.0}},
+	}
+	if !assert.True(t, MatchSnapshot(t, actual, snapshot)) {
+		t.Fatal()
+	}
+
+	actual["name"] = "debugtalk"
+	if !assert.False(t, MatchSnapshot(&testing.T{}, actual, snapshot)) {
+		t.Fatal()
+	}
+}
@@ -50,6 +50,9 @@ var (
 )
 
 // summary: [40, 50)
+var (
+	ThresholdsBreached = errors.New("load testing thresholds breached") // 40
+)
 
 // ios device related: [50, 60)
 var (
@@ -124,6 +127,9 @@ var errorsMap = map[error]int{
 	InterruptError:      38,
 	TimeoutError:        39,
 
+	// summary
+	ThresholdsBreached: 40,
+
 	// ios related
 	IOSDeviceConnectionError: 50,
 	IOSDeviceHTTPDriverError: 51,
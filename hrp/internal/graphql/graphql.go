@@ -0,0 +1,291 @@
+// Package graphql loads GraphQL schema from introspection result and validates
+// queries and variables against it with github.com/vektah/gqlparser/v2.
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// IntrospectionQuery queries root types, all types and directives of schema.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  fields(includeDeprecated: true) {
+    name
+    args { ...InputValue }
+    type { ...TypeRef }
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+type introspectionSchema struct {
+	QueryType        *typeRef     `json:"queryType"`
+	MutationType     *typeRef     `json:"mutationType"`
+	SubscriptionType *typeRef     `json:"subscriptionType"`
+	Types            []*fullType  `json:"types"`
+	Directives       []*directive `json:"directives"`
+}
+
+type fullType struct {
+	Kind          string        `json:"kind"`
+	Name          string        `json:"name"`
+	Fields        []*field      `json:"fields"`
+	InputFields   []*inputValue `json:"inputFields"`
+	Interfaces    []*typeRef    `json:"interfaces"`
+	EnumValues    []*typeRef    `json:"enumValues"`
+	PossibleTypes []*typeRef    `json:"possibleTypes"`
+}
+
+type field struct {
+	Name string        `json:"name"`
+	Args []*inputValue `json:"args"`
+	Type *typeRef      `json:"type"`
+}
+
+type inputValue struct {
+	Name         string   `json:"name"`
+	Type         *typeRef `json:"type"`
+	DefaultValue *string  `json:"defaultValue"`
+}
+
+type directive struct {
+	Name      string        `json:"name"`
+	Locations []string      `json:"locations"`
+	Args      []*inputValue `json:"args"`
+}
+
+// typeRef is type wrapped by NON_NULL and LIST, or named type, enum value is decoded as named type.
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+func (r *typeRef) String() string {
+	switch r.Kind {
+	case "NON_NULL":
+		return r.OfType.String() + "!"
+	case "LIST":
+		return "[" + r.OfType.String() + "]"
+	default:
+		return r.Name
+	}
+}
+
+// builtins are defined in prelude of gqlparser, thus skipped in converted schema.
+var builtins = map[string]bool{
+	"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true,
+	"include": true, "skip": true, "deprecated": true, "specifiedBy": true, "defer": true,
+}
+
+// LoadSchema loads schema from introspection response, e.g. {"data": {"__schema": {...}}}.
+func LoadSchema(introspection []byte) (*ast.Schema, error) {
+	var result struct {
+		Data struct {
+			Schema *introspectionSchema `json:"__schema"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal(introspection, &result); err != nil {
+		return nil, fmt.Errorf("invalid introspection response: %v", err)
+	}
+	if result.Data.Schema == nil {
+		return nil, fmt.Errorf("introspection response has no schema, errors: %v", result.Errors)
+	}
+	if result.Data.Schema.QueryType == nil {
+		return nil, fmt.Errorf("introspection response has no query type")
+	}
+	schema, err := gqlparser.LoadSchema(&ast.Source{
+		Name:  "introspection",
+		Input: result.Data.Schema.sdl(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// sdl converts introspection result to schema definition language.
+func (s *introspectionSchema) sdl() string {
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.QueryType.Name + "\n")
+	if s.MutationType != nil {
+		b.WriteString("  mutation: " + s.MutationType.Name + "\n")
+	}
+	if s.SubscriptionType != nil {
+		b.WriteString("  subscription: " + s.SubscriptionType.Name + "\n")
+	}
+	b.WriteString("}\n")
+
+	for _, d := range s.Directives {
+		if builtins[d.Name] {
+			continue
+		}
+		b.WriteString("directive @" + d.Name + inputValues(d.Args) + " on " + strings.Join(d.Locations, " | ") + "\n")
+	}
+
+	for _, t := range s.Types {
+		if builtins[t.Name] || strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		switch t.Kind {
+		case "SCALAR":
+			b.WriteString("scalar " + t.Name + "\n")
+		case "OBJECT", "INTERFACE":
+			keyword := "type "
+			if t.Kind == "INTERFACE" {
+				keyword = "interface "
+			}
+			b.WriteString(keyword + t.Name + implements(t.Interfaces) + " {\n")
+			for _, f := range t.Fields {
+				b.WriteString("  " + f.Name + inputValues(f.Args) + ": " + f.Type.String() + "\n")
+			}
+			b.WriteString("}\n")
+		case "UNION":
+			var names []string
+			for _, possibleType := range t.PossibleTypes {
+				names = append(names, possibleType.Name)
+			}
+			b.WriteString("union " + t.Name + " = " + strings.Join(names, " | ") + "\n")
+		case "ENUM":
+			b.WriteString("enum " + t.Name + " {\n")
+			for _, value := range t.EnumValues {
+				b.WriteString("  " + value.Name + "\n")
+			}
+			b.WriteString("}\n")
+		case "INPUT_OBJECT":
+			b.WriteString("input " + t.Name + " {\n")
+			for _, f := range t.InputFields {
+				b.WriteString("  " + inputValue2SDL(f) + "\n")
+			}
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
+func implements(interfaces []*typeRef) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+	var names []string
+	for _, i := range interfaces {
+		names = append(names, i.Name)
+	}
+	return " implements " + strings.Join(names, " & ")
+}
+
+func inputValues(args []*inputValue) string {
+	if len(args) == 0 {
+		return ""
+	}
+	var values []string
+	for _, arg := range args {
+		values = append(values, inputValue2SDL(arg))
+	}
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+// inputValue2SDL converts argument or input field, default value is GraphQL literal in introspection.
+func inputValue2SDL(v *inputValue) string {
+	if v.DefaultValue != nil {
+		return v.Name + ": " + v.Type.String() + " = " + *v.DefaultValue
+	}
+	return v.Name + ": " + v.Type.String()
+}
+
+// Validate validates query document against schema, including fields, arguments, fragments,
+// directives and literal values, variables are validated against the operation to be executed.
+func Validate(schema *ast.Schema, query, operationName string, variables map[string]interface{}) gqlerror.List {
+	doc, err := parser.ParseQuery(&ast.Source{Name: "query", Input: query})
+	if err != nil {
+		if gqlErr, ok := err.(*gqlerror.Error); ok {
+			return gqlerror.List{gqlErr}
+		}
+		return gqlerror.List{gqlerror.Wrap(err)}
+	}
+	if errs := validator.Validate(schema, doc); len(errs) > 0 {
+		return errs
+	}
+
+	op := doc.Operations.ForName(operationName)
+	if op == nil {
+		if operationName == "" {
+			return gqlerror.List{gqlerror.Errorf("operation name is required for document with multiple operations")}
+		}
+		return gqlerror.List{gqlerror.Errorf("operation %s not found", operationName)}
+	}
+	// variable values are coerced in place, validate with a copy
+	data, err := json.Marshal(variables)
+	if err != nil {
+		return gqlerror.List{gqlerror.Wrap(err)}
+	}
+	var vars map[string]interface{}
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return gqlerror.List{gqlerror.Wrap(err)}
+	}
+	if _, err := validator.VariableValues(schema, op, convertIntegers(vars).(map[string]interface{})); err != nil {
+		if gqlErr, ok := err.(*gqlerror.Error); ok {
+			return gqlerror.List{gqlErr}
+		}
+		return gqlerror.List{gqlerror.Wrap(err)}
+	}
+	return nil
+}
+
+// convertIntegers converts integral JSON numbers to int64, which could be used as Int or ID.
+func convertIntegers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = convertIntegers(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = convertIntegers(val)
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	}
+	return value
+}
@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const introspection = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": null,
+  "subscriptionType": null,
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}, "defaultValue": null}
+      ], "type": {"kind": "OBJECT", "name": "User", "ofType": null}},
+      {"name": "search", "args": [
+        {"name": "filter", "type": {"kind": "INPUT_OBJECT", "name": "Filter", "ofType": null}, "defaultValue": "{limit: 10}"}
+      ], "type": {"kind": "NON_NULL", "name": null,
+        "ofType": {"kind": "LIST", "name": null, "ofType": {"kind": "UNION", "name": "SearchResult", "ofType": null}}}}
+    ], "inputFields": null, "interfaces": [], "enumValues": null, "possibleTypes": null},
+    {"kind": "INTERFACE", "name": "Node", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}}
+    ], "inputFields": null, "interfaces": [], "enumValues": null,
+      "possibleTypes": [{"kind": "OBJECT", "name": "User", "ofType": null}]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}},
+      {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String", "ofType": null}},
+      {"name": "role", "args": [], "type": {"kind": "ENUM", "name": "Role", "ofType": null}},
+      {"name": "friends", "args": [
+        {"name": "first", "type": {"kind": "SCALAR", "name": "Int", "ofType": null}, "defaultValue": "10"}
+      ], "type": {"kind": "LIST", "name": null, "ofType": {"kind": "OBJECT", "name": "User", "ofType": null}}}
+    ], "inputFields": null, "interfaces": [{"kind": "INTERFACE", "name": "Node", "ofType": null}], "enumValues": null, "possibleTypes": null},
+    {"kind": "OBJECT", "name": "Post", "fields": [
+      {"name": "title", "args": [], "type": {"kind": "SCALAR", "name": "String", "ofType": null}}
+    ], "inputFields": null, "interfaces": [], "enumValues": null, "possibleTypes": null},
+    {"kind": "UNION", "name": "SearchResult", "fields": null, "inputFields": null, "interfaces": null, "enumValues": null,
+      "possibleTypes": [{"kind": "OBJECT", "name": "User", "ofType": null}, {"kind": "OBJECT", "name": "Post", "ofType": null}]},
+    {"kind": "ENUM", "name": "Role", "fields": null, "inputFields": null, "interfaces": null,
+      "enumValues": [{"name": "ADMIN"}, {"name": "GUEST"}], "possibleTypes": null},
+    {"kind": "INPUT_OBJECT", "name": "Filter", "fields": null, "inputFields": [
+      {"name": "limit", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "Int", "ofType": null}}, "defaultValue": null},
+      {"name": "roles", "type": {"kind": "LIST", "name": null, "ofType": {"kind": "ENUM", "name": "Role", "ofType": null}}, "defaultValue": null}
+    ], "interfaces": null, "enumValues": null, "possibleTypes": null},
+    {"kind": "SCALAR", "name": "ID", "fields": null, "inputFields": null, "interfaces": null, "enumValues": null, "possibleTypes": null},
+    {"kind": "SCALAR", "name": "String", "fields": null, "inputFields": null, "interfaces": null, "enumValues": null, "possibleTypes": null},
+    {"kind": "SCALAR", "name": "Int", "fields": null, "inputFields": null, "interfaces": null, "enumValues": null, "possibleTypes": null},
+    {"kind": "OBJECT", "name": "__Schema", "fields": [], "inputFields": null, "interfaces": [], "enumValues": null, "possibleTypes": null}
+  ],
+  "directives": [
+    {"name": "include", "locations": ["FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"], "args": [
+      {"name": "if", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "Boolean", "ofType": null}}, "defaultValue": null}
+    ]},
+    {"name": "cached", "locations": ["QUERY"], "args": [
+      {"name": "ttl", "type": {"kind": "SCALAR", "name": "Int", "ofType": null}, "defaultValue": "60"}
+    ]}
+  ]
+}}}`
+
+func TestLoadSchema(t *testing.T) {
+	schema, err := LoadSchema([]byte(introspection))
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "Query", schema.Query.Name) || !assert.Nil(t, schema.Mutation) ||
+		!assert.Len(t, schema.PossibleTypes["SearchResult"], 2) ||
+		!assert.Equal(t, "10", schema.Types["User"].Fields.ForName("friends").Arguments[0].DefaultValue.String()) ||
+		!assert.NotNil(t, schema.Directives["cached"]) {
+		t.Fatal()
+	}
+
+	if _, err := LoadSchema([]byte(`{"errors": [{"message": "introspection disabled"}]}`)); !assert.Error(t, err) {
+		t.Fatal()
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := LoadSchema([]byte(introspection))
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	valid := `query GetUser($id: ID!, $first: Int = 2) @cached(ttl: 10) {
+  user(id: $id) { id name role friends(first: $first) @include(if: true) { ...Fields } }
+  search(filter: {limit: 1, roles: [ADMIN]}) { __typename ... on User { name } ... on Post { title } }
+  __type(name: "User") { name }
+}
+fragment Fields on User { id ... on Node { id } }
+query Other { search { __typename } }`
+	if errs := Validate(schema, valid, "GetUser", map[string]interface{}{"id": 1}); !assert.Empty(t, errs) {
+		t.Fatal()
+	}
+
+	testData := []struct {
+		query         string
+		operationName string
+		variables     map[string]interface{}
+		expected      string
+	}{
+		{`{ user(id: "1") { nmae } }`, "", nil, `query:1: Cannot query field "nmae" on type "User".`},
+		{`{ user { id } }`, "", nil, `Field "user" argument "id" of type "ID!" is required`},
+		{`{ user(id: "1") { friends(first: "2") { id } } }`, "", nil, `Int cannot represent non-integer value: "2"`},
+		{`{ search(filter: {roles: [OWNER]}) { __typename } }`, "", nil, `Field "Filter.limit" of required type "Int!" was not provided.`},
+		{`{ user(id: "1") { id @skip } }`, "", nil, `Directive "@skip" argument "if" of type "Boolean!" is required`},
+		{`{ user(id: "1") { id @unknown } }`, "", nil, `Unknown directive "@unknown".`},
+		{`query ($id: ID!) { user(id: $id) { id } }`, "", nil, `variable.id must be defined`},
+		{`query ($id: ID!) { user(id: $id) { id } }`, "", map[string]interface{}{"id": true}, `cannot use bool as ID`},
+		{`query ($f: Filter) { search(filter: $f) { __typename } }`, "", map[string]interface{}{"f": map[string]interface{}{"limit": "a"}}, `variable.f.limit cannot use string as Int`},
+		{`query ($id: String) { user(id: $id) { id } }`, "", nil, `Variable "$id" of type "String" used in position expecting type "ID!".`},
+		{`query A { search { __typename } } query B { search { __typename } }`, "", nil, "operation name is required"},
+		{`query A { search { __typename } }`, "B", nil, "operation B not found"},
+		{`{ user(id: "1") { id }`, "", nil, "query:1: Expected Name, found <EOF>"},
+	}
+	for _, data := range testData {
+		var messages []string
+		for _, err := range Validate(schema, data.query, data.operationName, data.variables) {
+			messages = append(messages, err.Error())
+		}
+		if !assert.Contains(t, strings.Join(messages, "\n"), data.expected) {
+			t.Fatal(data.query)
+		}
+	}
+
+	errs := Validate(schema, "mutation { rename { ok } }", "", nil)
+	if !assert.Len(t, errs, 1) || !assert.Contains(t, errs[0].Error(), "Schema does not support operation type \"mutation\"") {
+		t.Fatal()
+	}
+}
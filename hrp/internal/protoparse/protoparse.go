@@ -0,0 +1,56 @@
+// Package protoparse parses .proto source files into file descriptors without protoc,
+// source files are compiled by github.com/bufbuild/protocompile.
+package protoparse
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Parser parses proto files, imports are searched in ImportPaths like protoc -I.
+type Parser struct {
+	ImportPaths []string // current working directory is used if empty
+}
+
+// ParseFiles parses proto files and their imports, file names are relative to import paths.
+// Well-known types could be imported without source files.
+// Returned file descriptors are ordered with dependencies first.
+func (p *Parser) ParseFiles(filenames ...string) ([]*descriptorpb.FileDescriptorProto, error) {
+	names := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		names = append(names, filepath.ToSlash(filename))
+	}
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: p.ImportPaths,
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, err
+	}
+
+	var fds []*descriptorpb.FileDescriptorProto
+	added := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if added[fd.Path()] {
+			return
+		}
+		added[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		fds = append(fds, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, f := range files {
+		add(f)
+	}
+	return fds, nil
+}
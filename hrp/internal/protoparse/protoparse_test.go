@@ -0,0 +1,212 @@
+package protoparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const commonProto = `
+syntax = "proto3";
+package demo.common;
+
+// Status of item
+enum Status {
+  STATUS_UNKNOWN = 0;
+  STATUS_OK = 1 [deprecated = true];
+  reserved 2, 3;
+}
+
+message Page {
+  int32 size = 1;
+  string token = 2;
+}
+`
+
+const serviceProto = `
+syntax = "proto3";
+
+package demo.v1;
+
+import "common/common.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/demo/v1;demov1";
+
+message Item {
+  message Attr {
+    string key = 1;
+    string value = 2;
+  }
+  string id = 1 [json_name = "itemId"];
+  demo.common.Status status = 2;
+  repeated Attr attrs = 3;
+  map<string, Attr> attr_map = 4;
+  map<string, int64> counts = 5;
+  optional string note = 6;
+  oneof source {
+    string url = 7;
+    bytes raw = 8;
+  }
+  google.protobuf.Timestamp created_at = 9;
+  /* block comment */
+  .demo.common.Page page = 10;
+}
+
+service ItemService {
+  option deprecated = false;
+  rpc GetItem(Item) returns (Item);
+  rpc ListItems(demo.common.Page) returns (stream Item) {
+    option idempotency_level = NO_SIDE_EFFECTS;
+  }
+  rpc Upload(stream Item) returns (common.Page) {}
+}
+`
+
+const legacyProto = `
+syntax = "proto2";
+package demo.legacy;
+
+import "google/protobuf/descriptor.proto";
+
+extend google.protobuf.FieldOptions {
+  optional string label = 50000;
+}
+
+message Legacy {
+  optional group Result = 1 {
+    required string url = 2;
+  }
+  optional string name = 3 [(label) = "Name", default = "none"];
+  extensions 100 to 199;
+}
+
+extend Legacy {
+  optional int32 rank = 100;
+}
+`
+
+func writeProtoFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := writeProtoFiles(t, map[string]string{
+		"common/common.proto": commonProto,
+		"service.proto":       serviceProto,
+	})
+	parser := &Parser{ImportPaths: []string{dir}}
+	fds, err := parser.ParseFiles("service.proto")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	// dependencies first
+	var names []string
+	for _, fd := range fds {
+		names = append(names, fd.GetName())
+	}
+	if !assert.Equal(t, []string{"common/common.proto", "google/protobuf/timestamp.proto", "service.proto"}, names) {
+		t.Fatal()
+	}
+
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: fds})
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	desc, err := files.FindDescriptorByName("demo.v1.Item")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	item := desc.(protoreflect.MessageDescriptor)
+	fields := item.Fields()
+	if !assert.Equal(t, "itemId", fields.ByName("id").JSONName()) ||
+		!assert.Equal(t, protoreflect.FullName("demo.common.Status"), fields.ByName("status").Enum().FullName()) ||
+		!assert.True(t, fields.ByName("attrs").IsList()) ||
+		!assert.True(t, fields.ByName("attr_map").IsMap()) ||
+		!assert.Equal(t, protoreflect.FullName("demo.v1.Item.Attr"), fields.ByName("attr_map").MapValue().Message().FullName()) ||
+		!assert.True(t, fields.ByName("note").HasPresence()) ||
+		!assert.Equal(t, protoreflect.Name("source"), fields.ByName("raw").ContainingOneof().Name()) ||
+		!assert.Equal(t, protoreflect.FullName("google.protobuf.Timestamp"), fields.ByName("created_at").Message().FullName()) ||
+		!assert.Equal(t, protoreflect.FullName("demo.common.Page"), fields.ByName("page").Message().FullName()) {
+		t.Fatal()
+	}
+
+	desc, err = files.FindDescriptorByName("demo.v1.ItemService")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	methods := desc.(protoreflect.ServiceDescriptor).Methods()
+	listItems := methods.ByName("ListItems")
+	if !assert.True(t, listItems.IsStreamingServer()) || !assert.False(t, listItems.IsStreamingClient()) ||
+		!assert.Equal(t, protoreflect.FullName("demo.common.Page"), listItems.Input().FullName()) {
+		t.Fatal()
+	}
+	upload := methods.ByName("Upload")
+	if !assert.True(t, upload.IsStreamingClient()) ||
+		!assert.Equal(t, protoreflect.FullName("demo.common.Page"), upload.Output().FullName()) {
+		t.Fatal()
+	}
+}
+
+func TestParseFilesProto2(t *testing.T) {
+	dir := writeProtoFiles(t, map[string]string{"legacy.proto": legacyProto})
+	parser := &Parser{ImportPaths: []string{dir}}
+	fds, err := parser.ParseFiles("legacy.proto")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: fds})
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	desc, err := files.FindDescriptorByName("demo.legacy.Legacy")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	fields := desc.(protoreflect.MessageDescriptor).Fields()
+	if !assert.Equal(t, protoreflect.GroupKind, fields.ByName("result").Kind()) ||
+		!assert.Equal(t, "none", fields.ByName("name").Default().String()) ||
+		!assert.Contains(t, fields.ByName("name").Options().(*descriptorpb.FieldOptions).String(), "Name") {
+		t.Fatal()
+	}
+	if _, err = files.FindDescriptorByName("demo.legacy.rank"); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+}
+
+func TestParseFilesError(t *testing.T) {
+	dir := writeProtoFiles(t, map[string]string{
+		"unknown.proto": "syntax = \"proto3\";\nmessage Foo {\n  Bar bar = 1;\n}\n",
+		"syntax.proto":  "syntax = \"proto3\";\nmessage Foo {\n  string name 1;\n}\n",
+		"a.proto":       "import \"b.proto\";",
+		"b.proto":       "import \"a.proto\";",
+	})
+	parser := &Parser{ImportPaths: []string{dir}}
+	testData := map[string]string{
+		"unknown.proto": "unknown.proto:3:3: field Foo.bar: unknown type Bar",
+		"syntax.proto":  "syntax.proto:3:15: syntax error",
+		"a.proto":       "cycle found in imports",
+		"none.proto":    "no such file or directory",
+	}
+	for filename, expected := range testData {
+		_, err := parser.ParseFiles(filename)
+		if !assert.Error(t, err) || !assert.Contains(t, err.Error(), expected) {
+			t.Fatal()
+		}
+	}
+}
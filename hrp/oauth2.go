@@ -0,0 +1,264 @@
+package hrp
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+)
+
+type oauth2GrantType string
+
+const (
+	oauth2ClientCredentials oauth2GrantType = "client_credentials"
+	oauth2Password          oauth2GrantType = "password"
+	oauth2RefreshToken      oauth2GrantType = "refresh_token"
+)
+
+// oauth2ExpiryDelta refreshes access token a little earlier before it expires
+const oauth2ExpiryDelta = 10 * time.Second
+
+// OAuth2Config represents OAuth2 settings of testcase, access token is fetched from token endpoint
+// once and shared by sessions of testcase, it is injected into Authorization header of request steps without other auth.
+type OAuth2Config struct {
+	GrantType    oauth2GrantType `json:"grant_type" yaml:"grant_type"` // client_credentials, password, refresh_token
+	TokenURL     string          `json:"token_url" yaml:"token_url"`
+	ClientID     string          `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret string          `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	Scopes       []string        `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	Username     string          `json:"username,omitempty" yaml:"username,omitempty"`           // password grant
+	Password     string          `json:"password,omitempty" yaml:"password,omitempty"`           // password grant
+	RefreshToken string          `json:"refresh_token,omitempty" yaml:"refresh_token,omitempty"` // refresh_token grant
+	AuthStyle    string          `json:"auth_style,omitempty" yaml:"auth_style,omitempty"`       // header(default): client credentials in basic auth header, body: in form body
+}
+
+// parse parses variables and functions in OAuth2 settings, e.g. client_secret: ${ENV(CLIENT_SECRET)}
+func (c *OAuth2Config) parse(parser *Parser, variables map[string]interface{}) (*OAuth2Config, error) {
+	parsed := *c
+	for _, field := range []*string{
+		&parsed.TokenURL, &parsed.ClientID, &parsed.ClientSecret,
+		&parsed.Username, &parsed.Password, &parsed.RefreshToken,
+	} {
+		if *field == "" {
+			continue
+		}
+		value, err := parser.ParseString(*field, variables)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse oauth2 config failed")
+		}
+		*field = convertString(value)
+	}
+	if parsed.TokenURL == "" {
+		return nil, errors.New("missing oauth2 token_url")
+	}
+	switch parsed.GrantType {
+	case oauth2ClientCredentials, oauth2Password, oauth2RefreshToken:
+	default:
+		return nil, errors.Errorf("unsupported oauth2 grant_type: %s", parsed.GrantType)
+	}
+	return &parsed, nil
+}
+
+// oauth2Token represents access token issued by token endpoint, RFC 6749 section 5.1.
+type oauth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	expiry       time.Time
+}
+
+func (t *oauth2Token) valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.expiry.IsZero() || time.Now().Add(oauth2ExpiryDelta).Before(t.expiry)
+}
+
+func (t *oauth2Token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// oauth2TokenCache caches access tokens of case runner keyed by parsed OAuth2 settings, thus sessions
+// and boomer iterations share tokens while different credentials, e.g. parameterized users, have their own.
+// cached tokens are not modified, an expired or rejected token is replaced.
+type oauth2TokenCache struct {
+	sync.Mutex
+	tokens map[string]*oauth2Token
+}
+
+func newOAuth2TokenCache() *oauth2TokenCache {
+	return &oauth2TokenCache{tokens: make(map[string]*oauth2Token)}
+}
+
+// setOAuth2Token sets Authorization header of request with cached access token,
+// the token is returned to be invalidated if server rejects it.
+func (r *SessionRunner) setOAuth2Token(rb *requestBuilder, client *http.Client,
+	stepVariables map[string]interface{}, sessionData *SessionData,
+) (*oauth2Token, error) {
+	config, err := r.caseRunner.parsedConfig.OAuth2.parse(r.caseRunner.parser, stepVariables)
+	if err != nil {
+		return nil, err
+	}
+	token, err := r.caseRunner.oauth2Tokens.get(client, config, sessionData)
+	if err != nil {
+		return nil, err
+	}
+	rb.req.Header.Set("Authorization", token.authorization())
+	rb.updateHeaders()
+	return token, nil
+}
+
+// get returns cached access token, a new token is fetched if it is missing or expired.
+// token endpoint exchanges are recorded in sessionData of the session fetching token.
+func (c *oauth2TokenCache) get(client *http.Client, config *OAuth2Config,
+	sessionData *SessionData,
+) (*oauth2Token, error) {
+	key, err := json.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal oauth2 config failed")
+	}
+	// token is fetched with lock held, concurrent sessions wait for it instead of requesting token endpoint
+	c.Lock()
+	defer c.Unlock()
+	cached := c.tokens[string(key)]
+	if cached.valid() {
+		return cached, nil
+	}
+	var token *oauth2Token
+	// try refresh token first, then fall back to configured grant
+	if cached != nil && cached.RefreshToken != "" {
+		refreshConfig := *config
+		refreshConfig.GrantType = oauth2RefreshToken
+		refreshConfig.RefreshToken = cached.RefreshToken
+		token, err = fetchOAuth2Token(client, &refreshConfig, sessionData)
+		if err != nil {
+			log.Warn().Err(err).Msg("refresh oauth2 token failed")
+		}
+	}
+	if token == nil {
+		token, err = fetchOAuth2Token(client, config, sessionData)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// keep refresh token if token endpoint does not issue a new one
+	if token.RefreshToken == "" && cached != nil {
+		token.RefreshToken = cached.RefreshToken
+	}
+	c.tokens[string(key)] = token
+	return token, nil
+}
+
+// invalidate marks access token as expired when server responds 401,
+// token which has been replaced by other sessions is kept.
+func (c *oauth2TokenCache) invalidate(token *oauth2Token) {
+	c.Lock()
+	defer c.Unlock()
+	for key, cached := range c.tokens {
+		if cached == token {
+			c.tokens[key] = &oauth2Token{RefreshToken: token.RefreshToken}
+		}
+	}
+}
+
+// fetchOAuth2Token requests access token from token endpoint, RFC 6749 section 4.
+func fetchOAuth2Token(client *http.Client, config *OAuth2Config, sessionData *SessionData) (*oauth2Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", string(config.GrantType))
+	if len(config.Scopes) > 0 {
+		form.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	switch config.GrantType {
+	case oauth2Password:
+		form.Set("username", config.Username)
+		form.Set("password", config.Password)
+	case oauth2RefreshToken:
+		form.Set("refresh_token", config.RefreshToken)
+	}
+	useBasicAuth := config.AuthStyle != "body" && config.ClientSecret != ""
+	if !useBasicAuth && config.ClientID != "" {
+		form.Set("client_id", config.ClientID)
+		if config.ClientSecret != "" {
+			form.Set("client_secret", config.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "create oauth2 token request failed")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasicAuth {
+		req.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	}
+
+	// record token endpoint exchange with secrets masked
+	reqResps := &ReqResps{Request: map[string]interface{}{
+		"method": req.Method,
+		"url":    req.URL.String(),
+		"body":   maskOAuth2Form(form),
+	}}
+	sessionData.OAuth2 = append(sessionData.OAuth2, reqResps)
+
+	log.Info().Str("grantType", string(config.GrantType)).Str("tokenURL", config.TokenURL).Msg("fetch oauth2 token")
+	resp, err := client.Do(req)
+	if err != nil {
+		reqResps.Response = map[string]interface{}{"error": err.Error()}
+		return nil, errors.Wrap(err, "request oauth2 token failed")
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read oauth2 token response failed")
+	}
+	var body interface{}
+	if err := json.Unmarshal(respBody, &body); err != nil {
+		body = string(respBody)
+	}
+	reqResps.Response = builtin.FormatResponse(map[string]interface{}{
+		"status_code": resp.StatusCode,
+		"body":        body,
+	})
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("request oauth2 token failed, status code: %d, body: %s",
+			resp.StatusCode, string(respBody))
+	}
+	token := &oauth2Token{}
+	if err := json.Unmarshal(respBody, token); err != nil {
+		return nil, errors.Wrap(err, "unmarshal oauth2 token response failed")
+	}
+	if token.AccessToken == "" {
+		return nil, errors.Errorf("access_token not found in oauth2 token response: %s", string(respBody))
+	}
+	if token.ExpiresIn > 0 {
+		token.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func maskOAuth2Form(form url.Values) map[string]string {
+	masked := make(map[string]string)
+	for key := range form {
+		value := form.Get(key)
+		if key == "password" || key == "client_secret" || key == "refresh_token" {
+			value = "******"
+		}
+		masked[key] = value
+	}
+	return masked
+}
@@ -0,0 +1,231 @@
+package hrp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// oauth2Server issues access tokens and checks them on /api
+type oauth2Server struct {
+	*httptest.Server
+	sync.Mutex
+	expiresIn   int
+	grantTypes  []string
+	validTokens map[string]bool
+}
+
+func newOAuth2Server(expiresIn int) *oauth2Server {
+	s := &oauth2Server{expiresIn: expiresIn, validTokens: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+		switch strings.Trim(r.URL.Path, "/") {
+		case "token":
+			r.ParseForm()
+			grantType := r.PostForm.Get("grant_type")
+			clientID, clientSecret, ok := r.BasicAuth()
+			if !ok {
+				clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+			}
+			if clientID != "hrp" || clientSecret != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid_client"}`))
+				return
+			}
+			if grantType == "password" && r.PostForm.Get("password") != "passwd" ||
+				grantType == "refresh_token" && r.PostForm.Get("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_grant"}`))
+				return
+			}
+			s.grantTypes = append(s.grantTypes, grantType)
+			token := fmt.Sprintf("token-%d", len(s.grantTypes))
+			s.validTokens[token] = true
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token": "%s", "token_type": "bearer", "expires_in": %d, "refresh_token": "refresh"}`,
+				token, s.expiresIn)
+		case "api":
+			token := r.Header.Get("Authorization")
+			if len(token) < 7 || !s.validTokens[token[7:]] {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(token[7:]))
+		case "revoke":
+			s.validTokens = make(map[string]bool)
+		}
+	}))
+	return s
+}
+
+func newOAuth2TestCase(ts *oauth2Server, oauth2 *OAuth2Config, steps ...IStep) *TestCase {
+	oauth2.TokenURL = "$base_url/token"
+	return &TestCase{
+		Config: NewConfig("oauth2").
+			SetBaseURL(ts.URL).
+			SetOAuth2(oauth2),
+		TestSteps: steps,
+	}
+}
+
+func stepOAuth2API(name string, token string) IStep {
+	return NewStep(name).
+		GET("/api").
+		Validate().
+		AssertEqual("status_code", 200, "check status code").
+		AssertEqual("body", token, "check access token")
+}
+
+func runOAuth2TestCase(t *testing.T, testcase *TestCase) *TestCaseSummary {
+	caseRunner, err := NewRunner(t).NewCaseRunner(testcase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionRunner := caseRunner.NewSession()
+	if err := sessionRunner.Start(nil); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	return summary
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	ts := newOAuth2Server(3600)
+	defer ts.Close()
+
+	testcase := newOAuth2TestCase(ts,
+		&OAuth2Config{GrantType: "client_credentials", ClientID: "hrp", ClientSecret: "secret", Scopes: []string{"read"}},
+		stepOAuth2API("fetch token", "token-1"),
+		stepOAuth2API("reuse token", "token-1"),
+		NewStep("step auth").
+			GET("/api").
+			SetAuth(NewBearerAuth("invalid")).
+			Validate().
+			AssertEqual("status_code", 401, "check oauth2 token not injected"),
+	)
+	summary := runOAuth2TestCase(t, testcase)
+	if !assert.Equal(t, []string{"client_credentials"}, ts.grantTypes) {
+		t.Fatal()
+	}
+
+	// token endpoint exchange is recorded in the first step
+	exchanges := summary.Records[0].Data.(*SessionData).OAuth2
+	if !assert.Len(t, exchanges, 1) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]string{"grant_type": "client_credentials", "scope": "read"},
+		exchanges[0].Request.(map[string]interface{})["body"]) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, 200, exchanges[0].Response.(map[string]interface{})["status_code"]) {
+		t.Fatal()
+	}
+	if !assert.Empty(t, summary.Records[1].Data.(*SessionData).OAuth2) {
+		t.Fatal()
+	}
+}
+
+func TestOAuth2TokenSharedBySessions(t *testing.T) {
+	ts := newOAuth2Server(3600)
+	defer ts.Close()
+
+	// sessions of case runner share token, e.g. iterations of load testing
+	testcase := newOAuth2TestCase(ts,
+		&OAuth2Config{GrantType: "client_credentials", ClientID: "hrp", ClientSecret: "secret"},
+		stepOAuth2API("fetch token", "token-1"),
+	)
+	caseRunner, err := NewRunner(t).NewCaseRunner(testcase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := caseRunner.NewSession().Start(nil); !assert.Nil(t, err) {
+			t.Fatal()
+		}
+	}
+	if !assert.Equal(t, []string{"client_credentials"}, ts.grantTypes) {
+		t.Fatal()
+	}
+
+	// parameterized credentials have their own tokens
+	ts.grantTypes = nil
+	testcase = newOAuth2TestCase(ts,
+		&OAuth2Config{GrantType: "password", ClientID: "hrp", ClientSecret: "secret",
+			Username: "$username", Password: "passwd"},
+		NewStep("fetch token").GET("/api").Validate().AssertEqual("status_code", 200, "check status code"),
+	)
+	testcase.Config.WithParameters(map[string]interface{}{
+		"username": []interface{}{"user1", "user2", "user1"},
+	})
+	if err := NewRunner(t).Run(testcase); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, []string{"password", "password"}, ts.grantTypes) {
+		t.Fatal()
+	}
+}
+
+func TestOAuth2RefreshToken(t *testing.T) {
+	ts := newOAuth2Server(3600)
+	defer ts.Close()
+
+	// refresh token when server responds 401
+	testcase := newOAuth2TestCase(ts,
+		&OAuth2Config{GrantType: "password", ClientID: "hrp", ClientSecret: "secret", AuthStyle: "body",
+			Username: "user", Password: "passwd"},
+		stepOAuth2API("fetch token", "token-1"),
+		NewStep("revoke token").GET("/revoke"),
+		stepOAuth2API("refresh token on 401", "token-2"),
+	)
+	summary := runOAuth2TestCase(t, testcase)
+	if !assert.Equal(t, []string{"password", "refresh_token"}, ts.grantTypes) {
+		t.Fatal()
+	}
+	body := summary.Records[0].Data.(*SessionData).OAuth2[0].Request.(map[string]interface{})["body"]
+	if !assert.Equal(t, map[string]string{
+		"grant_type": "password", "username": "user", "password": "******",
+		"client_id": "hrp", "client_secret": "******",
+	}, body) {
+		t.Fatal()
+	}
+
+	// refresh token when it expires
+	ts.expiresIn = 5
+	ts.grantTypes = nil
+	testcase = newOAuth2TestCase(ts,
+		&OAuth2Config{GrantType: "client_credentials", ClientID: "hrp", ClientSecret: "secret"},
+		stepOAuth2API("fetch token", "token-1"),
+		stepOAuth2API("refresh expired token", "token-2"),
+	)
+	runOAuth2TestCase(t, testcase)
+	if !assert.Equal(t, []string{"client_credentials", "refresh_token"}, ts.grantTypes) {
+		t.Fatal()
+	}
+}
+
+func TestOAuth2FetchTokenFailed(t *testing.T) {
+	ts := newOAuth2Server(3600)
+	defer ts.Close()
+
+	testcase := newOAuth2TestCase(ts,
+		&OAuth2Config{GrantType: "client_credentials", ClientID: "hrp", ClientSecret: "wrong"},
+		stepOAuth2API("fetch token", "token-1"),
+	)
+	caseRunner, _ := NewRunner(t).NewCaseRunner(testcase)
+	sessionRunner := caseRunner.NewSession()
+	if err := sessionRunner.Start(nil); !assert.Error(t, err) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	exchanges := summary.Records[0].Data.(*SessionData).OAuth2
+	if !assert.Len(t, exchanges, 1) ||
+		!assert.Equal(t, 401, exchanges[0].Response.(map[string]interface{})["status_code"]) {
+		t.Fatal()
+	}
+}
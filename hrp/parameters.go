@@ -145,22 +145,29 @@ func (iter *ParametersIterator) HasNext() bool {
 }
 
 func (iter *ParametersIterator) Next() map[string]interface{} {
+	parameters, _ := iter.next()
+	return parameters
+}
+
+// next returns the next parameters and the index of selected sequential parameters starting from 1,
+// index is 0 if there are no sequential parameters.
+func (iter *ParametersIterator) next() (map[string]interface{}, int) {
 	iter.Lock()
 	defer iter.Unlock()
 
 	if !iter.hasNext {
-		return nil
+		return nil, 0
 	}
 
 	var selectedParameters map[string]interface{}
+	var selectedIndex int
 	if len(iter.sequentialParameters) == 0 {
 		selectedParameters = make(map[string]interface{})
-	} else if iter.index < len(iter.sequentialParameters) {
-		selectedParameters = iter.sequentialParameters[iter.index]
 	} else {
 		// loop back to the first sequential parameter
-		index := iter.index % len(iter.sequentialParameters)
-		selectedParameters = iter.sequentialParameters[index]
+		selectedIndex = iter.index % len(iter.sequentialParameters)
+		selectedParameters = iter.sequentialParameters[selectedIndex]
+		selectedIndex++
 	}
 
 	// merge with random parameters
@@ -177,7 +184,7 @@ func (iter *ParametersIterator) Next() map[string]interface{} {
 		iter.hasNext = false
 	}
 
-	return selectedParameters
+	return selectedParameters, selectedIndex
 }
 
 func (iter *ParametersIterator) outParameters() map[string]interface{} {
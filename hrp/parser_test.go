@@ -784,3 +784,32 @@ func TestFindallVariables(t *testing.T) {
 		}
 	}
 }
+
+func TestIsTruthy(t *testing.T) {
+	testData := []struct {
+		value  interface{}
+		expect bool
+	}{
+		{nil, false},
+		{true, true},
+		{false, false},
+		{"true", true},
+		{"False", false},
+		{"0", false},
+		{"", false},
+		{"abc", true},
+		{0, false},
+		{int64(2), true},
+		{0.0, false},
+		{1.5, true},
+		{[]interface{}{}, false},
+		{[]interface{}{1}, true},
+		{map[string]interface{}{}, false},
+	}
+
+	for _, data := range testData {
+		if !assert.Equal(t, data.expect, isTruthy(data.value), "value: %v", data.value) {
+			t.Fatal()
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package boomer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// arrivalRateTickInterval is the interval to start iterations of arrival rate executor
+const arrivalRateTickInterval = 10 * time.Millisecond
+
+// arrivalRateExecutor is an open-model executor, iterations are started at target rate regardless of response times,
+// thus slow responses increase concurrency instead of decreasing throughput and hiding latency (coordinated omission).
+// Iterations are run by virtual users (VUs) from a pool, which is pre-allocated and grows up to max VUs on demand,
+// iterations are dropped if all VUs are busy.
+type arrivalRateExecutor struct {
+	rate              int64 // target iterations per second
+	preAllocatedVUs   int64
+	maxVUs            int64
+	droppedIterations int64 // iterations dropped since load testing started
+	idleVUs           int64 // VUs waiting for iterations
+}
+
+func newArrivalRateExecutor(rate, preAllocatedVUs, maxVUs int64) *arrivalRateExecutor {
+	if preAllocatedVUs <= 0 {
+		preAllocatedVUs = 1
+	}
+	if maxVUs < preAllocatedVUs {
+		maxVUs = preAllocatedVUs
+	}
+	return &arrivalRateExecutor{
+		rate:            rate,
+		preAllocatedVUs: preAllocatedVUs,
+		maxVUs:          maxVUs,
+	}
+}
+
+func (e *arrivalRateExecutor) setRate(rate int64) {
+	atomic.StoreInt64(&e.rate, rate)
+}
+
+func (e *arrivalRateExecutor) getRate() int64 {
+	return atomic.LoadInt64(&e.rate)
+}
+
+func (e *arrivalRateExecutor) getDroppedIterations() int64 {
+	return atomic.LoadInt64(&e.droppedIterations)
+}
+
+// runArrivalRate pre-allocates VUs and starts iterations at target rate until quit is closed,
+// VUs are acquired from controller, whose spawn count is the max VUs. The rate is ramped by stages if specified.
+func (r *runner) runArrivalRate(quit chan bool) {
+	e := r.arrivalRate
+	r.updateState(StateSpawning)
+	log.Info().
+		Int64("rate", e.getRate()).
+		Int64("preAllocatedVUs", e.preAllocatedVUs).
+		Int64("maxVUs", e.maxVUs).
+		Msg("Starting arrival rate executor")
+
+	atomic.StoreInt64(&e.droppedIterations, 0)
+	atomic.StoreInt64(&e.idleVUs, 0)
+	// buffered iterations never block, since they are only sent to idle VUs
+	iterations := make(chan struct{}, e.maxVUs)
+	r.controller.setSpawn(e.maxVUs, float64(e.maxVUs))
+	for i := int64(0); i < e.preAllocatedVUs && r.controller.acquire(); i++ {
+		atomic.AddInt64(&e.idleVUs, 1)
+		r.goAttach(func() {
+			r.runVU(quit, iterations)
+		})
+	}
+	r.controller.once.Do(
+		func() {
+			r.controller.spawnCompete()
+			r.updateState(StateRunning)
+		},
+	)
+
+	ticker := time.NewTicker(arrivalRateTickInterval)
+	defer ticker.Stop()
+	// the first iteration is started immediately, fractional iterations are carried over to the next tick
+	pending := 1.0
+	startTime := time.Now()
+	last := startTime
+	for {
+		for ; pending >= 1; pending-- {
+			r.startIteration(quit, iterations)
+		}
+		select {
+		case <-quit:
+			log.Info().Int64("droppedIterations", e.getDroppedIterations()).Msg("Quitting arrival rate executor")
+			return
+		case now := <-ticker.C:
+			if r.stages != nil {
+				_, _, rps := r.stages.target(now.Sub(startTime))
+				e.setRate(rps)
+			}
+			pending += float64(e.getRate()) * now.Sub(last).Seconds()
+			last = now
+		}
+	}
+}
+
+// startIteration hands iteration to an idle VU, allocates a new VU if all VUs are busy,
+// or drops the iteration if max VUs are reached.
+func (r *runner) startIteration(quit chan bool, iterations chan struct{}) {
+	if r.loop != nil && !r.loop.acquire() {
+		return
+	}
+	e := r.arrivalRate
+	// idle VUs are only decreased by this goroutine
+	if atomic.AddInt64(&e.idleVUs, -1) >= 0 {
+		iterations <- struct{}{}
+		return
+	}
+	atomic.AddInt64(&e.idleVUs, 1)
+	if r.controller.acquire() {
+		r.goAttach(func() {
+			r.runIteration()
+			atomic.AddInt64(&e.idleVUs, 1)
+			r.runVU(quit, iterations)
+		})
+		return
+	}
+	atomic.AddInt64(&e.droppedIterations, 1)
+	r.finishIteration()
+}
+
+// runVU waits for iterations until quit is closed.
+func (r *runner) runVU(quit chan bool, iterations chan struct{}) {
+	for {
+		select {
+		case <-quit:
+			r.controller.increaseFinishedCount()
+			return
+		case <-iterations:
+			r.runIteration()
+			atomic.AddInt64(&r.arrivalRate.idleVUs, 1)
+		}
+	}
+}
+
+func (r *runner) runIteration() {
+	task := r.getTask()
+	r.safeRun(task.Fn)
+	r.finishIteration()
+}
+
+// finishIteration stops load testing if all iterations of loop count are finished, including dropped iterations.
+func (r *runner) finishIteration() {
+	if r.loop != nil {
+		r.loop.increaseFinishedCount()
+		if r.loop.isFinished() {
+			go r.stop()
+		}
+	}
+}
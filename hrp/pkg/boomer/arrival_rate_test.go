@@ -0,0 +1,136 @@
+package boomer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestArrivalRateExecutor(t *testing.T) {
+	runner := newLocalRunner(1, 1)
+	runner.arrivalRate = newArrivalRateExecutor(50, 2, 10)
+	var count int64
+	runner.setTasks([]*Task{
+		{
+			Weight: 10,
+			Fn: func() {
+				atomic.AddInt64(&count, 1)
+				time.Sleep(50 * time.Millisecond)
+			},
+			Name: "TaskA",
+		},
+	})
+	runArrivalRateFor(runner, 2*time.Second)
+
+	// iterations are started at rate regardless of response times
+	if count < 80 || count > 110 {
+		t.Error("iterations should be started at rate of 50/s in 2 seconds, got:", count)
+	}
+	if dropped := runner.arrivalRate.getDroppedIterations(); dropped != 0 {
+		t.Error("iterations should not be dropped, got:", dropped)
+	}
+}
+
+func TestArrivalRateExecutorDropIterations(t *testing.T) {
+	runner := newLocalRunner(1, 1)
+	runner.arrivalRate = newArrivalRateExecutor(20, 1, 2)
+	var count, maxBusy, busy int64
+	runner.setTasks([]*Task{
+		{
+			Weight: 10,
+			Fn: func() {
+				atomic.AddInt64(&count, 1)
+				current := atomic.AddInt64(&busy, 1)
+				if current > atomic.LoadInt64(&maxBusy) {
+					atomic.StoreInt64(&maxBusy, current)
+				}
+				time.Sleep(600 * time.Millisecond)
+				atomic.AddInt64(&busy, -1)
+			},
+			Name: "TaskA",
+		},
+	})
+	runArrivalRateFor(runner, time.Second)
+
+	if maxBusy > 2 {
+		t.Error("VUs should not exceed max VUs, got:", maxBusy)
+	}
+	if count > 4 {
+		t.Error("iterations should be limited by max VUs, got:", count)
+	}
+	if dropped := runner.arrivalRate.getDroppedIterations(); dropped < 10 {
+		t.Error("iterations should be dropped when all VUs are busy, got:", dropped)
+	}
+}
+
+func TestArrivalRateExecutorWithLoopCount(t *testing.T) {
+	runner := newLocalRunner(1, 1)
+	runner.arrivalRate = newArrivalRateExecutor(100, 5, 5)
+	runner.loop = &Loop{loopCount: 20}
+	var count int64
+	runner.setTasks([]*Task{
+		{
+			Weight: 10,
+			Fn: func() {
+				atomic.AddInt64(&count, 1)
+			},
+			Name: "TaskA",
+		},
+	})
+
+	done := make(chan bool)
+	go func() {
+		runner.start()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		runner.stop()
+		t.Fatal("runner should be stopped after all iterations are finished")
+	}
+	if count != 20 {
+		t.Error("iterations should be equal to loop count, got:", count)
+	}
+}
+
+func TestArrivalRateExecutorWithStages(t *testing.T) {
+	runner := newLocalRunner(1, 1)
+	runner.arrivalRate = newArrivalRateExecutor(0, 5, 5)
+	stages, err := newLoadStages([]*Stage{
+		{Duration: "1s", RPS: 20},
+		{Duration: "1s", RPS: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.stages = stages
+	var count int64
+	runner.setTasks([]*Task{
+		{
+			Weight: 10,
+			Fn: func() {
+				atomic.AddInt64(&count, 1)
+			},
+			Name: "TaskA",
+		},
+	})
+	runner.start()
+
+	// about 10 iterations in the first stage and 60 iterations in the second stage
+	if count < 40 || count > 100 {
+		t.Error("iterations should be started at rate of stages, got:", count)
+	}
+}
+
+// runArrivalRateFor runs load testing and stops it after duration.
+func runArrivalRateFor(runner *localRunner, duration time.Duration) {
+	done := make(chan bool)
+	go func() {
+		runner.start()
+		close(done)
+	}()
+	time.Sleep(duration)
+	runner.stop()
+	<-done
+}
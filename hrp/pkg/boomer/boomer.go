@@ -24,6 +24,8 @@ const (
 	DistributedWorkerMode
 	// StandaloneMode will run without a master.
 	StandaloneMode
+	// ArrivalRateMode will run without a master, iterations are started at target rate by open-model executor.
+	ArrivalRateMode
 )
 
 // A Boomer is used to run tasks.
@@ -53,6 +55,9 @@ type Profile struct {
 	SpawnRate                float64       `json:"spawn-rate,omitempty" yaml:"spawn-rate,omitempty" mapstructure:"spawn-rate,omitempty"`
 	RunTime                  int64         `json:"run-time,omitempty" yaml:"run-time,omitempty" mapstructure:"run-time,omitempty"`
 	MaxRPS                   int64         `json:"max-rps,omitempty" yaml:"max-rps,omitempty" mapstructure:"max-rps,omitempty"`
+	ArrivalRate              int64         `json:"arrival-rate,omitempty" yaml:"arrival-rate,omitempty" mapstructure:"arrival-rate,omitempty"`
+	PreAllocatedVUs          int64         `json:"pre-allocated-vus,omitempty" yaml:"pre-allocated-vus,omitempty" mapstructure:"pre-allocated-vus,omitempty"`
+	MaxVUs                   int64         `json:"max-vus,omitempty" yaml:"max-vus,omitempty" mapstructure:"max-vus,omitempty"`
 	LoopCount                int64         `json:"loop-count,omitempty" yaml:"loop-count,omitempty" mapstructure:"loop-count,omitempty"`
 	RequestIncreaseRate      string        `json:"request-increase-rate,omitempty" yaml:"request-increase-rate,omitempty" mapstructure:"request-increase-rate,omitempty"`
 	MemoryProfile            string        `json:"memory-profile,omitempty" yaml:"memory-profile,omitempty" mapstructure:"memory-profile,omitempty"`
@@ -63,6 +68,9 @@ type Profile struct {
 	DisableConsoleOutput     bool          `json:"disable-console-output,omitempty" yaml:"disable-console-output,omitempty" mapstructure:"disable-console-output,omitempty"`
 	DisableCompression       bool          `json:"disable-compression,omitempty" yaml:"disable-compression,omitempty" mapstructure:"disable-compression,omitempty"`
 	DisableKeepalive         bool          `json:"disable-keepalive,omitempty" yaml:"disable-keepalive,omitempty" mapstructure:"disable-keepalive,omitempty"`
+	GenReport                bool          `json:"gen-report,omitempty" yaml:"gen-report,omitempty" mapstructure:"gen-report,omitempty"`
+	Thresholds               []*Threshold  `json:"thresholds,omitempty" yaml:"thresholds,omitempty" mapstructure:"thresholds,omitempty"`
+	Stages                   []*Stage      `json:"stages,omitempty" yaml:"stages,omitempty" mapstructure:"stages,omitempty"`
 }
 
 func NewProfile() *Profile {
@@ -99,7 +107,7 @@ func (b *Boomer) SetProfile(profile *Profile) {
 	}
 }
 
-// SetMode only accepts boomer.DistributedMasterMode、boomer.DistributedWorkerMode and boomer.StandaloneMode.
+// SetMode only accepts boomer.DistributedMasterMode、boomer.DistributedWorkerMode、boomer.StandaloneMode and boomer.ArrivalRateMode.
 func (b *Boomer) SetMode(mode Mode) {
 	switch mode {
 	case DistributedMasterMode:
@@ -108,6 +116,12 @@ func (b *Boomer) SetMode(mode Mode) {
 		b.mode = DistributedWorkerMode
 	case StandaloneMode:
 		b.mode = StandaloneMode
+	case ArrivalRateMode:
+		if b.localRunner != nil && b.localRunner.arrivalRate != nil {
+			b.mode = ArrivalRateMode
+		} else {
+			log.Error().Err(errors.New("Arrival rate executor is not initialized, ignored!"))
+		}
 	default:
 		log.Error().Err(errors.New("Invalid mode, ignored!"))
 	}
@@ -122,6 +136,8 @@ func (b *Boomer) GetMode() string {
 		return "worker"
 	case StandaloneMode:
 		return "standalone"
+	case ArrivalRateMode:
+		return "arrival-rate"
 	default:
 		log.Error().Err(errors.New("Invalid mode, ignored!"))
 		return ""
@@ -136,6 +152,18 @@ func NewStandaloneBoomer(spawnCount int64, spawnRate float64) *Boomer {
 	}
 }
 
+// NewArrivalRateBoomer returns a new Boomer with open-model executor, which can run without master.
+// Iterations are started at rate per second from preAllocatedVUs, which grows up to maxVUs on demand.
+func NewArrivalRateBoomer(rate, preAllocatedVUs, maxVUs int64) *Boomer {
+	executor := newArrivalRateExecutor(rate, preAllocatedVUs, maxVUs)
+	localRunner := newLocalRunner(executor.maxVUs, float64(executor.maxVUs))
+	localRunner.arrivalRate = executor
+	return &Boomer{
+		mode:        ArrivalRateMode,
+		localRunner: localRunner,
+	}
+}
+
 // NewMasterBoomer returns a new Boomer.
 func NewMasterBoomer(masterBindHost string, masterBindPort int) *Boomer {
 	return &Boomer{
@@ -312,6 +340,10 @@ func (b *Boomer) SetRateLimiter(maxRPS int64, requestIncreaseRate string) {
 		log.Error().Err(err).Msg("failed to create rate limiter")
 		return
 	}
+	if rateLimiter != nil && b.mode == ArrivalRateMode {
+		log.Warn().Msg("rate limiter is ignored in arrival rate mode")
+		return
+	}
 
 	if rateLimiter != nil {
 		switch b.mode {
@@ -358,6 +390,9 @@ func (b *Boomer) SetLoopCount(loopCount int64) {
 		b.masterRunner.loop = &Loop{loopCount: loopCount * b.masterRunner.getSpawnCount()}
 	case StandaloneMode:
 		b.localRunner.loop = &Loop{loopCount: loopCount * b.localRunner.getSpawnCount()}
+	case ArrivalRateMode:
+		// total iterations of arrival rate executor
+		b.localRunner.loop = &Loop{loopCount: loopCount}
 	}
 }
 
@@ -368,7 +403,7 @@ func (b *Boomer) AddOutput(o Output) {
 		b.workerRunner.addOutput(o)
 	case DistributedMasterMode:
 		b.masterRunner.addOutput(o)
-	case StandaloneMode:
+	case StandaloneMode, ArrivalRateMode:
 		b.localRunner.addOutput(o)
 	}
 }
@@ -418,7 +453,7 @@ func (b *Boomer) Run(tasks ...*Task) {
 		log.Info().Msg("running in worker mode")
 		b.workerRunner.setTasks(tasks)
 		b.workerRunner.start()
-	case StandaloneMode:
+	case StandaloneMode, ArrivalRateMode:
 		log.Info().Msg("running in standalone mode")
 		b.localRunner.setTasks(tasks)
 		b.localRunner.start()
@@ -432,7 +467,7 @@ func (b *Boomer) SetTasks(tasks ...*Task) {
 	case DistributedWorkerMode:
 		log.Info().Msg("set tasks to worker")
 		b.workerRunner.setTasks(tasks)
-	case StandaloneMode:
+	case StandaloneMode, ArrivalRateMode:
 		log.Info().Msg("set tasks to standalone")
 		b.localRunner.setTasks(tasks)
 	default:
@@ -448,7 +483,7 @@ func (b *Boomer) RecordTransaction(name string, success bool, elapsedTime int64,
 		runnerStats = b.workerRunner.stats
 	case DistributedMasterMode:
 		runnerStats = b.masterRunner.stats
-	case StandaloneMode:
+	case StandaloneMode, ArrivalRateMode:
 		runnerStats = b.localRunner.stats
 	}
 	runnerStats.transactionChan <- &transaction{
@@ -467,7 +502,7 @@ func (b *Boomer) RecordSuccess(requestType, name string, responseTime int64, res
 		runnerStats = b.workerRunner.stats
 	case DistributedMasterMode:
 		runnerStats = b.masterRunner.stats
-	case StandaloneMode:
+	case StandaloneMode, ArrivalRateMode:
 		runnerStats = b.localRunner.stats
 	}
 	runnerStats.requestSuccessChan <- &requestSuccess{
@@ -486,7 +521,7 @@ func (b *Boomer) RecordFailure(requestType, name string, responseTime int64, exc
 		runnerStats = b.workerRunner.stats
 	case DistributedMasterMode:
 		runnerStats = b.masterRunner.stats
-	case StandaloneMode:
+	case StandaloneMode, ArrivalRateMode:
 		runnerStats = b.localRunner.stats
 	}
 	runnerStats.requestFailureChan <- &requestFailure{
@@ -512,6 +547,12 @@ func (b *Boomer) Start(Args *Profile) error {
 	b.SetSpawnRate(Args.SpawnRate)
 	b.SetRunTime(Args.RunTime)
 	b.SetProfile(Args)
+	if err := b.SetThresholds(Args.Thresholds); err != nil {
+		return err
+	}
+	if err := b.SetStages(Args.Stages); err != nil {
+		return err
+	}
 	err := b.masterRunner.start()
 	return err
 }
@@ -574,11 +615,103 @@ func (b *Boomer) Quit() {
 		b.workerRunner.close()
 	case DistributedMasterMode:
 		b.masterRunner.close()
-	case StandaloneMode:
+	case StandaloneMode, ArrivalRateMode:
 		b.localRunner.stop()
 	}
 }
 
+// SetThresholds sets pass/fail thresholds of load testing, which are checked against stats in each report interval.
+// Thresholds are checked by master in distributed mode, thus they are ignored by worker.
+func (b *Boomer) SetThresholds(thresholds []*Threshold) error {
+	var checker *thresholdChecker
+	if len(thresholds) > 0 {
+		var err error
+		checker, err = newThresholdChecker(thresholds)
+		if err != nil {
+			return err
+		}
+	}
+	switch b.mode {
+	case DistributedMasterMode:
+		b.masterRunner.statsMutex.Lock()
+		b.masterRunner.thresholds = checker
+		b.masterRunner.statsMutex.Unlock()
+	case StandaloneMode, ArrivalRateMode:
+		b.localRunner.thresholds = checker
+	}
+	return nil
+}
+
+// SetStages sets stages of load profile to ramp, hold or spike users and rps, load testing is stopped after the last stage.
+// Stages are driven by master in distributed mode, thus they are ignored by worker.
+func (b *Boomer) SetStages(stages []*Stage) error {
+	var s *loadStages
+	if len(stages) > 0 {
+		var err error
+		s, err = newLoadStages(stages)
+		if err != nil {
+			return err
+		}
+	}
+	switch b.mode {
+	case DistributedMasterMode:
+		b.masterRunner.stages = s
+	case ArrivalRateMode:
+		// rps of stages drives the arrival rate
+		if s != nil && !s.rps {
+			return errors.New("stages should specify target rps in arrival rate mode")
+		}
+		b.localRunner.stages = s
+	case StandaloneMode:
+		b.localRunner.stages = s
+		if s == nil || !s.rps {
+			break
+		}
+		// rps driven by stages requires stable rate limiter
+		if _, ok := b.localRunner.rateLimiter.(*StableRateLimiter); !ok || !b.localRunner.rateLimitEnabled {
+			_, _, rps := s.target(0)
+			log.Warn().Int64("maxRPS", rps).Msg("set stable rate limiter for stages")
+			b.localRunner.rateLimitEnabled = true
+			b.localRunner.rateLimiter = NewStableRateLimiter(rps, time.Second)
+		}
+	}
+	return nil
+}
+
+// GetThresholdResults returns results of thresholds checked in the last report interval.
+func (b *Boomer) GetThresholdResults() []*ThresholdResult {
+	switch b.mode {
+	case DistributedMasterMode:
+		b.masterRunner.statsMutex.Lock()
+		defer b.masterRunner.statsMutex.Unlock()
+		if b.masterRunner.thresholds != nil {
+			return b.masterRunner.thresholds.getResults()
+		}
+	case StandaloneMode, ArrivalRateMode:
+		if b.localRunner.thresholds != nil {
+			return b.localRunner.thresholds.getResults()
+		}
+	}
+	return nil
+}
+
+// CheckThresholds returns error with breached conditions if any threshold is breached.
+func (b *Boomer) CheckThresholds() error {
+	switch b.mode {
+	case DistributedMasterMode:
+		b.masterRunner.statsMutex.Lock()
+		defer b.masterRunner.statsMutex.Unlock()
+		if b.masterRunner.thresholds != nil {
+			return b.masterRunner.thresholds.err()
+		}
+	case StandaloneMode, ArrivalRateMode:
+		if b.localRunner.thresholds != nil {
+			return b.localRunner.thresholds.err()
+		}
+	}
+	return nil
+}
+
 func (b *Boomer) GetSpawnDoneChan() chan struct{} {
 	switch b.mode {
 	case DistributedWorkerMode:
@@ -592,6 +725,8 @@ func (b *Boomer) GetSpawnDoneChan() chan struct{} {
 
 func (b *Boomer) GetSpawnCount() int {
 	switch b.mode {
+	case ArrivalRateMode:
+		return int(b.localRunner.arrivalRate.maxVUs)
 	case DistributedWorkerMode:
 		return int(b.workerRunner.getSpawnCount())
 	case DistributedMasterMode:
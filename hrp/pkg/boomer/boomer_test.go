@@ -21,6 +21,32 @@ func TestNewStandaloneBoomer(t *testing.T) {
 	}
 }
 
+func TestNewArrivalRateBoomer(t *testing.T) {
+	b := NewArrivalRateBoomer(100, 10, 5)
+
+	if b.GetMode() != "arrival-rate" {
+		t.Error("mode should be arrival-rate")
+	}
+
+	if b.localRunner.arrivalRate.rate != 100 {
+		t.Error("rate should be 100")
+	}
+
+	// max VUs are at least pre-allocated VUs
+	if b.GetSpawnCount() != 10 {
+		t.Error("max VUs should be 10")
+	}
+
+	b.SetRateLimiter(10, "-1")
+	if b.localRunner.rateLimitEnabled {
+		t.Error("rate limiter should be ignored in arrival rate mode")
+	}
+
+	if err := b.SetStages([]*Stage{{Duration: "10s", Users: 10}}); err == nil {
+		t.Error("stages without rps should be invalid in arrival rate mode")
+	}
+}
+
 func TestSetRateLimiter(t *testing.T) {
 	b := NewStandaloneBoomer(100, 10)
 	b.SetRateLimiter(10, "10/1s")
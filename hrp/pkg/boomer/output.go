@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,28 +47,6 @@ func NewConsoleOutput() *ConsoleOutput {
 	return &ConsoleOutput{}
 }
 
-func getMedianResponseTime(numRequests int64, responseTimes map[int64]int64) int64 {
-	medianResponseTime := int64(0)
-	if len(responseTimes) != 0 {
-		pos := (numRequests - 1) / 2
-		var sortedKeys []int64
-		for k := range responseTimes {
-			sortedKeys = append(sortedKeys, k)
-		}
-		sort.Slice(sortedKeys, func(i, j int) bool {
-			return sortedKeys[i] < sortedKeys[j]
-		})
-		for _, k := range sortedKeys {
-			if pos < responseTimes[k] {
-				medianResponseTime = k
-				break
-			}
-			pos -= responseTimes[k]
-		}
-	}
-	return medianResponseTime
-}
-
 func getAvgResponseTime(numRequests int64, totalResponseTime int64) (avgResponseTime float64) {
 	avgResponseTime = float64(0)
 	if numRequests != 0 {
@@ -120,26 +99,40 @@ func (o *ConsoleOutput) OnEvent(data map[string]interface{}) {
 	state := getStateName(output.State)
 
 	currentTime := time.Now()
-	println(fmt.Sprintf("Current time: %s, Users: %d, State: %s, Total RPS: %.1f, Total Average Response Time: %.1fms, Total Fail Ratio: %.1f%%",
-		currentTime.Format("2006/01/02 15:04:05"), output.UserCount, state, output.TotalRPS, output.TotalAvgResponseTime, output.TotalFailRatio*100))
+	println(fmt.Sprintf("Current time: %s, Users: %d, State: %s, Total RPS: %.1f, Total Average Response Time: %.1fms, Total P99 Response Time: %dms, Total Fail Ratio: %.1f%%",
+		currentTime.Format("2006/01/02 15:04:05"), output.UserCount, state, output.TotalRPS, output.TotalAvgResponseTime,
+		output.TotalResponseTimePercentiles["p99"], output.TotalFailRatio*100))
 	println(fmt.Sprintf("Accumulated Transactions: %d Passed, %d Failed",
 		output.TransactionsPassed, output.TransactionsFailed))
+	if output.DroppedIterations > 0 {
+		println(fmt.Sprintf("Accumulated Dropped Iterations: %d", output.DroppedIterations))
+	}
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Type", "Name", "# requests", "# fails", "Median", "Average", "Min", "Max", "Content Size", "# reqs/sec", "# fails/sec"})
+	header := []string{"Type", "Name", "# requests", "# fails", "Average", "Min"}
+	for _, percentile := range responseTimePercentiles {
+		header = append(header, strings.ToUpper(percentileName(percentile)))
+	}
+	header = append(header, "Max", "Content Size", "# reqs/sec", "# fails/sec")
+	table.SetHeader(header)
 
 	for _, stat := range output.Stats {
-		row := make([]string, 11)
-		row[0] = stat.Method
-		row[1] = stat.Name
-		row[2] = strconv.FormatInt(stat.NumRequests, 10)
-		row[3] = strconv.FormatInt(stat.NumFailures, 10)
-		row[4] = strconv.FormatInt(stat.medianResponseTime, 10)
-		row[5] = strconv.FormatFloat(stat.avgResponseTime, 'f', 2, 64)
-		row[6] = strconv.FormatInt(stat.MinResponseTime, 10)
-		row[7] = strconv.FormatInt(stat.MaxResponseTime, 10)
-		row[8] = strconv.FormatInt(stat.avgContentLength, 10)
-		row[9] = strconv.FormatFloat(stat.currentRps, 'f', 2, 64)
-		row[10] = strconv.FormatFloat(stat.currentFailPerSec, 'f', 2, 64)
+		row := []string{
+			stat.Method,
+			stat.Name,
+			strconv.FormatInt(stat.NumRequests, 10),
+			strconv.FormatInt(stat.NumFailures, 10),
+			strconv.FormatFloat(stat.avgResponseTime, 'f', 2, 64),
+			strconv.FormatInt(stat.MinResponseTime, 10),
+		}
+		for _, percentile := range responseTimePercentiles {
+			row = append(row, strconv.FormatInt(stat.percentiles[percentileName(percentile)], 10))
+		}
+		row = append(row,
+			strconv.FormatInt(stat.MaxResponseTime, 10),
+			strconv.FormatInt(stat.avgContentLength, 10),
+			strconv.FormatFloat(stat.currentRps, 'f', 2, 64),
+			strconv.FormatFloat(stat.currentFailPerSec, 'f', 2, 64),
+		)
 		table.Append(row)
 	}
 	table.Render()
@@ -149,29 +142,32 @@ func (o *ConsoleOutput) OnEvent(data map[string]interface{}) {
 type statsEntryOutput struct {
 	statsEntry
 
-	medianResponseTime int64   // median response time
-	avgResponseTime    float64 // average response time, round float to 2 decimal places
-	avgContentLength   int64   // average content size
-	currentRps         float64 // # reqs/sec
-	currentFailPerSec  float64 // # fails/sec
-	duration           float64 // the duration of stats
+	percentiles        map[string]int64 // response time percentiles, e.g. p50, p99.9
+	medianResponseTime int64            // median response time
+	avgResponseTime    float64          // average response time, round float to 2 decimal places
+	avgContentLength   int64            // average content size
+	currentRps         float64          // # reqs/sec
+	currentFailPerSec  float64          // # fails/sec
+	duration           float64          // the duration of stats
 }
 
 type dataOutput struct {
-	UserCount            int64                             `json:"user_count"`
-	State                int32                             `json:"state"`
-	TotalStats           *statsEntryOutput                 `json:"stats_total"`
-	TransactionsPassed   int64                             `json:"transactions_passed"`
-	TransactionsFailed   int64                             `json:"transactions_failed"`
-	TotalAvgResponseTime float64                           `json:"total_avg_response_time"`
-	TotalMinResponseTime float64                           `json:"total_min_response_time"`
-	TotalMaxResponseTime float64                           `json:"total_max_response_time"`
-	TotalRPS             float64                           `json:"total_rps"`
-	TotalFailRatio       float64                           `json:"total_fail_ratio"`
-	TotalFailPerSec      float64                           `json:"total_fail_per_sec"`
-	Duration             float64                           `json:"duration"`
-	Stats                []*statsEntryOutput               `json:"stats"`
-	Errors               map[string]map[string]interface{} `json:"errors"`
+	UserCount                    int64                             `json:"user_count"`
+	State                        int32                             `json:"state"`
+	TotalStats                   *statsEntryOutput                 `json:"stats_total"`
+	TransactionsPassed           int64                             `json:"transactions_passed"`
+	TransactionsFailed           int64                             `json:"transactions_failed"`
+	DroppedIterations            int64                             `json:"dropped_iterations,omitempty"`
+	TotalAvgResponseTime         float64                           `json:"total_avg_response_time"`
+	TotalMinResponseTime         float64                           `json:"total_min_response_time"`
+	TotalMaxResponseTime         float64                           `json:"total_max_response_time"`
+	TotalResponseTimePercentiles map[string]int64                  `json:"total_response_time_percentiles"`
+	TotalRPS                     float64                           `json:"total_rps"`
+	TotalFailRatio               float64                           `json:"total_fail_ratio"`
+	TotalFailPerSec              float64                           `json:"total_fail_per_sec"`
+	Duration                     float64                           `json:"duration"`
+	Stats                        []*statsEntryOutput               `json:"stats"`
+	Errors                       map[string]map[string]interface{} `json:"errors"`
 }
 
 func convertData(data map[string]interface{}) (output *dataOutput, err error) {
@@ -197,6 +193,9 @@ func convertData(data map[string]interface{}) (output *dataOutput, err error) {
 	transactionsPassed := transactions["passed"]
 	transactionsFailed := transactions["failed"]
 
+	// dropped iterations are only reported in arrival rate mode
+	droppedIterations, _ := data["dropped_iterations"].(int64)
+
 	// convert stats in total
 	statsTotal, ok := data["stats_total"].(interface{})
 	if !ok {
@@ -208,20 +207,22 @@ func convertData(data map[string]interface{}) (output *dataOutput, err error) {
 	}
 
 	output = &dataOutput{
-		UserCount:            userCount,
-		State:                state,
-		Duration:             entryTotalOutput.duration,
-		TotalStats:           entryTotalOutput,
-		TransactionsPassed:   transactionsPassed,
-		TransactionsFailed:   transactionsFailed,
-		TotalAvgResponseTime: entryTotalOutput.avgResponseTime,
-		TotalMaxResponseTime: float64(entryTotalOutput.MaxResponseTime),
-		TotalMinResponseTime: float64(entryTotalOutput.MinResponseTime),
-		TotalRPS:             entryTotalOutput.currentRps,
-		TotalFailRatio:       getTotalFailRatio(entryTotalOutput.NumRequests, entryTotalOutput.NumFailures),
-		TotalFailPerSec:      entryTotalOutput.currentFailPerSec,
-		Stats:                make([]*statsEntryOutput, 0, len(stats)),
-		Errors:               errors,
+		UserCount:                    userCount,
+		State:                        state,
+		Duration:                     entryTotalOutput.duration,
+		TotalStats:                   entryTotalOutput,
+		TransactionsPassed:           transactionsPassed,
+		TransactionsFailed:           transactionsFailed,
+		DroppedIterations:            droppedIterations,
+		TotalAvgResponseTime:         entryTotalOutput.avgResponseTime,
+		TotalMaxResponseTime:         float64(entryTotalOutput.MaxResponseTime),
+		TotalMinResponseTime:         float64(entryTotalOutput.MinResponseTime),
+		TotalResponseTimePercentiles: entryTotalOutput.percentiles,
+		TotalRPS:                     entryTotalOutput.currentRps,
+		TotalFailRatio:               getTotalFailRatio(entryTotalOutput.NumRequests, entryTotalOutput.NumFailures),
+		TotalFailPerSec:              entryTotalOutput.currentFailPerSec,
+		Stats:                        make([]*statsEntryOutput, 0, len(stats)),
+		Errors:                       errors,
 	}
 
 	// convert stats
@@ -248,6 +249,9 @@ func deserializeStatsEntry(stat interface{}) (entryOutput *statsEntryOutput, err
 	if err = json.Unmarshal(statBytes, &entry); err != nil {
 		return nil, err
 	}
+	if entry.ResponseTimes == nil {
+		entry.ResponseTimes = newResponseTimeHistogram()
+	}
 
 	var duration float64
 	if entry.Name == "Total" {
@@ -260,10 +264,12 @@ func deserializeStatsEntry(stat interface{}) (entryOutput *statsEntryOutput, err
 	}
 
 	numRequests := entry.NumRequests
+	percentiles := entry.ResponseTimes.percentiles()
 	entryOutput = &statsEntryOutput{
 		statsEntry:         entry,
 		duration:           duration,
-		medianResponseTime: getMedianResponseTime(numRequests, entry.ResponseTimes),
+		percentiles:        percentiles,
+		medianResponseTime: percentiles["p50"],
 		avgResponseTime:    getAvgResponseTime(numRequests, entry.TotalResponseTime),
 		avgContentLength:   getAvgContentLength(numRequests, entry.TotalContentLength),
 		currentRps:         getCurrentRps(numRequests, duration),
@@ -295,6 +301,13 @@ var (
 		},
 		[]string{"method", "name"},
 	)
+	gaugeResponseTimePercentile = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "response_time_percentile",
+			Help: "The response time percentiles, e.g. p50, p90, p95, p99, p99.9",
+		},
+		[]string{"method", "name", "percentile"},
+	)
 	gaugeAverageResponseTime = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "average_response_time",
@@ -371,9 +384,11 @@ var (
 			Name: "response_time",
 			Help: "The summary of response time",
 			Objectives: map[float64]float64{
-				0.5:  0.01,  // PCT50
-				0.9:  0.01,  // PCT90
-				0.95: 0.005, // PCT95
+				0.5:   0.01,   // PCT50
+				0.9:   0.01,   // PCT90
+				0.95:  0.005,  // PCT95
+				0.99:  0.001,  // PCT99
+				0.999: 0.0001, // PCT99.9
 			},
 			AgeBuckets: 1,
 			MaxAge:     100000 * time.Second,
@@ -452,6 +467,12 @@ var (
 			Help: "The accumulated number of failed transactions",
 		},
 	)
+	gaugeDroppedIterations = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "dropped_iterations",
+			Help: "The accumulated number of iterations dropped by arrival rate executor",
+		},
+	)
 )
 
 var (
@@ -484,6 +505,7 @@ func (o *PrometheusPusherOutput) OnStart() {
 		gaugeNumRequests,
 		gaugeNumFailures,
 		gaugeMedianResponseTime,
+		gaugeResponseTimePercentile,
 		gaugeAverageResponseTime,
 		gaugeMinResponseTime,
 		gaugeMaxResponseTime,
@@ -508,6 +530,7 @@ func (o *PrometheusPusherOutput) OnStart() {
 		gaugeTotalFailPerSec,
 		gaugeTransactionsPassed,
 		gaugeTransactionsFailed,
+		gaugeDroppedIterations,
 	)
 	o.pusher = o.pusher.Gatherer(registry)
 }
@@ -539,6 +562,9 @@ func (o *PrometheusPusherOutput) OnEvent(data map[string]interface{}) {
 	gaugeTotalAverageResponseTime.Set(output.TotalAvgResponseTime)
 	gaugeTotalMinResponseTime.WithLabelValues("", "Total").Set(output.TotalMinResponseTime)
 	gaugeTotalMaxResponseTime.WithLabelValues("", "Total").Set(output.TotalMaxResponseTime)
+	for percentile, responseTime := range output.TotalResponseTimePercentiles {
+		gaugeResponseTimePercentile.WithLabelValues("", "Total", percentile).Set(float64(responseTime))
+	}
 
 	// duration
 	gaugeDuration.Set(output.Duration)
@@ -556,6 +582,9 @@ func (o *PrometheusPusherOutput) OnEvent(data map[string]interface{}) {
 	gaugeTransactionsPassed.Set(float64(output.TransactionsPassed))
 	gaugeTransactionsFailed.Set(float64(output.TransactionsFailed))
 
+	// accumulated number of dropped iterations
+	gaugeDroppedIterations.Set(float64(output.DroppedIterations))
+
 	for _, stat := range output.Stats {
 		method := stat.Method
 		name := stat.Name
@@ -569,10 +598,13 @@ func (o *PrometheusPusherOutput) OnEvent(data map[string]interface{}) {
 		gaugeAverageContentLength.WithLabelValues(method, name).Set(float64(stat.avgContentLength))
 		gaugeCurrentRPS.WithLabelValues(method, name).Set(stat.currentRps)
 		gaugeCurrentFailPerSec.WithLabelValues(method, name).Set(stat.currentFailPerSec)
-		for responseTime, count := range stat.ResponseTimes {
+		for percentile, responseTime := range stat.percentiles {
+			gaugeResponseTimePercentile.WithLabelValues(method, name, percentile).Set(float64(responseTime))
+		}
+		for _, bar := range stat.ResponseTimes.Distribution() {
 			var i int64
-			for i = 0; i < count; i++ {
-				summaryResponseTime.WithLabelValues(method, name).Observe(float64(responseTime))
+			for i = 0; i < bar.Count; i++ {
+				summaryResponseTime.WithLabelValues(method, name).Observe(float64(bar.To))
 			}
 		}
 		// every stat in total
@@ -615,6 +647,7 @@ func resetPrometheusMetrics() {
 	gaugeNumRequests.Reset()
 	gaugeNumFailures.Reset()
 	gaugeMedianResponseTime.Reset()
+	gaugeResponseTimePercentile.Reset()
 	gaugeAverageResponseTime.Reset()
 	gaugeMinResponseTime.Reset()
 	gaugeMaxResponseTime.Reset()
@@ -639,6 +672,7 @@ func resetPrometheusMetrics() {
 	gaugeTotalFailPerSec.Set(0)
 	gaugeTransactionsPassed.Set(0)
 	gaugeTransactionsFailed.Set(0)
+	gaugeDroppedIterations.Set(0)
 
 	minResponseTimeMap = sync.Map{}
 	maxResponseTimeMap = sync.Map{}
@@ -6,27 +6,6 @@ import (
 	"testing"
 )
 
-func TestGetMedianResponseTime(t *testing.T) {
-	numRequests := int64(10)
-	responseTimes := map[int64]int64{
-		100: 1,
-		200: 3,
-		300: 6,
-	}
-
-	medianResponseTime := getMedianResponseTime(numRequests, responseTimes)
-	if medianResponseTime != 300 {
-		t.Error("medianResponseTime should be 300")
-	}
-
-	responseTimes = map[int64]int64{}
-
-	medianResponseTime = getMedianResponseTime(numRequests, responseTimes)
-	if medianResponseTime != 0 {
-		t.Error("medianResponseTime should be 0")
-	}
-}
-
 func TestGetAvgResponseTime(t *testing.T) {
 	numRequests := int64(3)
 	totalResponseTime := int64(100)
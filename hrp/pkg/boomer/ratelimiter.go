@@ -67,7 +67,7 @@ func (limiter *StableRateLimiter) Start() {
 			case <-quitChannel:
 				return
 			default:
-				atomic.StoreInt64(&limiter.currentThreshold, limiter.threshold)
+				atomic.StoreInt64(&limiter.currentThreshold, atomic.LoadInt64(&limiter.threshold))
 				time.Sleep(limiter.refillPeriod)
 				close(limiter.broadcastChannel)
 				// avoid data race
@@ -99,6 +99,11 @@ func (limiter *StableRateLimiter) Stop() {
 	close(limiter.quitChannel)
 }
 
+// setThreshold updates threshold of the bucket, which takes effect in the next refill period.
+func (limiter *StableRateLimiter) setThreshold(threshold int64) {
+	atomic.StoreInt64(&limiter.threshold, threshold)
+}
+
 // ErrParsingRampUpRate is the error returned if the format of rampUpRate is invalid.
 var ErrParsingRampUpRate = errors.New("ratelimiter: invalid format of rampUpRate, try \"1\" or \"1/1s\"")
 
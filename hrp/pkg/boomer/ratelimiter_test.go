@@ -20,6 +20,27 @@ func TestStableRateLimiter(t *testing.T) {
 	}
 }
 
+func TestStableRateLimiterSetThreshold(t *testing.T) {
+	rateLimiter := NewStableRateLimiter(1, 200*time.Millisecond)
+	rateLimiter.Start()
+	defer rateLimiter.Stop()
+
+	if blocked := rateLimiter.Acquire(); blocked {
+		t.Error("Unexpected blocked by rate limiter")
+	}
+	rateLimiter.setThreshold(3)
+	// threshold takes effect after the bucket is refilled
+	if blocked := rateLimiter.Acquire(); !blocked {
+		t.Error("Should be blocked by rate limiter")
+	}
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if blocked := rateLimiter.Acquire(); blocked {
+			t.Error("Unexpected blocked by rate limiter")
+		}
+	}
+}
+
 // FIXME
 // func TestRampUpRateLimiter(t *testing.T) {
 // 	rateLimiter, _ := NewRampUpRateLimiter(100, "10/200ms", 100*time.Millisecond)
@@ -0,0 +1,407 @@
+package boomer
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/copier"
+	"github.com/rs/zerolog/log"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+)
+
+//go:embed report_template.html
+var reportTemplate string
+
+// Report is the end-of-run report of load testing, which is generated from stats snapshots of each report interval.
+type Report struct {
+	StartTime          time.Time          `json:"start_time"`
+	EndTime            time.Time          `json:"end_time"`
+	Duration           float64            `json:"duration"` // seconds
+	Profile            *Profile           `json:"profile,omitempty"`
+	Total              *ReportStats       `json:"total"`
+	Stats              []*ReportStats     `json:"stats"` // accumulated stats of each request and transaction
+	Errors             []*ReportError     `json:"errors"`
+	Thresholds         []*ThresholdResult `json:"thresholds,omitempty"`
+	TransactionsPassed int64              `json:"transactions_passed"`
+	TransactionsFailed int64              `json:"transactions_failed"`
+	DroppedIterations  int64              `json:"dropped_iterations,omitempty"`
+	TimeSeries         []*ReportSnapshot  `json:"time_series"`
+}
+
+// ReportStats is the accumulated stats of request or transaction during load testing.
+type ReportStats struct {
+	Method                  string           `json:"method"`
+	Name                    string           `json:"name"`
+	NumRequests             int64            `json:"num_requests"`
+	NumFailures             int64            `json:"num_failures"`
+	FailRatio               float64          `json:"fail_ratio"`
+	AvgResponseTime         float64          `json:"avg_response_time"`
+	MinResponseTime         int64            `json:"min_response_time"`
+	MaxResponseTime         int64            `json:"max_response_time"`
+	ResponseTimePercentiles map[string]int64 `json:"response_time_percentiles"`
+	AvgContentLength        int64            `json:"avg_content_length"`
+	RPS                     float64          `json:"rps"`
+}
+
+// ReportError is the accumulated occurrences of error during load testing.
+type ReportError struct {
+	Method      string `json:"method"`
+	Name        string `json:"name"`
+	Error       string `json:"error"`
+	Occurrences int64  `json:"occurrences"`
+}
+
+// ReportSnapshot is the stats of requests in one report interval, transactions are excluded.
+type ReportSnapshot struct {
+	Time                    int64            `json:"time"` // unix timestamp in milliseconds
+	UserCount               int64            `json:"user_count"`
+	RPS                     float64          `json:"rps"`
+	FailPerSec              float64          `json:"fail_per_sec"`
+	AvgResponseTime         float64          `json:"avg_response_time"`
+	ResponseTimePercentiles map[string]int64 `json:"response_time_percentiles"`
+	Errors                  int64            `json:"errors"`
+}
+
+func newReportStats(entry *statsEntry, duration float64) *ReportStats {
+	stats := &ReportStats{
+		Method:                  entry.Method,
+		Name:                    entry.Name,
+		NumRequests:             entry.NumRequests,
+		NumFailures:             entry.NumFailures,
+		FailRatio:               getTotalFailRatio(entry.NumRequests, entry.NumFailures),
+		AvgResponseTime:         getAvgResponseTime(entry.NumRequests, entry.TotalResponseTime),
+		MinResponseTime:         entry.MinResponseTime,
+		MaxResponseTime:         entry.MaxResponseTime,
+		ResponseTimePercentiles: entry.ResponseTimes.percentiles(),
+		AvgContentLength:        getAvgContentLength(entry.NumRequests, entry.TotalContentLength),
+	}
+	if duration > 0 {
+		stats.RPS = getCurrentRps(entry.NumRequests, duration)
+	}
+	return stats
+}
+
+// ReportOutput collects stats snapshots in each report interval, and writes the end-of-run report
+// of load testing to dir as boom_report.json and self-contained boom_report.html when load testing is stopped.
+type ReportOutput struct {
+	dir     string
+	profile func() *Profile // returns profile of current load testing
+
+	mutex   sync.Mutex
+	report  *Report
+	entries map[string]*statsEntry  // accumulated stats of requests and transactions
+	errors  map[string]*ReportError // accumulated errors
+}
+
+// NewReportOutput returns a ReportOutput, profile of current load testing is included in the report.
+// Report is skipped if gen-report is disabled in profile, e.g. master started by HTTP API with different profiles.
+func NewReportOutput(dir string, profile func() *Profile) *ReportOutput {
+	return &ReportOutput{
+		dir:     dir,
+		profile: profile,
+	}
+}
+
+// OnStart resets stats collected in the last load testing, profile is copied
+// since it may be updated during load testing, e.g. targets of stages in distributed mode.
+func (o *ReportOutput) OnStart() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.report = nil
+	var profile *Profile
+	if o.profile != nil {
+		if current := o.profile(); current != nil {
+			if !current.GenReport {
+				return
+			}
+			profile = &Profile{}
+			if err := copier.Copy(profile, current); err != nil {
+				log.Error().Err(err).Msg("copy profile failed")
+				profile = nil
+			}
+		}
+	}
+	o.report = &Report{
+		StartTime: time.Now(),
+		Profile:   profile,
+	}
+	o.entries = make(map[string]*statsEntry)
+	o.errors = make(map[string]*ReportError)
+}
+
+// OnEvent appends stats snapshot to time series and accumulates stats and errors.
+func (o *ReportOutput) OnEvent(data map[string]interface{}) {
+	output, err := convertData(data)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to convert data")
+		return
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.report == nil {
+		return
+	}
+
+	snapshot := &ReportSnapshot{
+		Time:      time.Now().UnixMilli(),
+		UserCount: output.UserCount,
+	}
+	var numRequests, totalResponseTime int64
+	responseTimes := newResponseTimeHistogram()
+	for _, stat := range output.Stats {
+		key := stat.Method + stat.Name
+		entry, ok := o.entries[key]
+		if !ok {
+			entry = &statsEntry{
+				Name:          stat.Name,
+				Method:        stat.Method,
+				ResponseTimes: newResponseTimeHistogram(),
+			}
+			o.entries[key] = entry
+		}
+		entry.extend(&stat.statsEntry)
+
+		// transactions and testcases are not counted in total
+		if stat.Method == "transaction" || stat.Method == "testcase" {
+			continue
+		}
+		snapshot.RPS += stat.currentRps
+		snapshot.FailPerSec += stat.currentFailPerSec
+		numRequests += stat.NumRequests
+		totalResponseTime += stat.TotalResponseTime
+		responseTimes.merge(stat.ResponseTimes)
+	}
+	snapshot.AvgResponseTime = getAvgResponseTime(numRequests, totalResponseTime)
+	snapshot.ResponseTimePercentiles = responseTimes.percentiles()
+
+	for key, e := range output.Errors {
+		occurrences, _ := e["occurrences"].(int64)
+		snapshot.Errors += occurrences
+		reportError, ok := o.errors[key]
+		if !ok {
+			reportError = &ReportError{}
+			reportError.Method, _ = e["method"].(string)
+			reportError.Name, _ = e["name"].(string)
+			reportError.Error, _ = e["error"].(string)
+			o.errors[key] = reportError
+		}
+		reportError.Occurrences += occurrences
+	}
+
+	o.report.TimeSeries = append(o.report.TimeSeries, snapshot)
+	o.report.Duration = output.Duration
+	o.report.Total = newReportStats(&output.TotalStats.statsEntry, output.Duration)
+	o.report.TransactionsPassed = output.TransactionsPassed
+	o.report.TransactionsFailed = output.TransactionsFailed
+	o.report.DroppedIterations = output.DroppedIterations
+	if thresholds, ok := data["thresholds"].([]*ThresholdResult); ok {
+		o.report.Thresholds = thresholds
+	}
+}
+
+// OnStop writes report in JSON and HTML.
+func (o *ReportOutput) OnStop() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.report == nil || len(o.report.TimeSeries) == 0 {
+		log.Warn().Msg("no stats collected, skip generating load testing report")
+		return
+	}
+
+	o.report.EndTime = time.Now()
+	o.report.Stats = make([]*ReportStats, 0, len(o.entries))
+	for _, entry := range o.entries {
+		o.report.Stats = append(o.report.Stats, newReportStats(entry, o.report.Duration))
+	}
+	sort.Slice(o.report.Stats, func(i, j int) bool {
+		if o.report.Stats[i].Method != o.report.Stats[j].Method {
+			return o.report.Stats[i].Method < o.report.Stats[j].Method
+		}
+		return o.report.Stats[i].Name < o.report.Stats[j].Name
+	})
+	o.report.Errors = make([]*ReportError, 0, len(o.errors))
+	for _, reportError := range o.errors {
+		o.report.Errors = append(o.report.Errors, reportError)
+	}
+	sort.Slice(o.report.Errors, func(i, j int) bool {
+		return o.report.Errors[i].Occurrences > o.report.Errors[j].Occurrences
+	})
+
+	if err := builtin.EnsureFolderExists(o.dir); err != nil {
+		log.Error().Err(err).Msg("failed to create report dir")
+		return
+	}
+	if err := builtin.Dump2JSON(o.report, filepath.Join(o.dir, "boom_report.json")); err != nil {
+		log.Error().Err(err).Msg("failed to generate JSON report")
+	}
+	if err := o.report.genHTMLReport(filepath.Join(o.dir, "boom_report.html")); err != nil {
+		log.Error().Err(err).Msg("failed to generate HTML report")
+	}
+}
+
+func (r *Report) genHTMLReport(reportPath string) error {
+	file, err := os.OpenFile(reportPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	tmpl := template.Must(template.New("report").Funcs(template.FuncMap{
+		"percentileNames": func() (names []string) {
+			for _, percentile := range responseTimePercentiles {
+				names = append(names, percentileName(percentile))
+			}
+			return names
+		},
+		"upper":         strings.ToUpper,
+		"thresholdName": thresholdName,
+		"formatTime": func(t time.Time) string {
+			return t.Format("2006-01-02 15:04:05")
+		},
+		"percent": func(ratio float64) string {
+			return fmt.Sprintf("%.2f%%", ratio*100)
+		},
+		"toJSON": func(v interface{}) string {
+			b, _ := json.MarshalIndent(v, "", "  ")
+			return string(b)
+		},
+		"charts": r.charts,
+	}).Parse(reportTemplate))
+	if err = tmpl.Execute(writer, r); err != nil {
+		return err
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	log.Info().Str("path", reportPath).Msg("generate HTML report of load testing")
+	return nil
+}
+
+type chartSeries struct {
+	name   string
+	color  string
+	values []float64
+}
+
+// charts renders time series as inline SVG line charts, thus the HTML report has no external dependencies.
+func (r *Report) charts() []template.HTML {
+	n := len(r.TimeSeries)
+	elapsed := make([]float64, n)
+	users := make([]float64, n)
+	rps := make([]float64, n)
+	failPerSec := make([]float64, n)
+	errors := make([]float64, n)
+	avg := make([]float64, n)
+	percentiles := map[string][]float64{}
+	for i, snapshot := range r.TimeSeries {
+		elapsed[i] = float64(snapshot.Time-r.TimeSeries[0].Time) / 1e3
+		users[i] = float64(snapshot.UserCount)
+		rps[i] = snapshot.RPS
+		failPerSec[i] = snapshot.FailPerSec
+		errors[i] = float64(snapshot.Errors)
+		avg[i] = snapshot.AvgResponseTime
+		for _, percentile := range []string{"p50", "p95", "p99"} {
+			percentiles[percentile] = append(percentiles[percentile], float64(snapshot.ResponseTimePercentiles[percentile]))
+		}
+	}
+	return []template.HTML{
+		lineChart("Requests per second", elapsed,
+			chartSeries{"RPS", "#2f7ed8", rps}, chartSeries{"Fails/s", "#d9534f", failPerSec}),
+		lineChart("Response time (ms)", elapsed,
+			chartSeries{"Average", "#8bbc21", avg}, chartSeries{"P50", "#2f7ed8", percentiles["p50"]},
+			chartSeries{"P95", "#f28f43", percentiles["p95"]}, chartSeries{"P99", "#d9534f", percentiles["p99"]}),
+		lineChart("Users", elapsed, chartSeries{"Users", "#492970", users}),
+		lineChart("Errors", elapsed, chartSeries{"Errors", "#d9534f", errors}),
+	}
+}
+
+const (
+	chartWidth   = 560
+	chartHeight  = 220
+	chartPadding = 40
+)
+
+func lineChart(title string, elapsed []float64, series ...chartSeries) template.HTML {
+	maxX, maxY := 0.0, 0.0
+	if len(elapsed) > 0 {
+		maxX = elapsed[len(elapsed)-1]
+	}
+	for _, s := range series {
+		for _, v := range s.values {
+			maxY = math.Max(maxY, v)
+		}
+	}
+	maxY = niceCeil(maxY)
+	plotWidth := float64(chartWidth - 2*chartPadding)
+	plotHeight := float64(chartHeight - 2*chartPadding)
+	x := func(v float64) float64 {
+		if maxX == 0 {
+			return chartPadding + plotWidth/2
+		}
+		return chartPadding + v/maxX*plotWidth
+	}
+	y := func(v float64) float64 {
+		return chartPadding + plotHeight - v/maxY*plotHeight
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<text x="%d" y="20" class="title">%s</text>`, chartPadding, template.HTMLEscapeString(title))
+	// grid lines and labels of y axis
+	for i := 0; i <= 4; i++ {
+		v := maxY * float64(i) / 4
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="grid"/>`,
+			chartPadding, y(v), chartWidth-chartPadding, y(v))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="label" text-anchor="end">%s</text>`,
+			chartPadding-4, y(v)+4, formatChartValue(v))
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label">0s</text>`, chartPadding, chartHeight-chartPadding+16)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label" text-anchor="end">%.0fs</text>`,
+		chartWidth-chartPadding, chartHeight-chartPadding+16, maxX)
+	for i, s := range series {
+		points := make([]string, 0, len(s.values))
+		for j, v := range s.values {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(elapsed[j]), y(v)))
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), s.color)
+		// legend
+		legendX := chartPadding + i*90
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`, legendX, chartHeight-14, s.color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="label">%s</text>`, legendX+14, chartHeight-5, template.HTMLEscapeString(s.name))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// niceCeil rounds up max value of y axis to 1, 2 or 5 times power of 10, e.g. 73 to 100, 0.3 to 0.5.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if v <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func formatChartValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
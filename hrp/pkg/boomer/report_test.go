@@ -0,0 +1,149 @@
+package boomer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+)
+
+func TestReportOutput(t *testing.T) {
+	dir := t.TempDir()
+	profile := NewProfile()
+	profile.SpawnCount = 10
+	profile.GenReport = true
+	o := NewReportOutput(dir, func() *Profile { return profile })
+	o.OnStart()
+	// profile updated during load testing should not affect report
+	profile.SpawnCount = 20
+
+	checker, err := newThresholdChecker([]*Threshold{
+		{Conditions: []string{"fail_ratio < 10%"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := newRequestStats()
+	collect := func() map[string]interface{} {
+		checker.accumulate(stats.entries)
+		checker.check(stats.total)
+		data := stats.collectReportData()
+		data["user_count"] = int64(10)
+		data["state"] = int32(StateRunning)
+		data["thresholds"] = checker.getResults()
+		return data
+	}
+
+	stats.total.StartTime -= 2000
+	stats.logRequest("http", "login", 10, 100)
+	stats.logRequest("http", "login", 30, 100)
+	stats.logError("http", "login", "500 error")
+	stats.logTransaction("login", true, 50, 200)
+	o.OnEvent(collect())
+
+	stats.logRequest("http", "login", 20, 100)
+	stats.logRequest("http", "home", 40, 300)
+	stats.logError("http", "login", "500 error")
+	stats.logTransaction("login", false, 60, 200)
+	o.OnEvent(collect())
+
+	o.OnStop()
+
+	content, err := os.ReadFile(filepath.Join(dir, "boom_report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err = json.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.TimeSeries) != 2 {
+		t.Fatal("length of time series should be 2, got:", len(report.TimeSeries))
+	}
+	if report.TimeSeries[0].Errors != 1 || report.TimeSeries[1].AvgResponseTime != 30 {
+		t.Errorf("snapshots are wrong, got: %+v, %+v", report.TimeSeries[0], report.TimeSeries[1])
+	}
+	if report.Total.NumRequests != 4 || report.Total.NumFailures != 2 || report.Total.MaxResponseTime != 40 {
+		t.Errorf("total stats are wrong, got: %+v", report.Total)
+	}
+	if report.TransactionsPassed != 1 || report.TransactionsFailed != 1 {
+		t.Error("transactions are wrong, got:", report.TransactionsPassed, report.TransactionsFailed)
+	}
+
+	// stats are accumulated across report intervals
+	if len(report.Stats) != 3 {
+		t.Fatal("length of stats should be 3, got:", len(report.Stats))
+	}
+	login := report.Stats[1]
+	if login.Method != "http" || login.Name != "login" || login.NumRequests != 3 || login.NumFailures != 2 ||
+		login.AvgResponseTime != 20 || login.ResponseTimePercentiles["p50"] != 20 {
+		t.Errorf("stats of login are wrong, got: %+v", login)
+	}
+	if transaction := report.Stats[2]; transaction.Method != "transaction" || transaction.NumRequests != 2 {
+		t.Errorf("stats of transaction are wrong, got: %+v", transaction)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Occurrences != 2 || report.Errors[0].Error != "500 error" {
+		t.Errorf("errors are wrong, got: %+v", report.Errors)
+	}
+	if len(report.Thresholds) != 1 || report.Thresholds[0].Passed {
+		t.Errorf("thresholds are wrong, got: %+v", report.Thresholds)
+	}
+	if report.Profile == nil || report.Profile.SpawnCount != 10 {
+		t.Error("profile copied at start should be included in report, got:", report.Profile)
+	}
+
+	html, err := os.ReadFile(filepath.Join(dir, "boom_report.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"<svg", "login", "500 error", "fail_ratio &lt; 10%", "P99.9", "spawn-count"} {
+		if !strings.Contains(string(html), expected) {
+			t.Errorf("HTML report should contain %q", expected)
+		}
+	}
+}
+
+func TestReportOutputWithoutStats(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	o := NewReportOutput(dir, nil)
+	o.OnStart()
+	o.OnStop()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("report should not be generated without stats")
+	}
+}
+
+func TestReportOutputWithGenReportDisabled(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	o := NewReportOutput(dir, NewProfile)
+	o.OnStart()
+	stats := newRequestStats()
+	stats.logRequest("http", "login", 10, 100)
+	o.OnEvent(stats.collectReportData())
+	o.OnStop()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("report should not be generated if gen-report is disabled")
+	}
+}
+
+func TestNiceCeil(t *testing.T) {
+	testData := []struct {
+		value    float64
+		expected float64
+	}{
+		{0, 1},
+		{0.3, 0.5},
+		{1, 1},
+		{73, 100},
+		{120, 200},
+		{4000, 5000},
+	}
+	for _, data := range testData {
+		if actual := niceCeil(data.value); actual != data.expected {
+			t.Errorf("niceCeil(%v) should be %v, got: %v", data.value, data.expected, actual)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
 	"github.com/httprunner/httprunner/v4/hrp/pkg/boomer/grpc/messager"
 )
 
@@ -229,6 +231,17 @@ type runner struct {
 	wg sync.WaitGroup
 
 	outputs []Output
+
+	// onReport is called with stats data in each report interval, e.g. worker sends stats to master
+	onReport func(data map[string]interface{})
+
+	// thresholds are checked against accumulated stats in each report interval
+	thresholds *thresholdChecker
+
+	// stages drive users and rps of load testing, load testing is stopped after the last stage
+	stages *loadStages
+	// arrivalRate is the open-model executor of arrival rate mode, iterations are started at target rate
+	arrivalRate *arrivalRateExecutor
 }
 
 func (r *runner) setSpawnRate(spawnRate float64) {
@@ -334,12 +347,35 @@ func (r *runner) outputOnStop() {
 }
 
 func (r *runner) reportStats() {
+	if r.checkThresholds() && r.isStarting() {
+		log.Error().Msg("thresholds breached, abort load testing")
+		go r.stop()
+	}
 	data := r.stats.collectReportData()
 	data["user_count"] = r.controller.getCurrentClientsNum()
 	data["state"] = atomic.LoadInt32(&r.state)
+	if r.arrivalRate != nil {
+		data["dropped_iterations"] = r.arrivalRate.getDroppedIterations()
+	}
+	if r.thresholds != nil {
+		data["thresholds"] = r.thresholds.getResults()
+	}
+	if r.onReport != nil {
+		r.onReport(data)
+	}
 	r.outputOnEvent(data)
 }
 
+// checkThresholds checks thresholds before stats of report interval are reset,
+// returns true if load testing should be aborted.
+func (r *runner) checkThresholds() bool {
+	if r.thresholds == nil {
+		return false
+	}
+	r.thresholds.accumulate(r.stats.entries)
+	return r.thresholds.check(r.stats.total)
+}
+
 func (r *runner) reportTestResult() {
 	// convert stats in total
 	var statsTotal interface{} = r.stats.total.serialize()
@@ -353,26 +389,49 @@ func (r *runner) reportTestResult() {
 	println(fmt.Sprintf("Current time: %s, Users: %v, Duration: %v, Accumulated Transactions: %d Passed, %d Failed",
 		currentTime.Format("2006/01/02 15:04:05"), r.controller.getCurrentClientsNum(), duration, r.stats.transactionPassed, r.stats.transactionFailed))
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Name", "# requests", "# fails", "Median", "Average", "Min", "Max", "Content Size", "# reqs/sec", "# fails/sec"})
-	row := make([]string, 10)
-	row[0] = entryTotalOutput.Name
-	row[1] = strconv.FormatInt(entryTotalOutput.NumRequests, 10)
-	row[2] = strconv.FormatInt(entryTotalOutput.NumFailures, 10)
-	row[3] = strconv.FormatInt(entryTotalOutput.medianResponseTime, 10)
-	row[4] = strconv.FormatFloat(entryTotalOutput.avgResponseTime, 'f', 2, 64)
-	row[5] = strconv.FormatInt(entryTotalOutput.MinResponseTime, 10)
-	row[6] = strconv.FormatInt(entryTotalOutput.MaxResponseTime, 10)
-	row[7] = strconv.FormatInt(entryTotalOutput.avgContentLength, 10)
-	row[8] = strconv.FormatFloat(entryTotalOutput.currentRps, 'f', 2, 64)
-	row[9] = strconv.FormatFloat(entryTotalOutput.currentFailPerSec, 'f', 2, 64)
+	header := []string{"Name", "# requests", "# fails", "Average", "Min"}
+	for _, percentile := range responseTimePercentiles {
+		header = append(header, strings.ToUpper(percentileName(percentile)))
+	}
+	header = append(header, "Max", "Content Size", "# reqs/sec", "# fails/sec")
+	table.SetHeader(header)
+	row := []string{
+		entryTotalOutput.Name,
+		strconv.FormatInt(entryTotalOutput.NumRequests, 10),
+		strconv.FormatInt(entryTotalOutput.NumFailures, 10),
+		strconv.FormatFloat(entryTotalOutput.avgResponseTime, 'f', 2, 64),
+		strconv.FormatInt(entryTotalOutput.MinResponseTime, 10),
+	}
+	for _, percentile := range responseTimePercentiles {
+		row = append(row, strconv.FormatInt(entryTotalOutput.percentiles[percentileName(percentile)], 10))
+	}
+	row = append(row,
+		strconv.FormatInt(entryTotalOutput.MaxResponseTime, 10),
+		strconv.FormatInt(entryTotalOutput.avgContentLength, 10),
+		strconv.FormatFloat(entryTotalOutput.currentRps, 'f', 2, 64),
+		strconv.FormatFloat(entryTotalOutput.currentFailPerSec, 'f', 2, 64),
+	)
 	table.Append(row)
 	table.Render()
 	println()
+
+	if r.arrivalRate != nil {
+		println(fmt.Sprintf("Arrival Rate: %d/s, Max VUs: %d, Dropped Iterations: %d",
+			r.arrivalRate.getRate(), r.arrivalRate.maxVUs, r.arrivalRate.getDroppedIterations()))
+		println()
+	}
+
+	if r.thresholds != nil {
+		r.thresholds.printResults()
+	}
 }
 
 func (r *runner) reset() {
 	r.controller.reset()
 	r.stats.clearAll()
+	if r.thresholds != nil {
+		r.thresholds.reset()
+	}
 	r.stoppingChan = make(chan bool)
 	r.doneChan = make(chan bool)
 	r.reportedChan = make(chan bool)
@@ -660,7 +719,25 @@ func (r *localRunner) start() {
 
 	go r.runTimeCheck(r.getRunTime())
 
-	go r.spawnWorkers(r.getSpawnCount(), r.getSpawnRate(), r.stoppingChan, nil)
+	if r.stages != nil {
+		users, spawnRate, rps := r.stages.target(0)
+		if r.arrivalRate != nil {
+			r.arrivalRate.setRate(rps)
+		} else if r.stages.users {
+			r.setSpawnCount(users)
+			r.setSpawnRate(spawnRate)
+		}
+		if r.stages.rps {
+			r.setMaxRPS(rps)
+		}
+		go r.runStages(r.stoppingChan, r.applyStage, r.stop)
+	}
+
+	if r.arrivalRate != nil {
+		go r.runArrivalRate(r.stoppingChan)
+	} else {
+		go r.spawnWorkers(r.getSpawnCount(), r.getSpawnRate(), r.stoppingChan, nil)
+	}
 
 	defer func() {
 		// block concurrent waitgroup adds in GoAttach while stopping
@@ -731,9 +808,23 @@ func newWorkerRunner(masterHost string, masterPort int) (r *workerRunner) {
 		mutex:      sync.Mutex{},
 		ignoreQuit: false,
 	}
+	r.onReport = r.sendStats
 	return r
 }
 
+// sendStats sends stats of report interval to master, which are merged with stats of other workers.
+func (r *workerRunner) sendStats(data map[string]interface{}) {
+	if r.client == nil {
+		return
+	}
+	statsBytes, err := json.Marshal(data)
+	if err != nil {
+		log.Error().Err(err).Msg("marshal stats failed")
+		return
+	}
+	r.client.sendChannel() <- newGenericMessage(typeStats, map[string][]byte{"stats": statsBytes}, r.nodeID)
+}
+
 func (r *workerRunner) spawnComplete() {
 	data := make(map[string][]byte)
 	data["count"] = builtin.Int64ToBytes(r.controller.getSpawnCount())
@@ -766,6 +857,9 @@ func (r *workerRunner) onRebalanceMessage(msg *genericMessage) {
 	profile := BytesToProfile(msg.Profile)
 	r.setSpawnCount(profile.SpawnCount)
 	r.setSpawnRate(profile.SpawnRate)
+	if profile.MaxRPS > 0 {
+		r.setMaxRPS(profile.MaxRPS)
+	}
 
 	r.tasksChan <- &task{
 		Profile: profile,
@@ -1018,6 +1112,9 @@ type masterRunner struct {
 
 	profile *Profile
 
+	// statsMutex protects stats merged from workers
+	statsMutex sync.Mutex
+
 	parseTestCasesChan chan bool
 	testCaseBytesChan  chan []byte
 	testCasesBytes     []byte
@@ -1032,6 +1129,8 @@ func newMasterRunner(masterBindHost string, masterBindPort int) *masterRunner {
 			closeChan:    make(chan bool),
 			wg:           sync.WaitGroup{},
 			wgMu:         sync.RWMutex{},
+			stats:        newRequestStats(),
+			outputs:      make([]Output, 0),
 		},
 		masterBindHost:     masterBindHost,
 		masterBindPort:     masterBindPort,
@@ -1073,6 +1172,7 @@ func (r *masterRunner) heartbeatWorker() {
 			})
 		case <-reportTicker.C:
 			r.reportStats()
+			r.reportWorkersStats()
 		}
 	}
 }
@@ -1123,6 +1223,15 @@ func (r *masterRunner) clientListener() {
 					if ok {
 						workerInfo.updateUserCount(builtin.BytesToInt64(currentUsers))
 					}
+				case typeStats:
+					ws := &workerStats{}
+					if err := json.Unmarshal(msg.Data["stats"], ws); err != nil {
+						log.Error().Err(err).Str("worker", msg.NodeID).Msg("unmarshal worker stats failed")
+						break
+					}
+					r.statsMutex.Lock()
+					r.stats.mergeWorkerStats(msg.NodeID, ws)
+					r.statsMutex.Unlock()
 				case typeSpawning:
 					workerInfo.setState(StateSpawning)
 				case typeSpawningComplete:
@@ -1242,6 +1351,21 @@ func (r *masterRunner) start() error {
 		return err
 	}
 
+	// initial targets of stages, users and rps are at least the number of workers
+	if r.stages != nil {
+		users, spawnRate, rps := r.stages.target(0)
+		if r.stages.users {
+			r.profile.SpawnCount = maxInt64(users, int64(numWorkers))
+			r.profile.SpawnRate = spawnRate
+			r.setSpawnCount(r.profile.SpawnCount)
+			r.setSpawnRate(spawnRate)
+		}
+		if r.stages.rps {
+			r.profile.MaxRPS = maxInt64(rps, int64(numWorkers))
+			r.profile.RequestIncreaseRate = "-1"
+		}
+	}
+
 	workerProfile := &Profile{}
 	if err := copier.Copy(workerProfile, r.profile); err != nil {
 		log.Error().Err(err).Msg("copy workerProfile failed")
@@ -1260,6 +1384,18 @@ func (r *masterRunner) start() error {
 	// max RPS
 	maxRPSs := builtin.SplitInteger(int(workerProfile.MaxRPS), numWorkers)
 
+	// stopping channel is closed by stop, thus stage driver of this run quits before next run
+	r.stoppingChan = make(chan bool)
+
+	// clear stats merged from workers of last run
+	r.statsMutex.Lock()
+	r.stats.clearAll()
+	if r.thresholds != nil {
+		r.thresholds.reset()
+	}
+	r.statsMutex.Unlock()
+	r.outputOnStart()
+
 	r.updateState(StateSpawning)
 	log.Info().Msg("send spawn data to worker")
 
@@ -1288,6 +1424,14 @@ func (r *masterRunner) start() error {
 	})
 
 	log.Warn().Interface("profile", r.profile).Msg("send spawn data to worker successfully")
+
+	if r.stages != nil {
+		go r.runStages(r.stoppingChan, r.applyStage, func() {
+			if err := r.stop(); err != nil {
+				log.Error().Err(err).Msg("failed to stop load testing after stages")
+			}
+		})
+	}
 	return nil
 }
 
@@ -1369,6 +1513,7 @@ func (r *masterRunner) fetchTestCases() ([]byte, error) {
 func (r *masterRunner) stop() error {
 	if r.isStarting() {
 		r.updateState(StateStopping)
+		close(r.stoppingChan)
 		r.server.sendBroadcasts(&genericMessage{Type: "stop"})
 		return nil
 	} else {
@@ -1387,6 +1532,13 @@ func (r *masterRunner) onQuiting() {
 
 func (r *masterRunner) close() {
 	r.onQuiting()
+	r.outputOnStop()
+	r.statsMutex.Lock()
+	if r.thresholds != nil {
+		r.checkThresholds()
+		r.thresholds.printResults()
+	}
+	r.statsMutex.Unlock()
 	close(r.closeChan)
 }
 
@@ -1415,3 +1567,31 @@ func (r *masterRunner) reportStats() {
 	table.Render()
 	println()
 }
+
+// reportWorkersStats outputs stats merged from all workers, percentiles are calculated from merged histograms.
+func (r *masterRunner) reportWorkersStats() {
+	if !r.isStarting() {
+		return
+	}
+	r.statsMutex.Lock()
+	if r.stats.total.NumRequests == 0 {
+		r.statsMutex.Unlock()
+		return
+	}
+	abort := r.checkThresholds()
+	data := r.stats.collectReportData()
+	if r.thresholds != nil {
+		data["thresholds"] = r.thresholds.getResults()
+	}
+	r.statsMutex.Unlock()
+	if abort {
+		log.Error().Msg("thresholds breached, abort load testing")
+		if err := r.stop(); err != nil {
+			log.Error().Err(err).Msg("failed to stop load testing")
+		}
+	}
+
+	data["user_count"] = int64(r.server.getCurrentUsers())
+	data["state"] = r.getState()
+	r.outputOnEvent(data)
+}
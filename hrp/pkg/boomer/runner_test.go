@@ -265,14 +265,14 @@ func TestSpawnAndStop(t *testing.T) {
 		t.Error("Number of goroutines mismatches, expected: 10, current count", runner.controller.getCurrentClientsNum())
 	}
 
-	msg := <-runner.client.sendChannel()
+	msg := recvMessage(runner)
 	if msg.Type != "spawning_complete" {
 		t.Error("Runner should send spawning_complete message when spawning completed, got", msg.Type)
 	}
 	go runner.stop()
 
 	runner.onQuiting()
-	msg = <-runner.client.sendChannel()
+	msg = recvMessage(runner)
 	if msg.Type != "quit" {
 		t.Error("Runner should send quit message on quitting, got", msg.Type)
 	}
@@ -356,6 +356,16 @@ func TestOnQuitMessage(t *testing.T) {
 	}
 }
 
+// recvMessage receives message sent by worker, stats reported in each interval are skipped.
+func recvMessage(runner *workerRunner) *genericMessage {
+	for {
+		msg := <-runner.client.sendChannel()
+		if msg.Type != typeStats {
+			return msg
+		}
+	}
+}
+
 func TestOnMessage(t *testing.T) {
 	taskA := &Task{
 		Fn: func() {
@@ -378,7 +388,7 @@ func TestOnMessage(t *testing.T) {
 	runner.onMessage(newMessageToWorker("spawn", ProfileToBytes(&Profile{SpawnCount: 10, SpawnRate: 10}), nil, nil))
 	go runner.start()
 
-	msg := <-runner.client.sendChannel()
+	msg := recvMessage(runner)
 	if msg.Type != "spawning" {
 		t.Error("Runner should send spawning message when starting spawn, got", msg.Type)
 	}
@@ -388,7 +398,7 @@ func TestOnMessage(t *testing.T) {
 	if runner.controller.getCurrentClientsNum() != 10 {
 		t.Error("Number of goroutines mismatches, expected: 10, current count:", runner.controller.getCurrentClientsNum())
 	}
-	msg = <-runner.client.sendChannel()
+	msg = recvMessage(runner)
 	if msg.Type != "spawning_complete" {
 		t.Error("Runner should send spawning_complete message when spawn completed, got", msg.Type)
 	}
@@ -413,7 +423,7 @@ func TestOnMessage(t *testing.T) {
 	if runner.getState() != StateStopped {
 		t.Error("State of runner is not stopped, got", getStateName(runner.getState()))
 	}
-	msg = <-runner.client.sendChannel()
+	msg = recvMessage(runner)
 	if msg.Type != "client_stopped" {
 		t.Error("Runner should send client_stopped message, got", msg.Type)
 	}
@@ -424,7 +434,7 @@ func TestOnMessage(t *testing.T) {
 	runner.onMessage(newMessageToWorker("spawn", ProfileToBytes(&Profile{SpawnCount: 10, SpawnRate: 10}), nil, nil))
 	go runner.start()
 
-	msg = <-runner.client.sendChannel()
+	msg = recvMessage(runner)
 	if msg.Type != "spawning" {
 		t.Error("Runner should send spawning message when starting spawn, got", msg.Type)
 	}
@@ -437,7 +447,7 @@ func TestOnMessage(t *testing.T) {
 	if runner.getState() != StateRunning {
 		t.Error("State of runner is not running after spawn, got", getStateName(runner.getState()))
 	}
-	msg = <-runner.client.sendChannel()
+	msg = recvMessage(runner)
 	if msg.Type != "spawning_complete" {
 		t.Error("Runner should send spawning_complete message when spawn completed, got", msg.Type)
 	}
@@ -447,7 +457,7 @@ func TestOnMessage(t *testing.T) {
 	if runner.getState() != StateStopped {
 		t.Error("State of runner is not stopped, got", getStateName(runner.getState()))
 	}
-	msg = <-runner.client.sendChannel()
+	msg = recvMessage(runner)
 	if msg.Type != "client_stopped" {
 		t.Error("Runner should send client_stopped message, got", msg.Type)
 	}
@@ -0,0 +1,202 @@
+package boomer
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// stageInterval is the interval to apply targets of stages
+const stageInterval = time.Second
+
+// Stage is a stage of load profile, users and rps are ramped linearly from targets of the previous stage
+// to targets of this stage during the duration, e.g. [{duration: 2m, users: 100}, {duration: 5m, users: 100}]
+// ramps up to 100 users in 2 minutes and holds for 5 minutes. The first stage starts from 0.
+// Targets driven by stages should be positive in every stage, 0 is rejected since load testing is stopped
+// after the last stage and ramping down to 0 is not needed.
+type Stage struct {
+	Duration string `json:"duration" yaml:"duration" mapstructure:"duration"`                      // duration of stage, e.g. 30s, 2m
+	Users    int64  `json:"users,omitempty" yaml:"users,omitempty" mapstructure:"users,omitempty"` // target users at the end of stage
+	RPS      int64  `json:"rps,omitempty" yaml:"rps,omitempty" mapstructure:"rps,omitempty"`       // target requests per second at the end of stage, arrival-rate mode
+}
+
+// loadStages calculates targets of users and rps at elapsed time of load testing.
+type loadStages struct {
+	stages    []*Stage
+	durations []time.Duration
+	users     bool // users are driven by stages
+	rps       bool // rps are driven by stages
+}
+
+func newLoadStages(stages []*Stage) (*loadStages, error) {
+	s := &loadStages{}
+	for i, stage := range stages {
+		if stage == nil {
+			continue
+		}
+		duration, err := time.ParseDuration(stage.Duration)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid duration of stage %d", i+1)
+		}
+		if duration <= 0 {
+			return nil, errors.Errorf("duration of stage %d should be positive", i+1)
+		}
+		if stage.Users < 0 || stage.RPS < 0 {
+			return nil, errors.Errorf("users and rps of stage %d should not be negative", i+1)
+		}
+		s.stages = append(s.stages, stage)
+		s.durations = append(s.durations, duration)
+		s.users = s.users || stage.Users > 0
+		s.rps = s.rps || stage.RPS > 0
+	}
+	if !s.users && !s.rps {
+		return nil, errors.New("stages should specify target users or rps")
+	}
+	for i, stage := range s.stages {
+		if s.users && stage.Users == 0 {
+			return nil, errors.Errorf("users of stage %d should be positive, stages are ramped by users", i+1)
+		}
+		if s.rps && stage.RPS == 0 {
+			return nil, errors.Errorf("rps of stage %d should be positive, stages are ramped by rps", i+1)
+		}
+	}
+	return s, nil
+}
+
+func (s *loadStages) totalDuration() (total time.Duration) {
+	for _, duration := range s.durations {
+		total += duration
+	}
+	return total
+}
+
+// target returns target users, spawn rate and rps at elapsed time, targets of the last stage are held
+// after all stages are finished. Users and rps are at least 1 when ramping up from 0 in the first stage.
+func (s *loadStages) target(elapsed time.Duration) (users int64, spawnRate float64, rps int64) {
+	var prevUsers, prevRPS int64
+	var start time.Duration
+	for i, stage := range s.stages {
+		duration := s.durations[i]
+		if elapsed < start+duration {
+			progress := float64(elapsed-start) / float64(duration)
+			users = prevUsers + int64(math.Round(float64(stage.Users-prevUsers)*progress))
+			rps = prevRPS + int64(math.Round(float64(stage.RPS-prevRPS)*progress))
+			spawnRate = math.Abs(float64(stage.Users-prevUsers)) / duration.Seconds()
+			return maxInt64(users, 1), math.Max(spawnRate, 1), maxInt64(rps, 1)
+		}
+		prevUsers, prevRPS = stage.Users, stage.RPS
+		start += duration
+	}
+	return maxInt64(prevUsers, 1), 1, maxInt64(prevRPS, 1)
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// runStages applies targets of stages in each interval until all stages are finished, then load testing is stopped.
+// It returns early if quit is closed or apply returns false, e.g. load testing is stopped by user.
+func (r *runner) runStages(quit <-chan bool, apply func(users int64, spawnRate float64, rps int64) bool, stop func()) {
+	startTime := time.Now()
+	totalDuration := r.stages.totalDuration()
+	ticker := time.NewTicker(stageInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+			elapsed := time.Since(startTime)
+			if elapsed >= totalDuration {
+				log.Info().Dur("duration", totalDuration).Msg("all stages finished, stop load testing")
+				stop()
+				return
+			}
+			if !apply(r.stages.target(elapsed)) {
+				return
+			}
+		}
+	}
+}
+
+// setMaxRPS updates threshold of stable rate limiter, e.g. rps driven by stages.
+func (r *runner) setMaxRPS(maxRPS int64) {
+	if limiter, ok := r.rateLimiter.(*StableRateLimiter); ok && r.rateLimitEnabled {
+		limiter.setThreshold(maxRPS)
+	}
+}
+
+// notifyRebalance notifies spawning goroutine to rebalance users without blocking,
+// it is skipped if the runner is stopping since rebalance channel is closed.
+func (r *runner) notifyRebalance() {
+	r.wgMu.RLock()
+	defer r.wgMu.RUnlock()
+	select {
+	case <-r.stoppingChan:
+		return
+	default:
+	}
+	select {
+	case r.controller.getRebalanceChan() <- true:
+	default:
+	}
+}
+
+// applyStage applies targets of stage to local runner, spawning goroutine spawns or erases users to target.
+func (r *localRunner) applyStage(users int64, spawnRate float64, rps int64) bool {
+	if !r.isStarting() {
+		return false
+	}
+	// arrival rate is ramped by executor in each tick, users are allocated on demand
+	if r.arrivalRate != nil {
+		return true
+	}
+	if r.stages.users && users != r.controller.getSpawnCount() {
+		r.setSpawnCount(users)
+		r.setSpawnRate(spawnRate)
+		r.controller.setSpawn(users, spawnRate)
+		r.notifyRebalance()
+	}
+	if r.stages.rps {
+		r.setMaxRPS(rps)
+	}
+	return true
+}
+
+// applyStage applies targets of stage to master runner, which are split and sent to workers by rebalance.
+// Users and rps are at least the number of workers, thus every worker is running with rate limit.
+func (r *masterRunner) applyStage(users int64, spawnRate float64, rps int64) bool {
+	if !r.isStarting() {
+		return false
+	}
+	numWorkers := int64(r.server.getAvailableClientsLength())
+	changed := false
+	if r.stages.users {
+		users = maxInt64(users, numWorkers)
+		if users != r.profile.SpawnCount {
+			r.setSpawnCount(users)
+			r.setSpawnRate(spawnRate)
+			r.profile.SpawnCount = users
+			r.profile.SpawnRate = spawnRate
+			changed = true
+		}
+	}
+	if r.stages.rps {
+		rps = maxInt64(rps, numWorkers)
+		if rps != r.profile.MaxRPS {
+			r.profile.MaxRPS = rps
+			changed = true
+		}
+	}
+	if changed {
+		if err := r.rebalance(); err != nil {
+			log.Error().Err(err).Msg("failed to rebalance stage")
+		}
+	}
+	return true
+}
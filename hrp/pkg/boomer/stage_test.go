@@ -0,0 +1,106 @@
+package boomer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewLoadStages(t *testing.T) {
+	invalid := [][]*Stage{
+		{{Duration: "10", Users: 10}},
+		{{Duration: "-1s", Users: 10}},
+		{{Duration: "10s", Users: -1}},
+		{{Duration: "10s"}},
+		{{Duration: "10s", Users: 10}, {Duration: "10s", Users: 0}},
+		{{Duration: "10s", Users: 10, RPS: 100}, {Duration: "10s", Users: 10}},
+		{},
+	}
+	for _, stages := range invalid {
+		if _, err := newLoadStages(stages); err == nil {
+			t.Errorf("stages %+v should be invalid", stages)
+		}
+	}
+
+	s, err := newLoadStages([]*Stage{{Duration: "10s", RPS: 100}, nil, {Duration: "1m", RPS: 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.users || !s.rps {
+		t.Error("only rps should be driven by stages")
+	}
+	if s.totalDuration() != 70*time.Second {
+		t.Error("total duration of stages is wrong, got:", s.totalDuration())
+	}
+}
+
+func TestLoadStagesTarget(t *testing.T) {
+	s, err := newLoadStages([]*Stage{
+		{Duration: "10s", Users: 100, RPS: 1000},
+		{Duration: "20s", Users: 100, RPS: 1000},
+		{Duration: "5s", Users: 500, RPS: 200},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := []struct {
+		elapsed   time.Duration
+		users     int64
+		spawnRate float64
+		rps       int64
+	}{
+		{0, 1, 10, 1}, // ramp up from 0, at least 1
+		{5 * time.Second, 50, 10, 500},
+		{10 * time.Second, 100, 1, 1000}, // hold
+		{29 * time.Second, 100, 1, 1000},
+		{31 * time.Second, 180, 80, 840}, // spike
+		{35 * time.Second, 500, 1, 200},  // targets of the last stage are held
+		{time.Minute, 500, 1, 200},
+	}
+	for _, data := range testData {
+		users, spawnRate, rps := s.target(data.elapsed)
+		if users != data.users || spawnRate != data.spawnRate || rps != data.rps {
+			t.Errorf("target at %v is wrong, expected: (%d, %v, %d), got: (%d, %v, %d)",
+				data.elapsed, data.users, data.spawnRate, data.rps, users, spawnRate, rps)
+		}
+	}
+}
+
+func TestLocalRunnerWithStages(t *testing.T) {
+	runner := newLocalRunner(1, 1)
+	stages, err := newLoadStages([]*Stage{
+		{Duration: "2s", Users: 10},
+		{Duration: "1s", Users: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.stages = stages
+	runner.setTasks([]*Task{
+		{
+			Weight: 10,
+			Fn: func() {
+				time.Sleep(10 * time.Millisecond)
+			},
+			Name: "TaskA",
+		},
+	})
+
+	done := make(chan bool)
+	go func() {
+		runner.start()
+		close(done)
+	}()
+
+	time.Sleep(2500 * time.Millisecond)
+	if count := runner.controller.getSpawnCount(); count < 5 || count > 10 {
+		t.Error("spawn count should be ramped by stages, got:", count)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		runner.stop()
+		t.Fatal("runner should be stopped after all stages are finished")
+	}
+}
@@ -1,9 +1,12 @@
 package boomer
 
 import (
+	"strconv"
 	"sync/atomic"
 	"time"
 
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+
 	"github.com/httprunner/httprunner/v4/hrp/internal/json"
 )
 
@@ -40,6 +43,9 @@ type requestStats struct {
 
 	requestSuccessChan chan *requestSuccess
 	requestFailureChan chan *requestFailure
+
+	// latest stats reported by each worker, only used by master
+	workerStats map[string]*workerStats
 }
 
 func newRequestStats() (stats *requestStats) {
@@ -106,7 +112,7 @@ func (s *requestStats) get(name string, method string) (entry *statsEntry) {
 		newEntry := &statsEntry{
 			Name:          name,
 			Method:        method,
-			ResponseTimes: make(map[int64]int64),
+			ResponseTimes: newResponseTimeHistogram(),
 		}
 		s.entries[name+method] = newEntry
 		return newEntry
@@ -124,6 +130,7 @@ func (s *requestStats) clearAll() {
 	s.transactionFailed = 0
 	s.entries = make(map[string]*statsEntry)
 	s.errors = make(map[string]*statsError)
+	s.workerStats = nil
 	s.startTime = time.Now().Unix()
 }
 
@@ -174,11 +181,10 @@ type statsEntry struct {
 	MinResponseTime int64 `json:"min_response_time"`
 	// Maximum response time
 	MaxResponseTime int64 `json:"max_response_time"`
-	// A {response_time => count} dict that holds the response time distribution of all the requests
-	// The keys (the response time in ms) are rounded to store 1, 2, ... 9, 10, 20. .. 90,
-	// 100, 200 .. 900, 1000, 2000 ... 9000, in order to save memory.
-	// This dict is used to calculate the median and percentile response times.
-	ResponseTimes map[int64]int64 `json:"response_times"`
+	// HDR histogram that holds the response time distribution of all the requests with 3 significant digits,
+	// it is serialized as base64 encoded compressed histogram, thus could be merged across workers.
+	// This histogram is used to calculate the median and percentile response times.
+	ResponseTimes *responseTimeHistogram `json:"response_times"`
 	// The sum of the content length of all the requests for this entry
 	TotalContentLength int64 `json:"total_content_length"`
 	// Time of the first request for this entry
@@ -199,7 +205,11 @@ func (s *statsEntry) reset() {
 	s.NumRequests = 0
 	s.NumFailures = 0
 	s.TotalResponseTime = 0
-	s.ResponseTimes = make(map[int64]int64)
+	if s.ResponseTimes == nil {
+		s.ResponseTimes = newResponseTimeHistogram()
+	} else {
+		s.ResponseTimes.Reset()
+	}
 	s.MinResponseTime = 0
 	s.MaxResponseTime = 0
 	s.LastRequestTimestamp = time.Duration(time.Now().UnixNano()).Milliseconds()
@@ -234,34 +244,39 @@ func (s *statsEntry) logResponseTime(responseTime int64) {
 		s.MaxResponseTime = responseTime
 	}
 
-	var roundedResponseTime int64
-
-	// to avoid too much data that has to be transferred to the master node when
-	// running in distributed mode, we save the response time rounded in a dict
-	// so that 147 becomes 150, 3432 becomes 3400 and 58760 becomes 59000
-	// see also locust's stats.py
-	if responseTime < 100 {
-		roundedResponseTime = responseTime
-	} else if responseTime < 1000 {
-		roundedResponseTime = int64(round(float64(responseTime), .5, -1))
-	} else if responseTime < 10000 {
-		roundedResponseTime = int64(round(float64(responseTime), .5, -2))
-	} else {
-		roundedResponseTime = int64(round(float64(responseTime), .5, -3))
-	}
-
-	_, ok := s.ResponseTimes[roundedResponseTime]
-	if !ok {
-		s.ResponseTimes[roundedResponseTime] = 1
-	} else {
-		s.ResponseTimes[roundedResponseTime]++
-	}
+	s.ResponseTimes.record(responseTime)
 }
 
 func (s *statsEntry) logFailures() {
 	s.NumFailures++
 }
 
+// extend merges stats of another entry with the same name and method, e.g. stats reported by workers.
+func (s *statsEntry) extend(other *statsEntry) {
+	if other.NumRequests > 0 && (s.MinResponseTime == 0 || other.MinResponseTime < s.MinResponseTime) {
+		s.MinResponseTime = other.MinResponseTime
+	}
+	if other.MaxResponseTime > s.MaxResponseTime {
+		s.MaxResponseTime = other.MaxResponseTime
+	}
+	if s.StartTime == 0 || (other.StartTime > 0 && other.StartTime < s.StartTime) {
+		s.StartTime = other.StartTime
+	}
+	if other.LastRequestTimestamp > s.LastRequestTimestamp {
+		s.LastRequestTimestamp = other.LastRequestTimestamp
+	}
+	s.NumRequests += other.NumRequests
+	s.NumFailures += other.NumFailures
+	s.NumNoneRequests += other.NumNoneRequests
+	s.TotalResponseTime += other.TotalResponseTime
+	s.TotalContentLength += other.TotalContentLength
+	if s.ResponseTimes == nil {
+		s.ResponseTimes = newResponseTimeHistogram()
+	}
+	s.ResponseTimes.merge(other.ResponseTimes)
+}
+
+// serialize converts stats entry to map, response time percentiles are added for outputs.
 func (s *statsEntry) serialize() map[string]interface{} {
 	var result map[string]interface{}
 	val, err := json.Marshal(s)
@@ -272,6 +287,7 @@ func (s *statsEntry) serialize() map[string]interface{} {
 	if err != nil {
 		return nil
 	}
+	result["response_time_percentiles"] = s.ResponseTimes.percentiles()
 	return result
 }
 
@@ -300,3 +316,141 @@ func (err *statsError) toMap() map[string]interface{} {
 	m["occurrences"] = err.occurrences
 	return m
 }
+
+// maxTrackableResponseTime is the max response time recorded in histogram, larger ones are recorded as it.
+const maxTrackableResponseTime = int64(time.Hour / time.Millisecond)
+
+// responseTimePercentiles are reported for each stats entry, latency SLOs are usually written at p99.
+var responseTimePercentiles = []float64{50, 90, 95, 99, 99.9}
+
+// responseTimeHistogram records response times in milliseconds with 3 significant digits,
+// e.g. 147 and 3432 are recorded as is, while 58760 is recorded as 58752 within 0.1% error.
+type responseTimeHistogram struct {
+	*hdrhistogram.Histogram
+}
+
+func newResponseTimeHistogram() *responseTimeHistogram {
+	return &responseTimeHistogram{
+		Histogram: hdrhistogram.New(1, maxTrackableResponseTime, 3),
+	}
+}
+
+func (h *responseTimeHistogram) record(responseTime int64) {
+	if responseTime < 0 {
+		responseTime = 0
+	} else if responseTime > maxTrackableResponseTime {
+		responseTime = maxTrackableResponseTime
+	}
+	_ = h.RecordValue(responseTime)
+}
+
+func (h *responseTimeHistogram) merge(other *responseTimeHistogram) {
+	if other == nil || other.Histogram == nil {
+		return
+	}
+	h.Merge(other.Histogram)
+}
+
+// percentile returns response time at percentile, e.g. 99.9
+func (h *responseTimeHistogram) percentile(percentile float64) int64 {
+	if h == nil || h.Histogram == nil || h.TotalCount() == 0 {
+		return 0
+	}
+	return h.ValueAtPercentile(percentile)
+}
+
+// percentiles returns response times at reported percentiles, e.g. {"p50": 120, "p99.9": 980}
+func (h *responseTimeHistogram) percentiles() map[string]int64 {
+	result := make(map[string]int64, len(responseTimePercentiles))
+	for _, percentile := range responseTimePercentiles {
+		result[percentileName(percentile)] = h.percentile(percentile)
+	}
+	return result
+}
+
+// percentileName returns name of percentile, e.g. p50, p99.9
+func percentileName(percentile float64) string {
+	return "p" + strconv.FormatFloat(percentile, 'f', -1, 64)
+}
+
+// MarshalJSON encodes histogram as base64 encoded compressed histogram.
+func (h *responseTimeHistogram) MarshalJSON() ([]byte, error) {
+	encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(encoded))
+}
+
+// UnmarshalJSON decodes histogram from base64 encoded compressed histogram.
+func (h *responseTimeHistogram) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	if encoded == "" {
+		h.Histogram = newResponseTimeHistogram().Histogram
+		return nil
+	}
+	histogram, err := hdrhistogram.Decode([]byte(encoded))
+	if err != nil {
+		return err
+	}
+	h.Histogram = histogram
+	return nil
+}
+
+// workerStats is stats data reported by worker in each report interval.
+type workerStats struct {
+	Stats        []*statsEntry                `json:"stats"`
+	StatsTotal   *statsEntry                  `json:"stats_total"`
+	Errors       map[string]*workerStatsError `json:"errors"`
+	Transactions map[string]int64             `json:"transactions"`
+}
+
+type workerStatsError struct {
+	Method      string `json:"method"`
+	Name        string `json:"name"`
+	Error       string `json:"error"`
+	Occurrences int64  `json:"occurrences"`
+}
+
+// mergeWorkerStats merges stats reported by worker into master stats. Stats entries and errors are
+// collected in each report interval, while total stats and transactions are accumulated by each worker,
+// thus total of master is merged from the latest total of all workers.
+func (s *requestStats) mergeWorkerStats(nodeID string, ws *workerStats) {
+	for _, entry := range ws.Stats {
+		s.get(entry.Name, entry.Method).extend(entry)
+	}
+	for key, e := range ws.Errors {
+		entry, ok := s.errors[key]
+		if !ok {
+			entry = &statsError{
+				name:   e.Name,
+				method: e.Method,
+				errMsg: e.Error,
+			}
+			s.errors[key] = entry
+		}
+		entry.occurrences += e.Occurrences
+	}
+
+	if s.workerStats == nil {
+		s.workerStats = make(map[string]*workerStats)
+	}
+	s.workerStats[nodeID] = ws
+	total := &statsEntry{
+		Name:          "Total",
+		Method:        "",
+		ResponseTimes: newResponseTimeHistogram(),
+	}
+	s.transactionPassed, s.transactionFailed = 0, 0
+	for _, w := range s.workerStats {
+		if w.StatsTotal != nil {
+			total.extend(w.StatsTotal)
+		}
+		s.transactionPassed += w.Transactions["passed"]
+		s.transactionFailed += w.Transactions["failed"]
+	}
+	s.total = total
+}
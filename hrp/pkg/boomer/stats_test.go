@@ -2,6 +2,8 @@ package boomer
 
 import (
 	"testing"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
 )
 
 func TestLogRequest(t *testing.T) {
@@ -53,28 +55,123 @@ func BenchmarkLogRequest(b *testing.B) {
 	}
 }
 
-func TestRoundedResponseTime(t *testing.T) {
+func TestResponseTimePercentiles(t *testing.T) {
 	newStats := newRequestStats()
 	newStats.logRequest("http", "success", 147, 1)
 	newStats.logRequest("http", "success", 3432, 1)
 	newStats.logRequest("http", "success", 58760, 1)
 	entry := newStats.get("success", "http")
-	responseTimes := entry.ResponseTimes
 
-	if len(responseTimes) != 3 {
-		t.Error("len(responseTimes) is wrong, expected: 3, got:", len(responseTimes))
+	percentiles := entry.ResponseTimes.percentiles()
+	if len(percentiles) != 5 {
+		t.Error("len(percentiles) is wrong, expected: 5, got:", len(percentiles))
+	}
+	// response times are recorded within 0.1% error
+	if p50 := percentiles["p50"]; p50 < 3429 || p50 > 3435 {
+		t.Error("p50 is wrong, expected close to 3432, got:", p50)
+	}
+	if p99 := percentiles["p99.9"]; p99 < 58700 || p99 > 58800 {
+		t.Error("p99.9 is wrong, expected close to 58760, got:", p99)
+	}
+
+	for i := 1; i <= 1000; i++ {
+		newStats.logRequest("http", "percentile", int64(i), 1)
+	}
+	entry = newStats.get("percentile", "http")
+	expected := map[string]int64{"p50": 500, "p90": 900, "p95": 950, "p99": 990, "p99.9": 999}
+	percentiles = entry.ResponseTimes.percentiles()
+	for name, value := range expected {
+		if percentiles[name] != value {
+			t.Errorf("%s is wrong, expected: %d, got: %d", name, value, percentiles[name])
+		}
+	}
+
+	// response time larger than max trackable is recorded as max trackable
+	newStats.logRequest("http", "timeout", 2*maxTrackableResponseTime, 1)
+	entry = newStats.get("timeout", "http")
+	if p50 := entry.ResponseTimes.percentile(50); p50 < maxTrackableResponseTime*999/1000 {
+		t.Error("p50 is wrong, expected close to max trackable response time, got:", p50)
 	}
+}
 
-	if val, ok := responseTimes[150]; !ok || val != 1 {
-		t.Error("Rounded response time should be", 150)
+func TestStatsEntryMarshalJSON(t *testing.T) {
+	newStats := newRequestStats()
+	for i := 1; i <= 100; i++ {
+		newStats.logRequest("http", "success", int64(i), 1)
+	}
+	entry := newStats.get("success", "http")
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	if val, ok := responseTimes[3400]; !ok || val != 1 {
-		t.Error("Rounded response time should be", 3400)
+	decoded := &statsEntry{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.NumRequests != 100 || decoded.ResponseTimes.TotalCount() != 100 {
+		t.Error("decoded stats entry is wrong, got:", decoded.NumRequests, decoded.ResponseTimes.TotalCount())
+	}
+	if decoded.ResponseTimes.percentile(99) != 99 {
+		t.Error("decoded p99 is wrong, expected: 99, got:", decoded.ResponseTimes.percentile(99))
 	}
 
-	if val, ok := responseTimes[59000]; !ok || val != 1 {
-		t.Error("Rounded response time should be", 59000)
+	// empty histogram
+	empty := &statsEntry{}
+	if err := json.Unmarshal([]byte(`{"response_times": ""}`), empty); err != nil {
+		t.Fatal(err)
+	}
+	if empty.ResponseTimes.percentile(50) != 0 {
+		t.Error("p50 of empty histogram should be 0")
+	}
+}
+
+func TestMergeWorkerStats(t *testing.T) {
+	newWorkerData := func(responseTimes ...int64) *workerStats {
+		stats := newRequestStats()
+		for _, responseTime := range responseTimes {
+			stats.logRequest("http", "success", responseTime, 10)
+		}
+		stats.logError("http", "success", "500 error")
+		stats.logTransaction("tx", true, 1, 1)
+		data, err := json.Marshal(stats.collectReportData())
+		if err != nil {
+			t.Fatal(err)
+		}
+		ws := &workerStats{}
+		if err := json.Unmarshal(data, ws); err != nil {
+			t.Fatal(err)
+		}
+		return ws
+	}
+
+	masterStats := newRequestStats()
+	masterStats.mergeWorkerStats("worker1", newWorkerData(10, 20, 30))
+	masterStats.mergeWorkerStats("worker2", newWorkerData(40, 1000))
+	entry := masterStats.get("success", "http")
+	if entry.NumRequests != 5 || entry.NumFailures != 2 {
+		t.Error("merged numRequests or numFailures is wrong, got:", entry.NumRequests, entry.NumFailures)
+	}
+	if entry.MinResponseTime != 10 || entry.MaxResponseTime != 1000 {
+		t.Error("merged min or max response time is wrong, got:", entry.MinResponseTime, entry.MaxResponseTime)
+	}
+	if p50 := entry.ResponseTimes.percentile(50); p50 != 30 {
+		t.Error("merged p50 is wrong, expected: 30, got:", p50)
+	}
+	if masterStats.errors[genMD5("http", "success", "500 error")].occurrences != 2 {
+		t.Error("merged error occurrences is wrong")
+	}
+
+	// total is merged from the latest report of each worker
+	masterStats.mergeWorkerStats("worker1", newWorkerData(10, 20, 30, 40))
+	if masterStats.total.NumRequests != 6 {
+		t.Error("merged total numRequests is wrong, expected: 6, got:", masterStats.total.NumRequests)
+	}
+	if masterStats.transactionPassed != 2 {
+		t.Error("merged transactionPassed is wrong, expected: 2, got:", masterStats.transactionPassed)
+	}
+	if p99 := masterStats.total.ResponseTimes.percentile(99); p99 != 1000 {
+		t.Error("merged total p99 is wrong, expected: 1000, got:", p99)
 	}
 }
 
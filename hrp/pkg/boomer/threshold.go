@@ -0,0 +1,283 @@
+package boomer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/code"
+)
+
+// Threshold defines pass/fail criteria of load testing on stats of request or transaction name,
+// e.g. {"name": "login", "conditions": ["p95 < 300ms", "fail_ratio < 0.1%", "rps > 500"]}
+type Threshold struct {
+	Name           string   `json:"name,omitempty" yaml:"name,omitempty" mapstructure:"name,omitempty"`                                     // request or transaction name, empty for stats in total
+	Conditions     []string `json:"conditions" yaml:"conditions" mapstructure:"conditions"`                                                 // e.g. p95 < 300ms, fail_ratio < 0.1%, rps > 500
+	AbortOnBreach  bool     `json:"abort-on-breach,omitempty" yaml:"abort-on-breach,omitempty" mapstructure:"abort-on-breach,omitempty"`    // stop load testing once conditions are breached
+	DelayAbortEval int64    `json:"delay-abort-eval,omitempty" yaml:"delay-abort-eval,omitempty" mapstructure:"delay-abort-eval,omitempty"` // seconds to wait before aborting, e.g. warming up
+}
+
+// ThresholdResult is the result of threshold condition checked against accumulated stats.
+type ThresholdResult struct {
+	Name      string  `json:"name"`
+	Condition string  `json:"condition"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+	Aborted   bool    `json:"aborted,omitempty"` // load testing is aborted by this condition
+}
+
+// thresholdMetricUnits are units supported by metrics, response times are in milliseconds by default.
+var thresholdMetricUnits = map[string]map[string]float64{
+	"avg":        {"": 1, "ms": 1, "s": 1000},
+	"min":        {"": 1, "ms": 1, "s": 1000},
+	"max":        {"": 1, "ms": 1, "s": 1000},
+	"med":        {"": 1, "ms": 1, "s": 1000},
+	"percentile": {"": 1, "ms": 1, "s": 1000},
+	"rps":        {"": 1},
+	"fail_ratio": {"": 1, "%": 0.01},
+	"requests":   {"": 1},
+	"fails":      {"": 1},
+}
+
+var thresholdConditionRegexp = regexp.MustCompile(`^\s*([a-z_]+|p\d+(?:\.\d+)?)\s*(<=|>=|==|!=|<|>)\s*(\d+(?:\.\d+)?)\s*(ms|s|%)?\s*$`)
+
+type thresholdCondition struct {
+	name           string
+	expression     string
+	metric         string
+	percentile     float64
+	operator       string
+	value          float64
+	abortOnBreach  bool
+	delayAbortEval time.Duration
+	aborted        bool
+}
+
+func parseThresholdCondition(threshold *Threshold, expression string) (*thresholdCondition, error) {
+	matches := thresholdConditionRegexp.FindStringSubmatch(expression)
+	if matches == nil {
+		return nil, errors.Errorf("invalid threshold condition %q, expected format: <metric> <operator> <value>", expression)
+	}
+	condition := &thresholdCondition{
+		name:           threshold.Name,
+		expression:     strings.TrimSpace(expression),
+		metric:         matches[1],
+		operator:       matches[2],
+		abortOnBreach:  threshold.AbortOnBreach,
+		delayAbortEval: time.Duration(threshold.DelayAbortEval) * time.Second,
+	}
+	if strings.HasPrefix(condition.metric, "p") && condition.metric != "percentile" {
+		percentile, err := strconv.ParseFloat(condition.metric[1:], 64)
+		if err == nil {
+			if percentile <= 0 || percentile > 100 {
+				return nil, errors.Errorf("invalid percentile %q in threshold condition %q", condition.metric, expression)
+			}
+			condition.metric = "percentile"
+			condition.percentile = percentile
+		}
+	}
+	units, ok := thresholdMetricUnits[condition.metric]
+	if !ok || condition.metric == "percentile" && condition.percentile == 0 {
+		return nil, errors.Errorf("unsupported metric %q in threshold condition %q", matches[1], expression)
+	}
+	unit, ok := units[matches[4]]
+	if !ok {
+		return nil, errors.Errorf("unsupported unit %q of metric %q in threshold condition %q", matches[4], matches[1], expression)
+	}
+	value, _ := strconv.ParseFloat(matches[3], 64)
+	condition.value = value * unit
+	return condition, nil
+}
+
+// actual returns value of metric, duration of stats is in seconds.
+func (c *thresholdCondition) actual(entry *statsEntry, duration float64) float64 {
+	switch c.metric {
+	case "avg":
+		return getAvgResponseTime(entry.NumRequests, entry.TotalResponseTime)
+	case "min":
+		return float64(entry.MinResponseTime)
+	case "max":
+		return float64(entry.MaxResponseTime)
+	case "med":
+		return float64(entry.ResponseTimes.percentile(50))
+	case "percentile":
+		return float64(entry.ResponseTimes.percentile(c.percentile))
+	case "rps":
+		if duration <= 0 {
+			return 0
+		}
+		return getCurrentRps(entry.NumRequests, duration)
+	case "fail_ratio":
+		return getTotalFailRatio(entry.NumRequests, entry.NumFailures)
+	case "requests":
+		return float64(entry.NumRequests)
+	case "fails":
+		return float64(entry.NumFailures)
+	}
+	return 0
+}
+
+func (c *thresholdCondition) passed(actual float64) bool {
+	switch c.operator {
+	case "<":
+		return actual < c.value
+	case "<=":
+		return actual <= c.value
+	case ">":
+		return actual > c.value
+	case ">=":
+		return actual >= c.value
+	case "==":
+		return actual == c.value
+	case "!=":
+		return actual != c.value
+	}
+	return false
+}
+
+// thresholdChecker checks threshold conditions against stats accumulated since load testing started,
+// stats of the same name are accumulated together, e.g. request and transaction.
+type thresholdChecker struct {
+	conditions []*thresholdCondition
+	entries    map[string]*statsEntry
+	results    []*ThresholdResult
+	startTime  time.Time
+}
+
+func newThresholdChecker(thresholds []*Threshold) (*thresholdChecker, error) {
+	checker := &thresholdChecker{}
+	for _, threshold := range thresholds {
+		if threshold == nil {
+			continue
+		}
+		if len(threshold.Conditions) == 0 {
+			return nil, errors.Errorf("threshold %q has no conditions", threshold.Name)
+		}
+		for _, expression := range threshold.Conditions {
+			condition, err := parseThresholdCondition(threshold, expression)
+			if err != nil {
+				return nil, err
+			}
+			checker.conditions = append(checker.conditions, condition)
+		}
+	}
+	checker.reset()
+	return checker, nil
+}
+
+func (c *thresholdChecker) reset() {
+	c.entries = make(map[string]*statsEntry)
+	c.results = nil
+	c.startTime = time.Now()
+	for _, condition := range c.conditions {
+		condition.aborted = false
+	}
+}
+
+// accumulate merges stats entries of report interval before they are reset.
+func (c *thresholdChecker) accumulate(entries map[string]*statsEntry) {
+	for _, entry := range entries {
+		if entry.NumRequests == 0 && entry.NumFailures == 0 {
+			continue
+		}
+		accumulated, ok := c.entries[entry.Name]
+		if !ok {
+			accumulated = &statsEntry{
+				Name:          entry.Name,
+				Method:        entry.Method,
+				ResponseTimes: newResponseTimeHistogram(),
+			}
+			c.entries[entry.Name] = accumulated
+		}
+		accumulated.extend(entry)
+	}
+}
+
+// check checks all conditions against accumulated stats and total stats,
+// returns true if load testing should be aborted.
+func (c *thresholdChecker) check(total *statsEntry) (abort bool) {
+	duration := float64(total.LastRequestTimestamp-total.StartTime) / 1e3
+	results := make([]*ThresholdResult, 0, len(c.conditions))
+	for _, condition := range c.conditions {
+		result := &ThresholdResult{
+			Name:      condition.name,
+			Condition: condition.expression,
+		}
+		results = append(results, result)
+
+		entry := total
+		if condition.name != "" {
+			var ok bool
+			// thresholds of names without any stats are breached, e.g. typo of name
+			if entry, ok = c.entries[condition.name]; !ok {
+				continue
+			}
+		}
+		result.Actual = condition.actual(entry, duration)
+		result.Passed = condition.passed(result.Actual)
+		if !result.Passed && condition.abortOnBreach && !condition.aborted &&
+			time.Since(c.startTime) >= condition.delayAbortEval {
+			condition.aborted = true
+			abort = true
+		}
+		result.Aborted = condition.aborted
+	}
+	c.results = results
+	return abort
+}
+
+func (c *thresholdChecker) getResults() []*ThresholdResult {
+	return c.results
+}
+
+// err returns error with breached conditions if any threshold is breached.
+func (c *thresholdChecker) err() error {
+	var breached []string
+	for _, result := range c.results {
+		if !result.Passed {
+			breached = append(breached, fmt.Sprintf("%s: %s", thresholdName(result.Name), result.Condition))
+		}
+	}
+	if len(breached) == 0 {
+		return nil
+	}
+	return errors.Wrap(code.ThresholdsBreached, strings.Join(breached, "; "))
+}
+
+func (c *thresholdChecker) printResults() {
+	if len(c.results) == 0 {
+		return
+	}
+	println(fmt.Sprint("============================================== Thresholds ==============================================="))
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Threshold", "Actual", "Result"})
+	for _, result := range c.results {
+		state := "pass"
+		if result.Aborted {
+			state = "fail (aborted)"
+		} else if !result.Passed {
+			state = "fail"
+		}
+		table.Append([]string{
+			thresholdName(result.Name),
+			result.Condition,
+			strconv.FormatFloat(result.Actual, 'f', 2, 64),
+			state,
+		})
+	}
+	table.Render()
+	println()
+}
+
+func thresholdName(name string) string {
+	if name == "" {
+		return "Total"
+	}
+	return name
+}
@@ -0,0 +1,167 @@
+package boomer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/code"
+)
+
+func TestParseThresholdCondition(t *testing.T) {
+	threshold := &Threshold{Name: "login"}
+	testData := []struct {
+		expression string
+		metric     string
+		percentile float64
+		operator   string
+		value      float64
+	}{
+		{"p95 < 300ms", "percentile", 95, "<", 300},
+		{"p99.9<=1.5s", "percentile", 99.9, "<=", 1500},
+		{"avg < 200", "avg", 0, "<", 200},
+		{"fail_ratio < 0.1%", "fail_ratio", 0, "<", 0.001},
+		{"fail_ratio < 0.01", "fail_ratio", 0, "<", 0.01},
+		{"rps > 500", "rps", 0, ">", 500},
+		{" fails == 0 ", "fails", 0, "==", 0},
+	}
+	for _, data := range testData {
+		condition, err := parseThresholdCondition(threshold, data.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if condition.metric != data.metric || condition.percentile != data.percentile ||
+			condition.operator != data.operator || condition.value != data.value {
+			t.Errorf("parse %q failed, got: %+v", data.expression, condition)
+		}
+	}
+
+	invalid := []string{"p95 300ms", "p0 < 10", "p101 < 10", "percentile < 10", "latency < 10", "rps > 500ms", "p95 < 1%"}
+	for _, expression := range invalid {
+		if _, err := parseThresholdCondition(threshold, expression); err == nil {
+			t.Errorf("parse %q should fail", expression)
+		}
+	}
+}
+
+func TestThresholdChecker(t *testing.T) {
+	_, err := newThresholdChecker([]*Threshold{{Name: "login"}})
+	if err == nil {
+		t.Error("threshold without conditions should fail")
+	}
+
+	checker, err := newThresholdChecker([]*Threshold{
+		{Conditions: []string{"p50 < 100ms", "fail_ratio < 20%", "rps > 1"}},
+		{Name: "login", Conditions: []string{"max < 1s", "requests >= 3"}, AbortOnBreach: true},
+		{Name: "logout", Conditions: []string{"fails == 0"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := newRequestStats()
+	stats.logRequest("http", "login", 10, 1)
+	stats.logRequest("transaction", "login", 1200, 1)
+	stats.logRequest("http", "home", 20, 1)
+	stats.logError("http", "home", "500 error")
+	stats.total.StartTime = stats.total.LastRequestTimestamp - 1000
+	checker.accumulate(stats.entries)
+	if abort := checker.check(stats.total); !abort {
+		t.Error("login threshold with abort-on-breach should abort")
+	}
+
+	expected := []struct {
+		name    string
+		actual  float64
+		passed  bool
+		aborted bool
+	}{
+		{"", 20, true, false},
+		{"", 1.0 / 3, false, false},
+		{"", 3, true, false},
+		{"login", 1200, false, true},
+		{"login", 2, false, true},
+		{"logout", 0, false, false}, // no stats of logout
+	}
+	results := checker.getResults()
+	if len(results) != len(expected) {
+		t.Fatal("length of threshold results is wrong, got:", len(results))
+	}
+	for i, e := range expected {
+		r := results[i]
+		if r.Name != e.name || r.Actual != e.actual || r.Passed != e.passed || r.Aborted != e.aborted {
+			t.Errorf("threshold result %d is wrong, expected: %+v, got: %+v", i, e, r)
+		}
+	}
+
+	// stats are accumulated across report intervals, aborted only once
+	stats.get("login", "http").reset()
+	stats.get("login", "transaction").reset()
+	stats.logRequest("http", "login", 30, 1)
+	checker.accumulate(stats.entries)
+	if abort := checker.check(stats.total); abort {
+		t.Error("load testing should be aborted only once")
+	}
+	if results := checker.getResults(); results[4].Actual != 3 || !results[4].Passed {
+		t.Error("accumulated requests of login is wrong, got:", results[4].Actual)
+	}
+
+	err = checker.err()
+	if err == nil || errors.Cause(err) != code.ThresholdsBreached {
+		t.Fatal("error should be caused by ThresholdsBreached, got:", err)
+	}
+	for _, breached := range []string{"Total: fail_ratio < 20%", "login: max < 1s", "logout: fails == 0"} {
+		if !strings.Contains(err.Error(), breached) {
+			t.Errorf("error should contain %q, got: %v", breached, err)
+		}
+	}
+
+	checker.reset()
+	if len(checker.entries) != 0 || checker.getResults() != nil || checker.err() != nil {
+		t.Error("threshold checker should be reset")
+	}
+}
+
+func TestLocalRunnerAbortOnThresholdBreach(t *testing.T) {
+	runner := newLocalRunner(2, 2)
+	checker, err := newThresholdChecker([]*Threshold{
+		{Conditions: []string{"fail_ratio < 1%"}, AbortOnBreach: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.thresholds = checker
+	runner.setTasks([]*Task{
+		{
+			Weight: 10,
+			Fn: func() {
+				runner.stats.requestFailureChan <- &requestFailure{
+					requestType:  "http",
+					name:         "failure",
+					responseTime: 1,
+					errMsg:       "500 error",
+				}
+				time.Sleep(100 * time.Millisecond)
+			},
+			Name: "TaskA",
+		},
+	})
+
+	done := make(chan bool)
+	go func() {
+		runner.start()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		runner.stop()
+		t.Fatal("runner should be aborted when threshold is breached")
+	}
+	results := runner.thresholds.getResults()
+	if len(results) != 1 || results[0].Passed || !results[0].Aborted {
+		t.Errorf("threshold should be breached and aborted, got: %+v", results)
+	}
+}
@@ -1 +1,677 @@
 package convert
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/httprunner/httprunner/v4/hrp"
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+)
+
+// ==================== model definition starts here ====================
+
+/*
+JMeter test plan (.jmx) format
+https://jmeter.apache.org/usermanual/component_reference.html
+
+test elements are organized in hashTree, each element is followed by a hashTree containing its children:
+
+	<hashTree>
+	  <ThreadGroup testname="users">...</ThreadGroup>
+	  <hashTree>
+	    <HTTPSamplerProxy testname="login">...</HTTPSamplerProxy>
+	    <hashTree>...</hashTree>
+	  </hashTree>
+	</hashTree>
+*/
+
+// CaseJMeter represents the JMeter test plan file
+type CaseJMeter struct {
+	XMLName  xml.Name `xml:"jmeterTestPlan"`
+	HashTree *JMXNode `xml:"hashTree"`
+	path     string   // test plan file path, used to locate CSV data files
+	config   *hrp.TConfig
+}
+
+// JMXNode is a generic element in JMeter test plan, e.g. test element, property or hashTree
+type JMXNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Content  string     `xml:",chardata"`
+	Children []*JMXNode `xml:",any"`
+}
+
+// ==================== model definition ends here ====================
+
+// JMeter assertion test types, combined with bitwise or
+const (
+	jmxAssertMatches   = 1
+	jmxAssertContains  = 2
+	jmxAssertNot       = 4
+	jmxAssertEquals    = 8
+	jmxAssertSubstring = 16
+)
+
+func LoadJMeterCase(path string) (*hrp.TCase, error) {
+	log.Info().Str("path", path).Msg("load jmeter case file")
+	caseJMeter, err := loadCaseJMeter(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// convert to TCase format
+	return caseJMeter.ToTCase()
+}
+
+func loadCaseJMeter(path string) (*CaseJMeter, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read jmeter file failed")
+	}
+	caseJMeter := &CaseJMeter{path: path}
+	if err := xml.Unmarshal(content, caseJMeter); err != nil {
+		return nil, errors.Wrap(err, "load jmeter file failed")
+	}
+	if caseJMeter.HashTree == nil {
+		return nil, errors.New("invalid jmeter case file, missing hashTree")
+	}
+	return caseJMeter, nil
+}
+
+func (c *CaseJMeter) ToTCase() (*hrp.TCase, error) {
+	c.config = hrp.NewConfig("testcase description").
+		SetVerifySSL(false)
+	steps, err := c.prepareTestSteps(c.HashTree, &jmxScope{top: true})
+	if err != nil {
+		return nil, err
+	}
+	tCase := &hrp.TCase{
+		Config:    c.config,
+		TestSteps: steps,
+	}
+	err = tCase.MakeCompat()
+	if err != nil {
+		return nil, err
+	}
+	return tCase, nil
+}
+
+// jmxScope holds elements applied to all samplers in scope, e.g. timers and headers
+type jmxScope struct {
+	top     bool              // test plan or thread group level
+	headers map[string]string // headers of HeaderManager in nested controllers
+	timers  []*JMXNode        // ConstantTimer and SyncTimer applied before each sampler
+}
+
+func (s *jmxScope) child() *jmxScope {
+	child := &jmxScope{
+		headers: make(map[string]string),
+		timers:  append([]*JMXNode{}, s.timers...),
+	}
+	for k, v := range s.headers {
+		child.headers[k] = v
+	}
+	return child
+}
+
+// prepareTestSteps converts elements in hashTree to teststeps recursively
+func (c *CaseJMeter) prepareTestSteps(tree *JMXNode, parent *jmxScope) ([]*hrp.TStep, error) {
+	elements := tree.elements()
+
+	// config elements and timers apply to all samplers in scope
+	scope := parent.child()
+	scope.top = parent.top
+	for _, e := range elements {
+		switch e.node.XMLName.Local {
+		case "HeaderManager":
+			headers := e.node.headers()
+			if scope.top {
+				if c.config.Headers == nil {
+					c.config.Headers = make(map[string]string)
+				}
+				for k, v := range headers {
+					c.config.Headers[k] = v
+				}
+			} else {
+				for k, v := range headers {
+					scope.headers[k] = v
+				}
+			}
+		case "ConfigTestElement":
+			// HTTP Request Defaults
+			if baseURL := e.node.httpURL(""); baseURL != "" && c.config.BaseURL == "" {
+				c.config.BaseURL = baseURL
+			}
+		case "Arguments":
+			// User Defined Variables
+			for k, v := range e.node.arguments("Arguments.arguments") {
+				c.config.Variables[k] = v
+			}
+		case "CSVDataSet":
+			if err := c.makeParameters(e.node); err != nil {
+				return nil, err
+			}
+		case "ConstantTimer", "SyncTimer":
+			scope.timers = append(scope.timers, e.node)
+		}
+	}
+
+	var steps []*hrp.TStep
+	for _, e := range elements {
+		switch e.node.XMLName.Local {
+		case "TestPlan":
+			c.config.Name = e.node.attr("testname")
+			for k, v := range e.node.elementProp("TestPlan.user_defined_variables").arguments("Arguments.arguments") {
+				c.config.Variables[k] = v
+			}
+			subSteps, err := c.prepareTestSteps(e.tree, scope)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, subSteps...)
+		case "HTTPSamplerProxy":
+			subSteps, err := c.prepareRequestSteps(e.node, e.tree, scope)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, subSteps...)
+		case "TransactionController":
+			name := e.node.attr("testname")
+			subScope := scope.child()
+			subSteps, err := c.prepareTestSteps(e.tree, subScope)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, &hrp.TStep{
+				Name:        "transaction " + name + " start",
+				Transaction: &hrp.Transaction{Name: name, Type: "start"},
+			})
+			steps = append(steps, subSteps...)
+			steps = append(steps, &hrp.TStep{
+				Name:        "transaction " + name + " end",
+				Transaction: &hrp.Transaction{Name: name, Type: "end"},
+			})
+		case "HeaderManager", "ConfigTestElement", "Arguments", "CSVDataSet", "ConstantTimer", "SyncTimer":
+			// handled above
+		default:
+			if e.tree == nil || len(e.tree.Children) == 0 {
+				log.Warn().Str("element", e.node.XMLName.Local).
+					Str("name", e.node.attr("testname")).
+					Msg("unsupported jmeter element, ignore")
+				continue
+			}
+			// thread groups and other controllers, e.g. LoopController, are flattened
+			if !isJMXThreadGroup(e.node.XMLName.Local) {
+				log.Warn().Str("element", e.node.XMLName.Local).
+					Str("name", e.node.attr("testname")).
+					Msg("jmeter controller is flattened, logic is ignored")
+			}
+			subScope := scope.child()
+			subScope.top = isJMXThreadGroup(e.node.XMLName.Local)
+			subSteps, err := c.prepareTestSteps(e.tree, subScope)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, subSteps...)
+		}
+	}
+	return steps, nil
+}
+
+// prepareRequestSteps converts HTTP sampler to request step, timers in scope are inserted before request step
+func (c *CaseJMeter) prepareRequestSteps(sampler, tree *JMXNode, scope *jmxScope) ([]*hrp.TStep, error) {
+	log.Info().
+		Str("method", sampler.prop("HTTPSampler.method")).
+		Str("path", sampler.prop("HTTPSampler.path")).
+		Msg("convert teststep")
+
+	step := &stepFromJMeter{
+		TStep: hrp.TStep{
+			Name:       sampler.attr("testname"),
+			Request:    &hrp.Request{},
+			Validators: make([]interface{}, 0),
+		},
+	}
+	if len(scope.headers) > 0 {
+		step.Request.Headers = make(map[string]string)
+		for k, v := range scope.headers {
+			step.Request.Headers[k] = v
+		}
+	}
+
+	var steps []*hrp.TStep
+	for _, timer := range scope.timers {
+		steps = append(steps, timer.timerStep())
+	}
+	for _, e := range tree.elements() {
+		switch e.node.XMLName.Local {
+		case "HeaderManager":
+			if step.Request.Headers == nil {
+				step.Request.Headers = make(map[string]string)
+			}
+			for k, v := range e.node.headers() {
+				step.Request.Headers[k] = v
+			}
+		case "ConstantTimer", "SyncTimer":
+			steps = append(steps, e.node.timerStep())
+		case "ResponseAssertion":
+			step.makeResponseAssertion(e.node)
+		case "JSONPathAssertion":
+			step.makeJSONPathAssertion(e.node)
+		case "JSONPostProcessor":
+			step.makeJSONExtractor(e.node)
+		default:
+			log.Warn().Str("element", e.node.XMLName.Local).
+				Str("name", e.node.attr("testname")).
+				Msg("unsupported jmeter element in sampler, ignore")
+		}
+	}
+
+	if err := step.makeRequest(sampler); err != nil {
+		return nil, err
+	}
+	return append(steps, &step.TStep), nil
+}
+
+// makeParameters converts CSV Data Set Config to parameters, CSV data is loaded inline
+func (c *CaseJMeter) makeParameters(node *JMXNode) error {
+	filename := node.prop("filename")
+	variableNames := node.prop("variableNames")
+	delimiter := node.prop("delimiter")
+	if delimiter == "" {
+		delimiter = ","
+	}
+	if variableNames != "" {
+		variableNames = strings.ReplaceAll(variableNames, delimiter, ",")
+	}
+
+	csvPath := filename
+	if !filepath.IsAbs(csvPath) {
+		csvPath = filepath.Join(filepath.Dir(c.path), filename)
+	}
+	file, err := os.Open(csvPath)
+	if err != nil {
+		if variableNames == "" {
+			return errors.Wrapf(err, "open jmeter csv data file %s failed", csvPath)
+		}
+		// fall back to parameterize function, CSV file should contain header line
+		log.Warn().Err(err).Str("path", csvPath).Msg("load csv data file failed, use parameterize instead")
+		c.setParameters(strings.Split(variableNames, ","), fmt.Sprintf("${parameterize(%s)}", filename))
+		return nil
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = []rune(delimiter)[0]
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return errors.Wrapf(err, "read jmeter csv data file %s failed", csvPath)
+	}
+
+	var names []string
+	if variableNames == "" {
+		// variable names are read from the first line
+		if len(records) == 0 {
+			return errors.Errorf("jmeter csv data file %s is empty", csvPath)
+		}
+		names, records = records[0], records[1:]
+	} else {
+		names = strings.Split(variableNames, ",")
+		if node.prop("ignoreFirstLine") == "true" && len(records) > 0 {
+			records = records[1:]
+		}
+	}
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+
+	var values []interface{}
+	for _, record := range records {
+		if len(record) < len(names) {
+			continue
+		}
+		line := make([]interface{}, len(names))
+		for i := range names {
+			line[i] = record[i]
+		}
+		if len(names) == 1 {
+			values = append(values, line[0])
+		} else {
+			values = append(values, line)
+		}
+	}
+	c.setParameters(names, values)
+	return nil
+}
+
+func (c *CaseJMeter) setParameters(names []string, value interface{}) {
+	if c.config.Parameters == nil {
+		c.config.Parameters = make(map[string]interface{})
+	}
+	c.config.Parameters[strings.Join(names, "-")] = value
+}
+
+type stepFromJMeter struct {
+	hrp.TStep
+}
+
+func (s *stepFromJMeter) makeRequest(sampler *JMXNode) error {
+	method := strings.ToUpper(sampler.prop("HTTPSampler.method"))
+	if method == "" {
+		method = "GET"
+	}
+	s.Request.Method = hrp.HTTPMethod(method)
+	s.Request.URL = sampler.httpURL(sampler.prop("HTTPSampler.path"))
+
+	arguments := sampler.elementProp("HTTPsampler.Arguments").child("collectionProp", "Arguments.arguments")
+	if sampler.prop("HTTPSampler.postBodyRaw") == "true" {
+		var body string
+		for _, arg := range arguments.Children {
+			body += arg.prop("Argument.value")
+		}
+		return s.makeRequestBodyRaw(body)
+	}
+
+	args := make(map[string]interface{})
+	for _, arg := range arguments.Children {
+		name := arg.prop("Argument.name")
+		if name == "" {
+			continue
+		}
+		args[name] = arg.prop("Argument.value")
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	switch {
+	case method == "GET" || method == "DELETE" || method == "HEAD" || method == "OPTIONS":
+		s.Request.Params = args
+	case sampler.prop("HTTPSampler.DO_MULTIPART_POST") == "true":
+		s.Request.Upload = args
+	default:
+		s.setHeaderIfAbsent("Content-Type", "application/x-www-form-urlencoded")
+		s.Request.Body = args
+	}
+	return nil
+}
+
+func (s *stepFromJMeter) makeRequestBodyRaw(body string) error {
+	var data interface{}
+	if err := json.Unmarshal([]byte(body), &data); err == nil {
+		s.setHeaderIfAbsent("Content-Type", "application/json")
+		s.Request.Body = data
+		return nil
+	}
+	s.Request.Body = body
+	return nil
+}
+
+func (s *stepFromJMeter) setHeaderIfAbsent(key, value string) {
+	if s.Request.Headers == nil {
+		s.Request.Headers = make(map[string]string)
+	}
+	for k := range s.Request.Headers {
+		if strings.EqualFold(k, key) {
+			return
+		}
+	}
+	s.Request.Headers[key] = value
+}
+
+// makeResponseAssertion converts Response Assertion on response code or response data to validators
+func (s *stepFromJMeter) makeResponseAssertion(node *JMXNode) {
+	testType, _ := strconv.Atoi(node.prop("Assertion.test_type"))
+	if testType&jmxAssertNot != 0 {
+		log.Warn().Str("name", node.attr("testname")).Msg("negated jmeter response assertion is not supported, ignore")
+		return
+	}
+	field := node.prop("Assertion.test_field")
+	for _, item := range node.child("collectionProp", "Asserion.test_strings").Children {
+		expect := item.Content
+		switch field {
+		case "Assertion.response_code":
+			statusCode, err := strconv.Atoi(strings.TrimSpace(expect))
+			if err != nil {
+				log.Warn().Str("expect", expect).Msg("invalid jmeter response code assertion, ignore")
+				continue
+			}
+			s.Validators = append(s.Validators, hrp.Validator{
+				Check:   "status_code",
+				Assert:  "equals",
+				Expect:  statusCode,
+				Message: "assert response status code",
+			})
+		case "Assertion.response_data":
+			var assert string
+			switch {
+			case testType&jmxAssertEquals != 0:
+				assert = "equals"
+			case testType&jmxAssertMatches != 0:
+				assert = "regex_match"
+			case testType&(jmxAssertContains|jmxAssertSubstring) != 0:
+				assert = "contains"
+			default:
+				continue
+			}
+			s.Validators = append(s.Validators, hrp.Validator{
+				Check:   "body",
+				Assert:  assert,
+				Expect:  expect,
+				Message: "assert response body",
+			})
+		default:
+			log.Warn().Str("field", field).Msg("unsupported jmeter response assertion field, ignore")
+		}
+	}
+}
+
+// makeJSONPathAssertion converts JSON Assertion to validator, e.g. $.data.id => body.data.id
+func (s *stepFromJMeter) makeJSONPathAssertion(node *JMXNode) {
+	check := jsonPathToCheck(node.prop("JSON_PATH"))
+	if node.prop("JSONVALIDATION") != "true" {
+		s.Validators = append(s.Validators, hrp.Validator{
+			Check:   check,
+			Assert:  "not_equal",
+			Expect:  nil,
+			Message: "assert json path exists",
+		})
+		return
+	}
+	var expect interface{} = node.prop("EXPECTED_VALUE")
+	if err := json.Unmarshal([]byte(node.prop("EXPECTED_VALUE")), &expect); err != nil {
+		expect = node.prop("EXPECTED_VALUE")
+	}
+	s.Validators = append(s.Validators, hrp.Validator{
+		Check:   check,
+		Assert:  "equals",
+		Expect:  expect,
+		Message: "assert json path value",
+	})
+}
+
+// makeJSONExtractor converts JSON Extractor to extract variables
+func (s *stepFromJMeter) makeJSONExtractor(node *JMXNode) {
+	names := strings.Split(node.prop("JSONPostProcessor.referenceNames"), ";")
+	exprs := strings.Split(node.prop("JSONPostProcessor.jsonPathExprs"), ";")
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || i >= len(exprs) {
+			continue
+		}
+		if s.Extract == nil {
+			s.Extract = make(map[string]string)
+		}
+		s.Extract[name] = jsonPathToCheck(exprs[i])
+	}
+}
+
+// jsonPathToCheck converts simple JSONPath to jmespath check expression
+func jsonPathToCheck(jsonPath string) string {
+	path := strings.TrimPrefix(strings.TrimSpace(jsonPath), "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return "body"
+	}
+	if strings.HasPrefix(path, "[") {
+		return "body" + path
+	}
+	return "body." + path
+}
+
+// ==================== jmx node helpers ====================
+
+type jmxElement struct {
+	node *JMXNode
+	tree *JMXNode // hashTree containing children of element
+}
+
+// elements returns enabled test elements with their children hashTree
+func (n *JMXNode) elements() []*jmxElement {
+	var elements []*jmxElement
+	if n == nil {
+		return nil
+	}
+	for i, child := range n.Children {
+		if child.XMLName.Local == "hashTree" {
+			continue
+		}
+		e := &jmxElement{node: child}
+		if i+1 < len(n.Children) && n.Children[i+1].XMLName.Local == "hashTree" {
+			e.tree = n.Children[i+1]
+		}
+		if child.attr("enabled") == "false" {
+			continue
+		}
+		elements = append(elements, e)
+	}
+	return elements
+}
+
+func (n *JMXNode) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// child returns direct child with specified tag and name attribute
+func (n *JMXNode) child(tag, name string) *JMXNode {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.Children {
+		if child.XMLName.Local == tag && child.attr("name") == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// prop returns value of stringProp, boolProp, intProp or longProp
+func (n *JMXNode) prop(name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, child := range n.Children {
+		switch child.XMLName.Local {
+		case "stringProp", "boolProp", "intProp", "longProp":
+			if child.attr("name") == name {
+				return strings.TrimSpace(child.Content)
+			}
+		}
+	}
+	return ""
+}
+
+func (n *JMXNode) elementProp(name string) *JMXNode {
+	return n.child("elementProp", name)
+}
+
+// arguments returns name and value of arguments in collectionProp
+func (n *JMXNode) arguments(name string) map[string]interface{} {
+	args := make(map[string]interface{})
+	if collection := n.child("collectionProp", name); collection != nil {
+		for _, arg := range collection.Children {
+			if argName := arg.prop("Argument.name"); argName != "" {
+				args[argName] = arg.prop("Argument.value")
+			}
+		}
+	}
+	return args
+}
+
+// headers returns headers of HeaderManager
+func (n *JMXNode) headers() map[string]string {
+	headers := make(map[string]string)
+	if collection := n.child("collectionProp", "HeaderManager.headers"); collection != nil {
+		for _, header := range collection.Children {
+			if key := header.prop("Header.name"); key != "" {
+				headers[key] = header.prop("Header.value")
+			}
+		}
+	}
+	return headers
+}
+
+// httpURL returns URL of HTTP sampler or HTTP Request Defaults,
+// path is returned as is if domain is not specified, which is joined with base_url
+func (n *JMXNode) httpURL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	domain := n.prop("HTTPSampler.domain")
+	if domain == "" {
+		return path
+	}
+	protocol := n.prop("HTTPSampler.protocol")
+	if protocol == "" {
+		protocol = "http"
+	}
+	host := domain
+	if port := n.prop("HTTPSampler.port"); port != "" {
+		host += ":" + port
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s%s", protocol, host, path)
+}
+
+// timerStep converts ConstantTimer to think time step, and SyncTimer to rendezvous step
+func (n *JMXNode) timerStep() *hrp.TStep {
+	if n.XMLName.Local == "SyncTimer" {
+		rendezvous := &hrp.Rendezvous{Name: n.attr("testname")}
+		// group size 0 means all users
+		if groupSize, _ := strconv.ParseInt(n.prop("groupSize"), 10, 64); groupSize > 0 {
+			rendezvous.Number = groupSize
+		}
+		rendezvous.Timeout, _ = strconv.ParseInt(n.prop("timeoutInMs"), 10, 64)
+		return &hrp.TStep{
+			Name:       rendezvous.Name,
+			Rendezvous: rendezvous,
+		}
+	}
+	delay, _ := strconv.ParseFloat(n.prop("ConstantTimer.delay"), 64)
+	return &hrp.TStep{
+		Name:      n.attr("testname"),
+		ThinkTime: &hrp.ThinkTime{Time: delay / 1000},
+	}
+}
+
+func isJMXThreadGroup(tag string) bool {
+	return strings.HasSuffix(tag, "ThreadGroup")
+}
@@ -0,0 +1,110 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/httprunner/httprunner/v4/hrp"
+)
+
+var jmeterPath = "../../../examples/data/jmeter/demo.jmx"
+
+func TestLoadJMeterCase(t *testing.T) {
+	tCase, err := LoadJMeterCase(jmeterPath)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+
+	// check config
+	config := tCase.Config
+	if !assert.Equal(t, "jmeter demo", config.Name) || !assert.Equal(t, "https://postman-echo.com", config.BaseURL) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{"app_version": "v1"}, config.Variables) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]string{"User-Agent": "HttpRunner"}, config.Headers) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{
+		"username-password": []interface{}{
+			[]interface{}{"test1", "111111"},
+			[]interface{}{"test2", "222222"},
+		},
+	}, config.Parameters) {
+		t.Fatal()
+	}
+
+	// check steps, disabled sampler is ignored
+	steps := tCase.TestSteps
+	if !assert.Len(t, steps, 7) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, &hrp.Transaction{Name: "login", Type: "start"}, steps[0].Transaction) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, &hrp.ThinkTime{Time: 0.5}, steps[1].ThinkTime) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "sync login", steps[2].Rendezvous.Name) ||
+		!assert.Equal(t, int64(5), steps[2].Rendezvous.Number) ||
+		!assert.Equal(t, int64(3000), steps[2].Rendezvous.Timeout) {
+		t.Fatal()
+	}
+
+	// raw JSON body, response code assertion and JSON extractor
+	login := steps[3]
+	if !assert.Equal(t, "post login", login.Name) || !assert.Equal(t, hrp.HTTPMethod("POST"), login.Request.Method) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "/post", login.Request.URL) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{"username": "${username}", "password": "${password}"}, login.Request.Body) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "application/json", login.Request.Headers["Content-Type"]) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, hrp.Validator{
+		Check: "status_code", Assert: "equals", Expect: 200, Message: "assert response status code",
+	}, login.Validators[0]) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]string{"user": "body.json.username"}, login.Extract) {
+		t.Fatal()
+	}
+
+	if !assert.Equal(t, &hrp.Transaction{Name: "login", Type: "end"}, steps[4].Transaction) {
+		t.Fatal()
+	}
+	if !assert.NotNil(t, steps[5].ThinkTime) {
+		t.Fatal()
+	}
+
+	// query params and JSON assertion
+	get := steps[6]
+	if !assert.Equal(t, map[string]interface{}{"foo": "${user}"}, get.Request.Params) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, hrp.Validator{
+		Check: "body.args.foo", Assert: "equals", Expect: "test1", Message: "assert json path value",
+	}, get.Validators[0]) {
+		t.Fatal()
+	}
+}
+
+func TestJSONPathToCheck(t *testing.T) {
+	testData := map[string]string{
+		"$":              "body",
+		"$.data.id":      "body.data.id",
+		"$[0].name":      "body[0].name",
+		"$.items[1].url": "body.items[1].url",
+	}
+	for jsonPath, expected := range testData {
+		if !assert.Equal(t, expected, jsonPathToCheck(jsonPath)) {
+			t.Fatal()
+		}
+	}
+}
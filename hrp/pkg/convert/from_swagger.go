@@ -1,24 +1,486 @@
 package convert
 
 import (
-	"github.com/go-openapi/spec"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 
 	"github.com/httprunner/httprunner/v4/hrp"
-	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/openapi"
 )
 
+// max depth of nested schemas when generating example values, avoid infinite recursion of circular references
+const maxExampleDepth = 8
+
+var regexNonWord = regexp.MustCompile(`\W+`)
+
+// LoadSwaggerCase loads Swagger 2.0 or OpenAPI 3.x file and converts all operations to one TCase.
 func LoadSwaggerCase(path string) (*hrp.TCase, error) {
-	// load swagger file
-	caseSwagger := new(spec.Swagger)
-	err := builtin.LoadFile(path, caseSwagger)
+	caseSwagger, err := loadCaseSwagger(path)
+	if err != nil {
+		return nil, err
+	}
+	return caseSwagger.ToTCase()
+}
+
+// LoadSwaggerCases loads Swagger 2.0 or OpenAPI 3.x file and converts operations to TCases,
+// operations are grouped by their first tag, untagged operations are grouped by path.
+func LoadSwaggerCases(path string) ([]*hrp.TCase, error) {
+	caseSwagger, err := loadCaseSwagger(path)
+	if err != nil {
+		return nil, err
+	}
+	return caseSwagger.ToTCases()
+}
+
+func loadCaseSwagger(path string) (*CaseSwagger, error) {
+	log.Info().Str("path", path).Msg("load swagger case file")
+	spec, err := openapi.LoadFile(path)
 	if err != nil {
 		return nil, errors.Wrap(err, "load swagger file failed")
 	}
-	if caseSwagger.Definitions == nil {
-		return nil, errors.New("invalid swagger case file, missing definitions")
+	if _, ok := spec.Doc()["paths"].(map[string]interface{}); !ok {
+		return nil, errors.New("invalid swagger case file, missing paths")
+	}
+	return &CaseSwagger{spec: spec}, nil
+}
+
+// CaseSwagger represents the loaded Swagger 2.0 or OpenAPI 3.x document
+type CaseSwagger struct {
+	spec *openapi.Spec
+}
+
+func (c *CaseSwagger) ToTCase() (*hrp.TCase, error) {
+	var steps []*hrp.TStep
+	for _, op := range c.spec.Operations() {
+		steps = append(steps, c.prepareTestStep(op))
+	}
+	title, _ := c.spec.Doc()["info"].(map[string]interface{})["title"].(string)
+	return c.makeTCase(title, steps)
+}
+
+func (c *CaseSwagger) ToTCases() ([]*hrp.TCase, error) {
+	groups := make(map[string][]*hrp.TStep)
+	var names []string
+	for _, op := range c.spec.Operations() {
+		name := op.Path
+		if tags, ok := op.Spec["tags"].([]interface{}); ok && len(tags) > 0 {
+			name = fmt.Sprint(tags[0])
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], c.prepareTestStep(op))
+	}
+
+	if len(names) == 0 {
+		return nil, errors.New("invalid swagger case file, missing operations")
 	}
 
-	// TODO: convert swagger to TCase
-	return nil, nil
+	var tCases []*hrp.TCase
+	for _, name := range names {
+		tCase, err := c.makeTCase(name, groups[name])
+		if err != nil {
+			return nil, err
+		}
+		tCases = append(tCases, tCase)
+	}
+	return tCases, nil
+}
+
+func (c *CaseSwagger) makeTCase(name string, steps []*hrp.TStep) (*hrp.TCase, error) {
+	tCase := &hrp.TCase{
+		Config: hrp.NewConfig(name).
+			SetBaseURL(c.baseURL()).
+			SetVerifySSL(false),
+		TestSteps: steps,
+	}
+	err := tCase.MakeCompat()
+	if err != nil {
+		return nil, err
+	}
+	return tCase, nil
+}
+
+// baseURL returns base url from the first server for OpenAPI 3.x, or from schemes, host and basePath for Swagger 2.0
+func (c *CaseSwagger) baseURL() string {
+	doc := c.spec.Doc()
+	if c.spec.Version() == 2 {
+		host, _ := doc["host"].(string)
+		basePath, _ := doc["basePath"].(string)
+		if host == "" {
+			return strings.TrimSuffix(basePath, "/")
+		}
+		scheme := "https"
+		if schemes, ok := doc["schemes"].([]interface{}); ok && len(schemes) > 0 {
+			scheme = fmt.Sprint(schemes[0])
+		}
+		return strings.TrimSuffix(fmt.Sprintf("%s://%s%s", scheme, host, basePath), "/")
+	}
+
+	servers, _ := doc["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]interface{})
+	serverURL, _ := server["url"].(string)
+	// replace server variables with default values
+	variables, _ := server["variables"].(map[string]interface{})
+	for name, variable := range variables {
+		if value, ok := variable.(map[string]interface{})["default"]; ok {
+			serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", fmt.Sprint(value))
+		}
+	}
+	return strings.TrimSuffix(serverURL, "/")
+}
+
+func (c *CaseSwagger) prepareTestStep(op *openapi.Operation) *hrp.TStep {
+	log.Info().
+		Str("method", op.Method).
+		Str("path", op.Path).
+		Msg("convert teststep")
+
+	step := &stepFromSwagger{
+		TStep: hrp.TStep{
+			Request: &hrp.Request{
+				Method: hrp.HTTPMethod(op.Method),
+				URL:    op.Path,
+			},
+			Validators: make([]interface{}, 0),
+		},
+		spec: c.spec,
+	}
+	step.makeRequestName(op)
+	step.makeRequestParams(op)
+	step.makeRequestBody(op)
+	step.makeValidate(op)
+	return &step.TStep
+}
+
+type stepFromSwagger struct {
+	hrp.TStep
+	spec *openapi.Spec
+}
+
+// makeRequestName indicates the step name with summary, operationId or method and path in order
+func (s *stepFromSwagger) makeRequestName(op *openapi.Operation) {
+	switch {
+	case op.Summary != "":
+		s.Name = op.Summary
+	case op.OperationID != "":
+		s.Name = op.OperationID
+	default:
+		s.Name = fmt.Sprintf("%s %s", op.Method, op.Path)
+	}
+}
+
+// makeRequestParams converts path, query and header parameters with example values,
+// path parameters are referenced as step variables, e.g. /pets/{petId} => /pets/${petId}
+func (s *stepFromSwagger) makeRequestParams(op *openapi.Operation) {
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			varName := regexNonWord.ReplaceAllString(param.Name, "_")
+			s.Request.URL = strings.ReplaceAll(s.Request.URL, "{"+param.Name+"}", "${"+varName+"}")
+			if s.Variables == nil {
+				s.Variables = make(map[string]interface{})
+			}
+			s.Variables[varName] = s.paramExample(param)
+		case "query":
+			if !param.Required {
+				continue
+			}
+			if s.Request.Params == nil {
+				s.Request.Params = make(map[string]interface{})
+			}
+			s.Request.Params[param.Name] = s.paramExample(param)
+		case "header":
+			if !param.Required {
+				continue
+			}
+			if s.Request.Headers == nil {
+				s.Request.Headers = make(map[string]string)
+			}
+			s.Request.Headers[param.Name] = fmt.Sprint(s.paramExample(param))
+		}
+	}
+}
+
+// makeRequestBody generates example request body from requestBody for OpenAPI 3.x,
+// or from body and formData parameters for Swagger 2.0
+func (s *stepFromSwagger) makeRequestBody(op *openapi.Operation) {
+	if s.spec.Version() == 2 {
+		formData := make(map[string]interface{})
+		hasFile := false
+		for _, param := range op.Parameters {
+			switch param.In {
+			case "body":
+				schema, _ := param.Spec["schema"].(map[string]interface{})
+				s.setRequestBody("application/json", s.example(schema, 0))
+			case "formData":
+				if param.Spec["type"] == "file" {
+					hasFile = true
+				}
+				formData[param.Name] = s.paramExample(param)
+			}
+		}
+		if len(formData) > 0 {
+			contentType := "application/x-www-form-urlencoded"
+			if hasFile || hasMediaType(op.Spec["consumes"], "multipart/form-data") {
+				contentType = "multipart/form-data"
+			}
+			s.setRequestBody(contentType, formData)
+		}
+		return
+	}
+
+	requestBody, _ := s.resolve(op.Spec["requestBody"]).(map[string]interface{})
+	content, _ := requestBody["content"].(map[string]interface{})
+	mediaType := selectSwaggerMediaType(content)
+	if mediaType == "" {
+		return
+	}
+	media, _ := content[mediaType].(map[string]interface{})
+	if example, ok := media["example"]; ok {
+		s.setRequestBody(mediaType, example)
+		return
+	}
+	if examples, ok := media["examples"].(map[string]interface{}); ok && len(examples) > 0 {
+		var keys []string
+		for key := range examples {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		example, _ := s.resolve(examples[keys[0]]).(map[string]interface{})
+		if value, ok := example["value"]; ok {
+			s.setRequestBody(mediaType, value)
+			return
+		}
+	}
+	schema, _ := media["schema"].(map[string]interface{})
+	s.setRequestBody(mediaType, s.example(schema, 0))
+}
+
+func (s *stepFromSwagger) setRequestBody(contentType string, body interface{}) {
+	if body == nil {
+		return
+	}
+	if contentType == "multipart/form-data" {
+		if upload, ok := body.(map[string]interface{}); ok {
+			s.Request.Upload = upload
+			return
+		}
+	}
+	if s.Request.Headers == nil {
+		s.Request.Headers = make(map[string]string)
+	}
+	s.Request.Headers["Content-Type"] = contentType
+	s.Request.Body = body
+}
+
+// makeValidate makes validator for the first declared success response status code
+func (s *stepFromSwagger) makeValidate(op *openapi.Operation) {
+	responses, _ := op.Spec["responses"].(map[string]interface{})
+	var codes []int
+	for key := range responses {
+		key = strings.ToUpper(key)
+		if strings.HasSuffix(key, "XX") {
+			key = strings.TrimSuffix(key, "XX") + "00"
+		}
+		statusCode, err := strconv.Atoi(key)
+		if err != nil {
+			// default response
+			continue
+		}
+		codes = append(codes, statusCode)
+	}
+	if len(codes) == 0 {
+		return
+	}
+	sort.Ints(codes)
+	// prefer success status code, e.g. 200 or 201
+	expect := codes[0]
+	for _, statusCode := range codes {
+		if statusCode >= 200 && statusCode < 300 {
+			expect = statusCode
+			break
+		}
+	}
+	s.Validators = append(s.Validators, hrp.Validator{
+		Check:   "status_code",
+		Assert:  "equals",
+		Expect:  expect,
+		Message: "assert response status code",
+	})
+}
+
+// paramExample returns example value of parameter,
+// schema is located in schema field for OpenAPI 3.x, or parameter itself for Swagger 2.0
+func (s *stepFromSwagger) paramExample(param *openapi.Parameter) interface{} {
+	if example, ok := param.Spec["example"]; ok {
+		return example
+	}
+	if schema, ok := param.Spec["schema"].(map[string]interface{}); ok {
+		return s.example(schema, 0)
+	}
+	return s.example(param.Spec, 0)
+}
+
+// example generates example value from schema, example, default and enum values are preferred
+func (s *stepFromSwagger) example(schema map[string]interface{}, depth int) interface{} {
+	schema, _ = s.resolve(schema).(map[string]interface{})
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default", "x-example"} {
+		if value, ok := schema[key]; ok {
+			return value
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, item := range allOf {
+			sub, _ := item.(map[string]interface{})
+			if value, ok := s.example(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range value {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if items, ok := schema[key].([]interface{}); ok && len(items) > 0 {
+			sub, _ := items[0].(map[string]interface{})
+			return s.example(sub, depth+1)
+		}
+	}
+
+	typ, _ := schema["type"].(string)
+	if typ == "" {
+		if _, ok := schema["properties"]; ok {
+			typ = "object"
+		} else if _, ok := schema["items"]; ok {
+			typ = "array"
+		}
+	}
+	switch typ {
+	case "object":
+		value := make(map[string]interface{})
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, property := range properties {
+			sub, _ := property.(map[string]interface{})
+			if sub["readOnly"] == true {
+				continue
+			}
+			value[name] = s.example(sub, depth+1)
+		}
+		return value
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		item := s.example(items, depth+1)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	case "integer", "number":
+		if minimum, ok := schema["minimum"]; ok {
+			return minimum
+		}
+		return 0
+	case "boolean":
+		return true
+	case "string", "file":
+		return stringExample(schema)
+	}
+	return nil
+}
+
+func stringExample(schema map[string]interface{}) string {
+	format, _ := schema["format"].(string)
+	switch format {
+	case "date":
+		return "2023-01-01"
+	case "date-time":
+		return "2023-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "hostname":
+		return "example.com"
+	}
+	return "string"
+}
+
+// resolve resolves $ref of value, other values are returned as is
+func (s *stepFromSwagger) resolve(value interface{}) interface{} {
+	for i := 0; i < maxExampleDepth; i++ {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return value
+		}
+		resolved, err := s.spec.Resolve(ref)
+		if err != nil {
+			log.Warn().Err(err).Str("ref", ref).Msg("resolve reference failed, ignore")
+			return nil
+		}
+		value = resolved
+	}
+	return value
+}
+
+// selectSwaggerMediaType selects JSON media type in preference to form and other media types
+func selectSwaggerMediaType(content map[string]interface{}) string {
+	var mediaTypes []string
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, prefer := range []string{"json", "application/x-www-form-urlencoded", "multipart/form-data"} {
+		for _, mediaType := range mediaTypes {
+			if strings.Contains(mediaType, prefer) {
+				return mediaType
+			}
+		}
+	}
+	if len(mediaTypes) > 0 {
+		return mediaTypes[0]
+	}
+	return ""
+}
+
+func hasMediaType(mediaTypes interface{}, mediaType string) bool {
+	list, _ := mediaTypes.([]interface{})
+	for _, item := range list {
+		if item == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// swaggerCaseFileName returns file name part for TCase converted from swagger, e.g. pet, pets_petId
+func swaggerCaseFileName(name string) string {
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return strings.Trim(regexNonWord.ReplaceAllString(name, "_"), "_")
 }
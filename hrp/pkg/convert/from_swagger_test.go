@@ -0,0 +1,135 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/httprunner/httprunner/v4/hrp"
+)
+
+var (
+	swaggerPath   = "../../../examples/data/swagger/petstore.yaml"
+	swaggerV2Path = "../../../examples/data/swagger/petstore_v2.json"
+)
+
+func TestLoadSwaggerCases(t *testing.T) {
+	tCases, err := LoadSwaggerCases(swaggerPath)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	// grouped by tag, untagged operation is grouped by path
+	if !assert.Len(t, tCases, 2) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "pet", tCases[0].Config.Name) || !assert.Equal(t, "/store/inventory", tCases[1].Config.Name) {
+		t.Fatal()
+	}
+	// base url with server variables replaced
+	if !assert.Equal(t, "https://petstore.example.com/v1", tCases[0].Config.BaseURL) {
+		t.Fatal()
+	}
+
+	steps := tCases[0].TestSteps
+	if !assert.Len(t, steps, 3) {
+		t.Fatal()
+	}
+
+	// list pets with required query param
+	if !assert.Equal(t, "list pets", steps[0].Name) || !assert.Equal(t, hrp.HTTPMethod("GET"), steps[0].Request.Method) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{"limit": float64(1)}, steps[0].Request.Params) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, hrp.Validator{
+		Check: "status_code", Assert: "equals", Expect: 200, Message: "assert response status code",
+	}, steps[0].Validators[0]) {
+		t.Fatal()
+	}
+
+	// create pet with example body generated from schema
+	if !assert.Equal(t, "create pet", steps[1].Name) || !assert.Equal(t, "/pets", steps[1].Request.URL) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{
+		"name":     "doggie",
+		"status":   "available",
+		"birthday": "2023-01-01",
+		"tags":     []interface{}{"string"},
+	}, steps[1].Request.Body) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "application/json", steps[1].Request.Headers["Content-Type"]) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, 201, steps[1].Validators[0].(hrp.Validator).Expect) {
+		t.Fatal()
+	}
+
+	// path parameter is referenced as step variable
+	if !assert.Equal(t, "getPet", steps[2].Name) || !assert.Equal(t, "/pets/${petId}", steps[2].Request.URL) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{"petId": float64(1)}, steps[2].Variables) {
+		t.Fatal()
+	}
+
+	// 2XX range response
+	if !assert.Equal(t, 200, tCases[1].TestSteps[0].Validators[0].(hrp.Validator).Expect) {
+		t.Fatal()
+	}
+}
+
+func TestLoadSwaggerV2Case(t *testing.T) {
+	tCase, err := LoadSwaggerCase(swaggerV2Path)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "Petstore", tCase.Config.Name) || !assert.Equal(t, "http://petstore.example.com/v2", tCase.Config.BaseURL) {
+		t.Fatal()
+	}
+	steps := tCase.TestSteps
+	if !assert.Len(t, steps, 2) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{
+		"id":        0,
+		"name":      "doggie",
+		"photoUrls": []interface{}{"string"},
+	}, steps[0].Request.Body) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "/pet/${petId}", steps[1].Request.URL) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]interface{}{"name": "string", "status": "available"}, steps[1].Request.Body) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "application/x-www-form-urlencoded", steps[1].Request.Headers["Content-Type"]) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, 405, steps[1].Validators[0].(hrp.Validator).Expect) {
+		t.Fatal()
+	}
+}
+
+func TestConvertSwaggerCases(t *testing.T) {
+	outputDir := t.TempDir()
+	caseConverter := NewConverter(outputDir, "")
+	err := caseConverter.Convert(swaggerPath, FromTypeSwagger, OutputTypeYAML)
+	if !assert.NoError(t, err) {
+		t.Fatal()
+	}
+	for _, name := range []string{"petstore_pet_test.yaml", "petstore_store_inventory_test.yaml"} {
+		if !assert.FileExists(t, filepath.Join(outputDir, name)) {
+			t.Fatal()
+		}
+	}
+	files, _ := os.ReadDir(outputDir)
+	if !assert.Len(t, files, 2) {
+		t.Fatal()
+	}
+}
@@ -31,6 +31,7 @@ const (
 	FromTypeSwagger
 	FromTypePyest
 	FromTypeGotest
+	FromTypeJMeter
 )
 
 func (fromType FromType) String() string {
@@ -49,6 +50,8 @@ func (fromType FromType) String() string {
 		return "gotest"
 	case FromTypePyest:
 		return "pytest"
+	case FromTypeJMeter:
+		return "jmeter"
 	default:
 		return "json"
 	}
@@ -60,14 +63,18 @@ func (fromType FromType) Extensions() []string {
 		return []string{suffixYAML, ".yml"}
 	case FromTypeHAR:
 		return []string{suffixHAR}
-	case FromTypePostman, FromTypeSwagger:
+	case FromTypePostman:
 		return []string{suffixJSON}
+	case FromTypeSwagger:
+		return []string{suffixJSON, suffixYAML, ".yml"}
 	case FromTypeCurl:
 		return []string{".txt", ".curl"}
 	case FromTypeGotest:
 		return []string{suffixGoTest}
 	case FromTypePyest:
 		return []string{suffixPyTest}
+	case FromTypeJMeter:
+		return []string{".jmx"}
 	default:
 		return []string{suffixJSON}
 	}
@@ -115,11 +122,14 @@ type TCaseConverter struct {
 	profilePath string
 	outputDir   string
 	tCase       *hrp.TCase
+	tCases      []*hrp.TCase // multiple TCases converted from one source file, e.g. swagger grouped by tags
+	caseName    string       // name of current TCase in tCases, used in output file name
 }
 
 // LoadCase loads source file and convert to TCase type
 func (c *TCaseConverter) loadCase(casePath string, fromType FromType) error {
 	c.fromFile = casePath
+	c.tCases = nil
 	var err error
 	switch fromType {
 	case FromTypeJSON:
@@ -131,9 +141,11 @@ func (c *TCaseConverter) loadCase(casePath string, fromType FromType) error {
 	case FromTypePostman:
 		c.tCase, err = LoadPostmanCase(casePath)
 	case FromTypeSwagger:
-		c.tCase, err = LoadSwaggerCase(casePath)
+		c.tCases, err = LoadSwaggerCases(casePath)
 	case FromTypeCurl:
 		c.tCase, err = LoadCurlCase(casePath)
+	case FromTypeJMeter:
+		c.tCase, err = LoadJMeterCase(casePath)
 	}
 	return err
 }
@@ -160,7 +172,25 @@ func (c *TCaseConverter) Convert(casePath string, fromType FromType, outputType
 	if err != nil {
 		return err
 	}
+	if c.tCases == nil {
+		return c.convertTCase(outputType)
+	}
 
+	// convert each TCase to separate output file
+	defer func() {
+		c.caseName = ""
+	}()
+	for _, tCase := range c.tCases {
+		c.tCase = tCase
+		c.caseName = swaggerCaseFileName(tCase.Config.Name)
+		if err = c.convertTCase(outputType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *TCaseConverter) convertTCase(outputType OutputType) (err error) {
 	// override TCase with profile
 	if c.profilePath != "" {
 		c.overrideWithProfile(c.profilePath)
@@ -187,7 +217,11 @@ func (c *TCaseConverter) Convert(casePath string, fromType FromType, outputType
 }
 
 func (c *TCaseConverter) genOutputPath(suffix string) string {
-	outFileFullName := builtin.GetFileNameWithoutExtension(c.fromFile) + "_test" + suffix
+	outFileName := builtin.GetFileNameWithoutExtension(c.fromFile)
+	if c.caseName != "" {
+		outFileName += "_" + c.caseName
+	}
+	outFileFullName := outFileName + "_test" + suffix
 	if c.outputDir != "" {
 		return filepath.Join(c.outputDir, outFileFullName)
 	} else {
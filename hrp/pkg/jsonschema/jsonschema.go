@@ -0,0 +1,243 @@
+// Package jsonschema validates JSON values against JSON Schema (draft-04 to 2020-12),
+// it wraps github.com/santhosh-tekuri/jsonschema/v5 and reports failed keywords as
+// violations with JSON pointer paths. References to files are resolved relative to
+// the schema file, remote references over network are not supported.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Draft is the JSON schema draft used when $schema is missing in schema.
+type Draft int
+
+const (
+	Draft4    Draft = 4    // used by Swagger 2.0 and OpenAPI 3.0
+	Draft7    Draft = 7    // draft-07
+	Draft2020 Draft = 2020 // used by OpenAPI 3.1, default draft
+)
+
+var drafts = map[Draft]*jsonschema.Draft{
+	Draft4:    jsonschema.Draft4,
+	Draft7:    jsonschema.Draft7,
+	Draft2020: jsonschema.Draft2020,
+}
+
+// memoryURL is the base URL of schema compiled from value in memory.
+const memoryURL = "mem:///schema.json"
+
+// Violation represents one failed keyword of schema validation.
+type Violation struct {
+	Path    string `json:"path" yaml:"path"`       // JSON pointer of the invalid instance, e.g. #/items/0/id
+	Keyword string `json:"keyword" yaml:"keyword"` // schema keyword that failed, e.g. required
+	Message string `json:"message" yaml:"message"`
+}
+
+func (v *Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// Schema is a compiled JSON schema.
+type Schema struct {
+	compiler *compiler // shared with sub schemas of the same document
+	url      string    // base URL of document
+	schema   *jsonschema.Schema
+}
+
+// compiler is not safe for concurrent use, sub schemas are compiled lazily.
+type compiler struct {
+	sync.Mutex
+	*jsonschema.Compiler
+}
+
+func newCompiler(draft Draft) (*compiler, error) {
+	d, ok := drafts[draft]
+	if !ok {
+		return nil, errors.Errorf("unsupported JSON schema draft %d", draft)
+	}
+	c := jsonschema.NewCompiler()
+	c.Draft = d
+	// formats are annotations only since draft 2019-09, assert them for contract testing
+	c.AssertFormat = true
+	c.LoadURL = loadURL
+	return &compiler{Compiler: c}, nil
+}
+
+// Compile compiles schema, which could be decoded from JSON/YAML or constructed with Go maps.
+// Draft 2020-12 is used if $schema is missing.
+func Compile(schema interface{}) (*Schema, error) {
+	return CompileDraft(schema, Draft2020)
+}
+
+// CompileDraft compiles schema with the given draft if $schema is missing.
+func CompileDraft(schema interface{}, draft Draft) (*Schema, error) {
+	data, err := json.Marshal(convertYAMLMap(schema))
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal schema failed")
+	}
+	c, err := newCompiler(draft)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.AddResource(memoryURL, bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrap(err, "invalid schema")
+	}
+	return c.compile(memoryURL)
+}
+
+// LoadFile loads and compiles schema from JSON or YAML file,
+// relative references to other files are resolved against the schema file.
+func LoadFile(path string) (*Schema, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "get schema file path failed")
+	}
+	c, err := newCompiler(Draft2020)
+	if err != nil {
+		return nil, err
+	}
+	return c.compile((&url.URL{Scheme: "file", Path: filepath.ToSlash(absPath)}).String())
+}
+
+func (c *compiler) compile(url string) (*Schema, error) {
+	c.Lock()
+	defer c.Unlock()
+	schema, err := c.Compile(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile schema failed")
+	}
+	return &Schema{
+		compiler: c,
+		url:      url,
+		schema:   schema,
+	}, nil
+}
+
+// Sub returns the sub schema located by JSON pointer reference in the same document,
+// e.g. #/definitions/User. $ref in sub schema is resolved against the whole document,
+// which is useful for validating with schemas embedded in OpenAPI documents.
+func (s *Schema) Sub(ref string) (*Schema, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, errors.Errorf("sub schema reference %s should start with #", ref)
+	}
+	return s.compiler.compile(s.url + ref)
+}
+
+// Validate validates instance against schema, returns all violations found.
+func (s *Schema) Validate(instance interface{}) []*Violation {
+	value, err := normalize(instance)
+	if err != nil {
+		return []*Violation{{Path: "#", Keyword: "type", Message: err.Error()}}
+	}
+	err = s.schema.Validate(value)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		// infinite loop or invalid JSON type
+		return []*Violation{{Path: "#", Keyword: "$ref", Message: err.Error()}}
+	}
+	return collectViolations(validationErr, nil)
+}
+
+// collectViolations converts leaf causes of validation error to violations.
+func collectViolations(err *jsonschema.ValidationError, violations []*Violation) []*Violation {
+	if len(err.Causes) == 0 {
+		keyword := err.KeywordLocation[strings.LastIndex(err.KeywordLocation, "/")+1:]
+		keyword = strings.ReplaceAll(strings.ReplaceAll(keyword, "~1", "/"), "~0", "~")
+		return append(violations, &Violation{
+			Path:    "#" + err.InstanceLocation,
+			Keyword: keyword,
+			Message: err.Message,
+		})
+	}
+	for _, cause := range err.Causes {
+		violations = collectViolations(cause, violations)
+	}
+	return violations
+}
+
+// loadURL loads referenced JSON or YAML schema file, other schemes are not supported.
+func loadURL(s string) (io.ReadCloser, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "file" {
+		return nil, errors.Errorf("unsupported remote reference %s", s)
+	}
+	path := filepath.FromSlash(u.Path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read schema file failed")
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var schema interface{}
+		if err := yaml.Unmarshal(content, &schema); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal schema file %s failed", path)
+		}
+		if content, err = json.Marshal(convertYAMLMap(schema)); err != nil {
+			return nil, errors.Wrapf(err, "convert schema file %s failed", path)
+		}
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// normalize converts value to generic JSON types:
+// nil, bool, json.Number, string, []interface{} and map[string]interface{}.
+func normalize(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(convertYAMLMap(value))
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized interface{}
+	err = decoder.Decode(&normalized)
+	return normalized, err
+}
+
+// convertYAMLMap converts map[interface{}]interface{} decoded by yaml.v2 to map[string]interface{}.
+func convertYAMLMap(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = convertYAMLMap(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = convertYAMLMap(val)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, val := range v {
+			l[i] = convertYAMLMap(val)
+		}
+		return l
+	default:
+		return value
+	}
+}
+
+// EscapePointer escapes JSON pointer reference token, "~" to "~0" and "/" to "~1".
+func EscapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
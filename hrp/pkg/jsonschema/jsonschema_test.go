@@ -0,0 +1,222 @@
+package jsonschema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var userSchema = map[string]interface{}{
+	"$schema":  "https://json-schema.org/draft/2020-12/schema",
+	"type":     "object",
+	"required": []string{"id", "name", "tags"},
+	"properties": map[string]interface{}{
+		"id":    map[string]interface{}{"type": "integer", "minimum": 1},
+		"name":  map[string]interface{}{"type": "string", "minLength": 1},
+		"email": map[string]interface{}{"type": "string", "format": "email"},
+		"role":  map[string]interface{}{"enum": []string{"admin", "user"}},
+		"tags": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"$ref": "#/$defs/tag"},
+			"uniqueItems": true,
+		},
+	},
+	"additionalProperties": false,
+	"$defs": map[string]interface{}{
+		"tag": map[string]interface{}{"type": "string", "pattern": "^[a-z]+$"},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile(userSchema)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	violations := schema.Validate(map[string]interface{}{
+		"id":    1,
+		"name":  "debugtalk",
+		"email": "debugtalk@example.com",
+		"role":  "admin",
+		"tags":  []interface{}{"go", "python"},
+	})
+	if !assert.Empty(t, violations) {
+		t.Fatal()
+	}
+
+	violations = schema.Validate(map[string]interface{}{
+		"id":    1.5,
+		"email": "invalid",
+		"role":  "guest",
+		"tags":  []interface{}{"go", "Go", "go"},
+		"extra": true,
+	})
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path+" "+v.Keyword)
+	}
+	if !assert.ElementsMatch(t, []string{
+		"# required",
+		"#/id type",
+		"#/email format",
+		"#/role enum",
+		"#/tags uniqueItems",
+		"#/tags/1 pattern",
+		"# additionalProperties",
+	}, paths) {
+		t.Fatal()
+	}
+}
+
+func TestValidateDraft07(t *testing.T) {
+	schema, err := Compile(map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"definitions": map[string]interface{}{
+			"point": map[string]interface{}{
+				"type":            "array",
+				"items":           []interface{}{map[string]interface{}{"type": "number"}, map[string]interface{}{"type": "number"}},
+				"additionalItems": false,
+			},
+		},
+		"type": "object",
+		"properties": map[string]interface{}{
+			"points": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"$ref": "#/definitions/point"},
+			},
+			"kind": map[string]interface{}{"oneOf": []interface{}{
+				map[string]interface{}{"const": "line"},
+				map[string]interface{}{"const": "polygon"},
+			}},
+		},
+		"if": map[string]interface{}{
+			"properties": map[string]interface{}{"kind": map[string]interface{}{"const": "polygon"}},
+		},
+		"then": map[string]interface{}{
+			"properties": map[string]interface{}{"points": map[string]interface{}{"minItems": 3}},
+		},
+	})
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	violations := schema.Validate(map[string]interface{}{
+		"kind":   "line",
+		"points": []interface{}{[]interface{}{0, 0}, []interface{}{1, 1}},
+	})
+	if !assert.Empty(t, violations) {
+		t.Fatal()
+	}
+
+	violations = schema.Validate(map[string]interface{}{
+		"kind":   "polygon",
+		"points": []interface{}{[]interface{}{0, 0}, []interface{}{1, "1", 2}},
+	})
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path+" "+v.Keyword)
+	}
+	if !assert.ElementsMatch(t, []string{
+		"#/points minItems",
+		"#/points/1/1 type",
+		"#/points/1 additionalItems",
+	}, paths) {
+		t.Fatal()
+	}
+}
+
+func TestValidateDraft2020(t *testing.T) {
+	schema, err := Compile(map[string]interface{}{
+		"$id": "https://example.com/order.json",
+		"$defs": map[string]interface{}{
+			"amount": map[string]interface{}{
+				"$anchor": "amount",
+				"type":    "number",
+				"minimum": 0,
+			},
+		},
+		"type": "object",
+		"allOf": []interface{}{
+			map[string]interface{}{"properties": map[string]interface{}{
+				"id": map[string]interface{}{"type": "string"},
+			}},
+		},
+		"properties": map[string]interface{}{
+			"total": map[string]interface{}{"$ref": "#amount"},
+			"items": map[string]interface{}{
+				"type":             "array",
+				"prefixItems":      []interface{}{map[string]interface{}{"$ref": "https://example.com/order.json#amount"}},
+				"unevaluatedItems": false,
+			},
+			"note": map[string]interface{}{"type": "string", "nullable": true},
+		},
+		"unevaluatedProperties": false,
+	})
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	violations := schema.Validate(map[string]interface{}{
+		"id":    "1",
+		"total": 1.5,
+		"items": []interface{}{1.5},
+	})
+	if !assert.Empty(t, violations) {
+		t.Fatal()
+	}
+
+	// nullable is an OpenAPI extension, it is ignored in JSON schema
+	violations = schema.Validate(map[string]interface{}{
+		"id":    "1",
+		"total": -1,
+		"items": []interface{}{1.5, 2},
+		"note":  nil,
+		"extra": true,
+	})
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path+" "+v.Keyword)
+	}
+	if !assert.ElementsMatch(t, []string{
+		"#/total minimum",
+		"#/items/1 unevaluatedItems",
+		"#/note type",
+		"#/extra unevaluatedProperties",
+	}, paths) {
+		t.Fatal()
+	}
+}
+
+func TestCompileUnsupportedReference(t *testing.T) {
+	_, err := Compile(map[string]interface{}{
+		"$ref": "https://example.com/schema.json",
+	})
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.yaml")
+	content := "type: object\nrequired: [status]\nproperties:\n  status:\n    $ref: status.yml\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "status.yml"), []byte("type: string\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadFile(path)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	violations := schema.Validate(map[string]interface{}{"status": 200})
+	if !assert.Len(t, violations, 1) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "#/status: expected string, but got number", violations[0].String()) {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,440 @@
+// Package openapi loads Swagger 2.0 and OpenAPI 3.x documents, and validates
+// HTTP requests and responses against the documented operations.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/httprunner/httprunner/v4/hrp/pkg/jsonschema"
+)
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is a loaded Swagger 2.0 or OpenAPI 3.x document.
+type Spec struct {
+	doc       map[string]interface{}
+	schema    *jsonschema.Schema // whole document compiled, used to locate sub schemas
+	version   int                // major version, 2 for Swagger 2.0, 3 for OpenAPI 3.x
+	basePaths []string
+	paths     []*pathTemplate
+
+	mu      sync.Mutex
+	schemas map[string]*jsonschema.Schema // compiled sub schemas, key is JSON pointer
+}
+
+// Operation is an API operation in spec.
+type Operation struct {
+	Method      string                 // upper case HTTP method
+	Path        string                 // path template, e.g. /users/{id}
+	OperationID string                 // operationId of operation
+	Summary     string                 // summary of operation
+	Spec        map[string]interface{} // raw operation object
+	Parameters  []*Parameter           // path level and operation level parameters
+
+	pointer string // JSON pointer of operation object in document
+}
+
+// Parameter is a parameter of operation.
+type Parameter struct {
+	Name     string
+	In       string // path, query, header, cookie, body or formData
+	Required bool
+	Spec     map[string]interface{} // raw parameter object
+
+	pointer string // JSON pointer of parameter object in document
+}
+
+type pathTemplate struct {
+	path     string
+	segments []string
+	params   int // count of templated segments
+}
+
+// LoadFile loads spec from JSON or YAML file.
+func LoadFile(path string) (*Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read openapi file failed")
+	}
+	var doc interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &doc)
+	default:
+		// YAML is a superset of JSON
+		err = yaml.Unmarshal(content, &doc)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal openapi file %s failed", path)
+	}
+	return Load(doc)
+}
+
+// Load loads spec from decoded JSON/YAML document.
+func Load(document interface{}) (*Spec, error) {
+	// normalized document for inspecting
+	var doc map[string]interface{}
+	data, err := json.Marshal(convertYAMLMap(document))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "invalid openapi document")
+	}
+
+	spec := &Spec{
+		doc:     doc,
+		schemas: make(map[string]*jsonschema.Schema),
+	}
+	// schemas of Swagger 2.0 and OpenAPI 3.0 are based on JSON schema draft-04 with nullable extension,
+	// OpenAPI 3.1 is compatible with JSON schema 2020-12.
+	var schemaDoc interface{} = doc
+	draft := jsonschema.Draft4
+	if version, ok := doc["swagger"].(string); ok && strings.HasPrefix(version, "2") {
+		spec.version = 2
+		if basePath, ok := doc["basePath"].(string); ok {
+			spec.basePaths = append(spec.basePaths, basePath)
+		}
+		schemaDoc = convertNullable(doc, "x-nullable")
+	} else if version, ok := doc["openapi"].(string); ok && strings.HasPrefix(version, "3") {
+		spec.version = 3
+		servers, _ := doc["servers"].([]interface{})
+		for _, server := range servers {
+			serverURL, _ := server.(map[string]interface{})["url"].(string)
+			if u, err := url.Parse(serverURL); err == nil {
+				spec.basePaths = append(spec.basePaths, u.Path)
+			}
+		}
+		if strings.HasPrefix(version, "3.0") {
+			schemaDoc = convertNullable(doc, "nullable")
+		} else {
+			draft = jsonschema.Draft2020
+		}
+	} else {
+		return nil, errors.New("unsupported openapi document, missing swagger 2.0 or openapi 3.x version")
+	}
+	spec.schema, err = jsonschema.CompileDraft(schemaDoc, draft)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid openapi document")
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path := range paths {
+		tmpl := &pathTemplate{
+			path:     path,
+			segments: splitPath(path),
+		}
+		for _, segment := range tmpl.segments {
+			if strings.Contains(segment, "{") {
+				tmpl.params++
+			}
+		}
+		spec.paths = append(spec.paths, tmpl)
+	}
+	// prefer literal paths to templated paths, e.g. /users/me before /users/{id}
+	sort.Slice(spec.paths, func(i, j int) bool {
+		if spec.paths[i].params != spec.paths[j].params {
+			return spec.paths[i].params < spec.paths[j].params
+		}
+		return spec.paths[i].path < spec.paths[j].path
+	})
+	return spec, nil
+}
+
+// Version returns major version of spec, 2 for Swagger 2.0, 3 for OpenAPI 3.x.
+func (s *Spec) Version() int {
+	return s.version
+}
+
+// Doc returns the raw document of spec.
+func (s *Spec) Doc() map[string]interface{} {
+	return s.doc
+}
+
+// Operations returns all operations in spec, sorted by path and method.
+func (s *Spec) Operations() []*Operation {
+	var paths []string
+	for _, tmpl := range s.paths {
+		paths = append(paths, tmpl.path)
+	}
+	sort.Strings(paths)
+
+	var operations []*Operation
+	for _, path := range paths {
+		for _, method := range methods {
+			if op := s.operation(path, method); op != nil {
+				operations = append(operations, op)
+			}
+		}
+	}
+	return operations
+}
+
+// FindOperation finds operation matching request method and URL path,
+// returns the operation and path parameters parsed from path.
+func (s *Spec) FindOperation(method, path string) (*Operation, map[string]string) {
+	method = strings.ToLower(method)
+	candidates := []string{path}
+	for _, basePath := range s.basePaths {
+		basePath = strings.TrimSuffix(basePath, "/")
+		if basePath != "" && strings.HasPrefix(path, basePath) {
+			candidates = append([]string{strings.TrimPrefix(path, basePath)}, candidates...)
+		}
+	}
+
+	for _, candidate := range candidates {
+		segments := splitPath(candidate)
+		for _, tmpl := range s.paths {
+			params, ok := tmpl.match(segments)
+			if !ok {
+				continue
+			}
+			if op := s.operation(tmpl.path, method); op != nil {
+				return op, params
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Spec) operation(path, method string) *Operation {
+	pathItem, _ := s.doc["paths"].(map[string]interface{})[path].(map[string]interface{})
+	opSpec, ok := pathItem[method].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	pathPointer := "#/paths/" + jsonschema.EscapePointer(path)
+	op := &Operation{
+		Method:  strings.ToUpper(method),
+		Path:    path,
+		Spec:    opSpec,
+		pointer: pathPointer + "/" + method,
+	}
+	op.OperationID, _ = opSpec["operationId"].(string)
+	op.Summary, _ = opSpec["summary"].(string)
+
+	// merge path level and operation level parameters, operation level overrides
+	params := make(map[string]*Parameter)
+	var keys []string
+	collect := func(parameters interface{}, pointer string) {
+		list, _ := parameters.([]interface{})
+		for i, item := range list {
+			paramSpec, _ := item.(map[string]interface{})
+			paramPointer := fmt.Sprintf("%s/parameters/%d", pointer, i)
+			if ref, ok := paramSpec["$ref"].(string); ok {
+				resolved, err := s.resolve(ref)
+				if err != nil {
+					continue
+				}
+				paramSpec, _ = resolved.(map[string]interface{})
+				paramPointer = ref
+			}
+			if paramSpec == nil {
+				continue
+			}
+			param := &Parameter{
+				Spec:    paramSpec,
+				pointer: paramPointer,
+			}
+			param.Name, _ = paramSpec["name"].(string)
+			param.In, _ = paramSpec["in"].(string)
+			param.Required, _ = paramSpec["required"].(bool)
+			key := param.In + ":" + param.Name
+			if _, exists := params[key]; !exists {
+				keys = append(keys, key)
+			}
+			params[key] = param
+		}
+	}
+	collect(pathItem["parameters"], pathPointer)
+	collect(opSpec["parameters"], op.pointer)
+	for _, key := range keys {
+		op.Parameters = append(op.Parameters, params[key])
+	}
+	return op
+}
+
+// Resolve returns the value located by local JSON pointer reference, e.g. #/components/schemas/Pet.
+func (s *Spec) Resolve(ref string) (interface{}, error) {
+	return s.resolve(ref)
+}
+
+// resolve returns the value located by JSON pointer reference in document.
+func (s *Spec) resolve(ref string) (interface{}, error) {
+	pointer, err := url.PathUnescape(strings.TrimPrefix(ref, "#"))
+	if err != nil || !strings.HasPrefix(ref, "#") {
+		return nil, errors.Errorf("unsupported reference %s", ref)
+	}
+	current := interface{}(s.doc)
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[token]
+			if !ok {
+				return nil, errors.Errorf("reference %s not found", ref)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, errors.Errorf("reference %s not found", ref)
+			}
+			current = node[index]
+		default:
+			return nil, errors.Errorf("reference %s not found", ref)
+		}
+	}
+	return current, nil
+}
+
+// compile compiles sub schema located by JSON pointer, compiled schemas are cached.
+func (s *Spec) compile(pointer string) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.schemas[pointer]; ok {
+		return schema, nil
+	}
+	schema, err := s.schema.Sub(pointer)
+	if err != nil {
+		return nil, err
+	}
+	s.schemas[pointer] = schema
+	return schema, nil
+}
+
+// compileParameter compiles Swagger 2.0 parameter without schema field,
+// which is the schema itself except for the fixed fields, e.g. its boolean required.
+func (s *Spec) compileParameter(param *Parameter) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.schemas[param.pointer]; ok {
+		return schema, nil
+	}
+	paramSchema := make(map[string]interface{}, len(param.Spec))
+	for key, value := range param.Spec {
+		switch key {
+		case "name", "in", "required", "description", "allowEmptyValue", "collectionFormat":
+		default:
+			paramSchema[key] = value
+		}
+	}
+	schema, err := jsonschema.CompileDraft(convertNullable(paramSchema, "x-nullable"), jsonschema.Draft4)
+	if err != nil {
+		return nil, err
+	}
+	s.schemas[param.pointer] = schema
+	return schema, nil
+}
+
+// convertNullable converts nullable (OpenAPI 3.0) or x-nullable (Swagger 2.0) of schemas
+// to JSON schema, null value is allowed in addition to the declared type or enum.
+func convertNullable(value interface{}, keyword string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = convertNullable(val, keyword)
+		}
+		if nullable, _ := m[keyword].(bool); !nullable {
+			return m
+		}
+		if ref, ok := m["$ref"]; ok {
+			// siblings of $ref are ignored in draft-04
+			return map[string]interface{}{"anyOf": []interface{}{
+				map[string]interface{}{"$ref": ref},
+				map[string]interface{}{"type": "null"},
+			}}
+		}
+		if typ, ok := m["type"].(string); ok {
+			m["type"] = []interface{}{typ, "null"}
+		}
+		if enum, ok := m["enum"].([]interface{}); ok {
+			m["enum"] = append(enum, nil)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, val := range v {
+			l[i] = convertNullable(val, keyword)
+		}
+		return l
+	default:
+		return value
+	}
+}
+
+func (t *pathTemplate) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(t.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, segment := range t.segments {
+		start := strings.Index(segment, "{")
+		end := strings.LastIndex(segment, "}")
+		if start < 0 || end < start {
+			if segment != segments[i] {
+				return nil, false
+			}
+			continue
+		}
+		// templated segment, e.g. {id} or {name}.json
+		prefix, suffix := segment[:start], segment[end+1:]
+		value := segments[i]
+		if !strings.HasPrefix(value, prefix) || !strings.HasSuffix(value, suffix) ||
+			len(value) <= len(prefix)+len(suffix) {
+			return nil, false
+		}
+		value = value[len(prefix) : len(value)-len(suffix)]
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			value = unescaped
+		}
+		params[segment[start+1:end]] = value
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// convertYAMLMap converts map[interface{}]interface{} decoded by yaml.v2 to map[string]interface{}.
+func convertYAMLMap(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = convertYAMLMap(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = convertYAMLMap(val)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, val := range v {
+			l[i] = convertYAMLMap(val)
+		}
+		return l
+	default:
+		return value
+	}
+}
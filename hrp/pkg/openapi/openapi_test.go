@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func violationPaths(t *testing.T, spec *Spec, req *Request, resp *Response) []string {
+	_, violations := spec.Validate(req, resp)
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path+" "+v.Keyword)
+	}
+	return paths
+}
+
+func TestFindOperation(t *testing.T) {
+	spec, err := LoadFile("testdata/petstore_v3.yaml")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, 3, spec.Version()) || !assert.Len(t, spec.Operations(), 3) {
+		t.Fatal()
+	}
+
+	op, params := spec.FindOperation("GET", "/v1/pets/mine")
+	if !assert.NotNil(t, op) || !assert.Equal(t, "getMyPets", op.OperationID) || !assert.Empty(t, params) {
+		t.Fatal()
+	}
+	op, params = spec.FindOperation("GET", "/v1/pets/12/")
+	if !assert.NotNil(t, op) || !assert.Equal(t, "getPet", op.OperationID) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, map[string]string{"petId": "12"}, params) {
+		t.Fatal()
+	}
+	op, _ = spec.FindOperation("DELETE", "/v1/pets/12")
+	if !assert.Nil(t, op) {
+		t.Fatal()
+	}
+}
+
+func TestValidateV3(t *testing.T) {
+	spec, err := LoadFile("testdata/petstore_v3.yaml")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	paths := violationPaths(t, spec,
+		&Request{Method: "POST", Path: "/v1/pets", ContentType: "application/json",
+			Body: map[string]interface{}{"name": "kitty", "tag": nil}},
+		&Response{StatusCode: 201, ContentType: "application/json",
+			Body: map[string]interface{}{"id": 1, "name": "kitty"}})
+	if !assert.Empty(t, paths) {
+		t.Fatal()
+	}
+
+	paths = violationPaths(t, spec,
+		&Request{Method: "POST", Path: "/v1/pets", ContentType: "application/json",
+			Body: `{"tag": 1}`},
+		&Response{StatusCode: 200, ContentType: "application/json", Body: map[string]interface{}{}})
+	if !assert.ElementsMatch(t, []string{
+		"request.body# required",
+		"request.body#/tag type",
+		"response.status responses",
+	}, paths) {
+		t.Fatal()
+	}
+
+	paths = violationPaths(t, spec,
+		&Request{Method: "GET", Path: "/v1/pets/0"},
+		&Response{StatusCode: 404, ContentType: "application/json", Body: map[string]interface{}{"msg": "not found"}})
+	if !assert.ElementsMatch(t, []string{
+		"request.path.petId# minimum",
+		"response.body# required",
+	}, paths) {
+		t.Fatal()
+	}
+
+	paths = violationPaths(t, spec, &Request{Method: "GET", Path: "/v1/users"}, nil)
+	if !assert.Equal(t, []string{"request operation"}, paths) {
+		t.Fatal()
+	}
+}
+
+func TestValidateV2(t *testing.T) {
+	spec, err := LoadFile("testdata/petstore_v2.json")
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	paths := violationPaths(t, spec,
+		&Request{Method: "GET", Path: "/v2/pets/abc"},
+		&Response{StatusCode: 200, ContentType: "application/json; charset=utf-8",
+			Body: map[string]interface{}{"id": 1, "name": "kitty"}})
+	if !assert.Equal(t, []string{"request.path.petId# type"}, paths) {
+		t.Fatal()
+	}
+
+	paths = violationPaths(t, spec,
+		&Request{Method: "PUT", Path: "/v2/pets/1", ContentType: "application/json"},
+		&Response{StatusCode: 500, ContentType: "application/json",
+			Body: map[string]interface{}{"id": "1", "name": "kitty"}})
+	if !assert.ElementsMatch(t, []string{
+		"request.body required",
+		"response.body#/id type",
+	}, paths) {
+		t.Fatal()
+	}
+}
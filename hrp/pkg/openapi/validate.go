@@ -0,0 +1,309 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/httprunner/httprunner/v4/hrp/pkg/jsonschema"
+)
+
+// Request is the HTTP request to be validated.
+type Request struct {
+	Method      string
+	Path        string // URL path, e.g. /v1/users/1
+	ContentType string
+	Body        interface{} // decoded body, nil if request has no body
+}
+
+// Response is the HTTP response to be validated.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        interface{} // decoded body, raw string if body is not JSON
+}
+
+// Validate validates request and response against the matched operation in spec,
+// including path parameters, request body, response status and response body.
+// violation paths are prefixed with the location, e.g. request.body#/name or response.status.
+func (s *Spec) Validate(req *Request, resp *Response) (*Operation, []*jsonschema.Violation) {
+	op, pathParams := s.FindOperation(req.Method, req.Path)
+	if op == nil {
+		return nil, []*jsonschema.Violation{{
+			Path:    "request",
+			Keyword: "operation",
+			Message: fmt.Sprintf("operation %s %s is not documented", strings.ToUpper(req.Method), req.Path),
+		}}
+	}
+
+	var violations []*jsonschema.Violation
+	violations = append(violations, s.validatePathParams(op, pathParams)...)
+	violations = append(violations, s.validateRequestBody(op, req)...)
+	if resp != nil {
+		violations = append(violations, s.validateResponse(op, resp)...)
+	}
+	return op, violations
+}
+
+func (s *Spec) validatePathParams(op *Operation, pathParams map[string]string) (violations []*jsonschema.Violation) {
+	for _, param := range op.Parameters {
+		if param.In != "path" {
+			continue
+		}
+		location := "request.path." + param.Name
+		value, ok := pathParams[param.Name]
+		if !ok {
+			violations = append(violations, &jsonschema.Violation{
+				Path:    location,
+				Keyword: "required",
+				Message: fmt.Sprintf("missing path parameter %s", param.Name),
+			})
+			continue
+		}
+
+		// schema of parameter is in schema field for OpenAPI 3.x, or parameter itself for Swagger 2.0
+		var schema *jsonschema.Schema
+		var err error
+		paramSchema := param.Spec
+		if sch, ok := param.Spec["schema"].(map[string]interface{}); ok {
+			paramSchema = sch
+			schema, err = s.compile(param.pointer + "/schema")
+		} else {
+			schema, err = s.compileParameter(param)
+		}
+		if err != nil {
+			violations = append(violations, &jsonschema.Violation{
+				Path: location, Keyword: "schema", Message: err.Error(),
+			})
+			continue
+		}
+		typedValue := convertParamValue(value, s.schemaType(paramSchema))
+		violations = append(violations, prefixViolations(location, schema.Validate(typedValue))...)
+	}
+	return
+}
+
+func (s *Spec) validateRequestBody(op *Operation, req *Request) (violations []*jsonschema.Violation) {
+	var pointer string
+	required := false
+	if s.version == 2 {
+		for _, param := range op.Parameters {
+			if param.In == "body" {
+				pointer = param.pointer + "/schema"
+				required = param.Required
+			}
+		}
+	} else {
+		requestBodyPointer := op.pointer + "/requestBody"
+		requestBody, ok := op.Spec["requestBody"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if ref, ok := requestBody["$ref"].(string); ok {
+			resolved, err := s.resolve(ref)
+			if err != nil {
+				return []*jsonschema.Violation{{Path: "request.body", Keyword: "$ref", Message: err.Error()}}
+			}
+			requestBody, _ = resolved.(map[string]interface{})
+			requestBodyPointer = ref
+		}
+		required, _ = requestBody["required"].(bool)
+		content, _ := requestBody["content"].(map[string]interface{})
+		mediaType := selectMediaType(content, req.ContentType)
+		if mediaType == "" {
+			if req.Body != nil && len(content) > 0 {
+				return []*jsonschema.Violation{{
+					Path:    "request.body",
+					Keyword: "content",
+					Message: fmt.Sprintf("request content type %s is not documented", req.ContentType),
+				}}
+			}
+		} else if hasSchema(content[mediaType]) {
+			pointer = requestBodyPointer + "/content/" + jsonschema.EscapePointer(mediaType) + "/schema"
+		}
+	}
+
+	if req.Body == nil {
+		if required {
+			violations = append(violations, &jsonschema.Violation{
+				Path:    "request.body",
+				Keyword: "required",
+				Message: "request body is required",
+			})
+		}
+		return
+	}
+	if pointer == "" || !isJSON(req.ContentType, req.Body) {
+		return
+	}
+	schema, err := s.compile(pointer)
+	if err != nil {
+		return []*jsonschema.Violation{{Path: "request.body", Keyword: "schema", Message: err.Error()}}
+	}
+	return prefixViolations("request.body", schema.Validate(decodeBody(req.Body)))
+}
+
+func (s *Spec) validateResponse(op *Operation, resp *Response) (violations []*jsonschema.Violation) {
+	responses, _ := op.Spec["responses"].(map[string]interface{})
+	statusCode := strconv.Itoa(resp.StatusCode)
+	var key string
+	for _, candidate := range []string{statusCode, statusCode[:1] + "XX", statusCode[:1] + "xx", "default"} {
+		if _, ok := responses[candidate]; ok {
+			key = candidate
+			break
+		}
+	}
+	if key == "" {
+		var documented []string
+		for k := range responses {
+			documented = append(documented, k)
+		}
+		sort.Strings(documented)
+		return []*jsonschema.Violation{{
+			Path:    "response.status",
+			Keyword: "responses",
+			Message: fmt.Sprintf("response status %d is not documented, expected one of %v",
+				resp.StatusCode, documented),
+		}}
+	}
+
+	responsePointer := op.pointer + "/responses/" + jsonschema.EscapePointer(key)
+	response, _ := responses[key].(map[string]interface{})
+	if ref, ok := response["$ref"].(string); ok {
+		resolved, err := s.resolve(ref)
+		if err != nil {
+			return []*jsonschema.Violation{{Path: "response.body", Keyword: "$ref", Message: err.Error()}}
+		}
+		response, _ = resolved.(map[string]interface{})
+		responsePointer = ref
+	}
+
+	var pointer string
+	if s.version == 2 {
+		if _, ok := response["schema"]; ok {
+			pointer = responsePointer + "/schema"
+		}
+	} else {
+		content, _ := response["content"].(map[string]interface{})
+		mediaType := selectMediaType(content, resp.ContentType)
+		if mediaType != "" {
+			if hasSchema(content[mediaType]) {
+				pointer = responsePointer + "/content/" + jsonschema.EscapePointer(mediaType) + "/schema"
+			}
+		}
+	}
+	if pointer == "" || !isJSON(resp.ContentType, resp.Body) {
+		return
+	}
+	schema, err := s.compile(pointer)
+	if err != nil {
+		return []*jsonschema.Violation{{Path: "response.body", Keyword: "schema", Message: err.Error()}}
+	}
+	return prefixViolations("response.body", schema.Validate(decodeBody(resp.Body)))
+}
+
+// schemaType returns type of schema, $ref is resolved.
+func (s *Spec) schemaType(schema map[string]interface{}) string {
+	for i := 0; i < 10 && schema != nil; i++ {
+		if typ, ok := schema["type"].(string); ok {
+			return typ
+		}
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			break
+		}
+		resolved, err := s.resolve(ref)
+		if err != nil {
+			break
+		}
+		schema, _ = resolved.(map[string]interface{})
+	}
+	return ""
+}
+
+// selectMediaType selects the documented media type matching content type,
+// falls back to JSON media type or wildcard.
+func selectMediaType(content map[string]interface{}, contentType string) string {
+	if len(content) == 0 {
+		return ""
+	}
+	contentType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	if _, ok := content[contentType]; ok && contentType != "" {
+		return contentType
+	}
+	if contentType != "" {
+		if wildcard := strings.Split(contentType, "/")[0] + "/*"; content[wildcard] != nil {
+			return wildcard
+		}
+		if _, ok := content["*/*"]; ok {
+			return "*/*"
+		}
+		return ""
+	}
+	// content type not specified
+	for mediaType := range content {
+		if strings.Contains(mediaType, "json") {
+			return mediaType
+		}
+	}
+	return ""
+}
+
+func hasSchema(mediaType interface{}) bool {
+	m, ok := mediaType.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m["schema"]
+	return ok
+}
+
+func isJSON(contentType string, body interface{}) bool {
+	if contentType != "" {
+		return strings.Contains(contentType, "json")
+	}
+	_, isString := body.(string)
+	return !isString
+}
+
+// decodeBody decodes JSON string body, other values are returned as is.
+func decodeBody(body interface{}) interface{} {
+	switch v := body.(type) {
+	case string:
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(v), &decoded); err == nil {
+			return decoded
+		}
+	case []byte:
+		var decoded interface{}
+		if err := json.Unmarshal(v, &decoded); err == nil {
+			return decoded
+		}
+		return string(v)
+	}
+	return body
+}
+
+// convertParamValue converts path parameter string to the type declared in schema.
+func convertParamValue(value, typ string) interface{} {
+	switch typ {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func prefixViolations(location string, violations []*jsonschema.Violation) []*jsonschema.Violation {
+	for _, v := range violations {
+		v.Path = location + v.Path
+	}
+	return violations
+}
@@ -0,0 +1,266 @@
+package hrp
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/env"
+)
+
+// ReportWriter writes tests summary to report in specified format.
+type ReportWriter interface {
+	Format() string // report format name, e.g. json, html, junit, tap
+	Write(s *Summary) error
+}
+
+var (
+	reportWriters      = make(map[string]ReportWriter)
+	reportWritersMutex sync.RWMutex
+)
+
+func init() {
+	RegisterReportWriter(&jsonReportWriter{})
+	RegisterReportWriter(&htmlReportWriter{})
+	RegisterReportWriter(&junitReportWriter{})
+	RegisterReportWriter(&tapReportWriter{})
+}
+
+// RegisterReportWriter registers report writer, writer with the same format is overridden.
+func RegisterReportWriter(writer ReportWriter) {
+	reportWritersMutex.Lock()
+	defer reportWritersMutex.Unlock()
+	reportWriters[writer.Format()] = writer
+}
+
+func getReportWriter(format string) (ReportWriter, error) {
+	reportWritersMutex.RLock()
+	defer reportWritersMutex.RUnlock()
+	writer, ok := reportWriters[format]
+	if !ok {
+		return nil, errors.Errorf("unsupported report format: %s", format)
+	}
+	return writer, nil
+}
+
+// WriteReport writes summary to report in specified format.
+func (s *Summary) WriteReport(format string) error {
+	writer, err := getReportWriter(strings.ToLower(strings.TrimSpace(format)))
+	if err != nil {
+		return err
+	}
+	return writer.Write(s)
+}
+
+// ReportsDir returns the dir to store reports of current task execution.
+func (s *Summary) ReportsDir() string {
+	return filepath.Join(s.rootDir, env.ResultsDir)
+}
+
+type jsonReportWriter struct{}
+
+func (w *jsonReportWriter) Format() string { return "json" }
+
+func (w *jsonReportWriter) Write(s *Summary) error {
+	return s.genSummary()
+}
+
+type htmlReportWriter struct{}
+
+func (w *htmlReportWriter) Format() string { return "html" }
+
+func (w *htmlReportWriter) Write(s *Summary) error {
+	return s.genHTMLReport()
+}
+
+// JUnit XML report, one testsuite per testcase and one testcase per step.
+type junitTestSuites struct {
+	XMLName    xml.Name          `xml:"testsuites"`
+	Name       string            `xml:"name,attr"`
+	Tests      int               `xml:"tests,attr"`
+	Failures   int               `xml:"failures,attr"`
+	Skipped    int               `xml:"skipped,attr"`
+	Time       string            `xml:"time,attr"`
+	TestSuites []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	Timestamp string           `xml:"timestamp,attr,omitempty"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitReportWriter struct{}
+
+func (w *junitReportWriter) Format() string { return "junit" }
+
+func (w *junitReportWriter) Write(s *Summary) error {
+	suites := &junitTestSuites{
+		Name: "hrp",
+		Time: formatSeconds(s.Time.Duration),
+	}
+	for _, caseSummary := range s.Details {
+		suite := &junitTestSuite{
+			Name: caseSummary.Name,
+			Time: formatSeconds(caseSummary.Time.Duration),
+		}
+		if !caseSummary.Time.StartAt.IsZero() {
+			suite.Timestamp = caseSummary.Time.StartAt.Format("2006-01-02T15:04:05")
+		}
+		for _, record := range caseSummary.Records {
+			testCase := &junitTestCase{
+				Name:      record.Name,
+				ClassName: caseSummary.Name,
+				Time:      formatSeconds(float64(record.Elapsed) / 1000),
+			}
+			if record.Skipped {
+				testCase.Skipped = &junitSkipped{Message: fmt.Sprint(record.Attachments)}
+				suite.Skipped++
+			} else if !record.Success {
+				message, details := stepFailureMessage(record)
+				testCase.Failure = &junitFailure{
+					Message: message,
+					Type:    string(record.StepType),
+					Content: details,
+				}
+				suite.Failures++
+			}
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.TestSuites = append(suites.TestSuites, suite)
+	}
+
+	content, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal junit report failed")
+	}
+	return writeReportFile(s, "junit.xml", append([]byte(xml.Header), content...))
+}
+
+// TAP (Test Anything Protocol) version 13 report, one test point per step.
+type tapReportWriter struct{}
+
+func (w *tapReportWriter) Format() string { return "tap" }
+
+func (w *tapReportWriter) Write(s *Summary) error {
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	b.WriteString(fmt.Sprintf("1..%d\n", s.Stat.TestSteps.Total))
+	index := 0
+	for _, caseSummary := range s.Details {
+		for _, record := range caseSummary.Records {
+			index++
+			description := fmt.Sprintf("%s - %s", caseSummary.Name, record.Name)
+			switch {
+			case record.Skipped:
+				b.WriteString(fmt.Sprintf("ok %d - %s # SKIP %v\n", index, description, record.Attachments))
+			case record.Success:
+				b.WriteString(fmt.Sprintf("ok %d - %s # time=%dms\n", index, description, record.Elapsed))
+			default:
+				b.WriteString(fmt.Sprintf("not ok %d - %s # time=%dms\n", index, description, record.Elapsed))
+				message, details := stepFailureMessage(record)
+				b.WriteString("  ---\n")
+				b.WriteString(fmt.Sprintf("  message: %q\n", message))
+				b.WriteString(fmt.Sprintf("  step_type: %s\n", record.StepType))
+				if details != "" {
+					b.WriteString("  details: |\n")
+					for _, line := range strings.Split(strings.TrimRight(details, "\n"), "\n") {
+						b.WriteString("    " + line + "\n")
+					}
+				}
+				b.WriteString("  ...\n")
+			}
+		}
+	}
+	return writeReportFile(s, "report.tap", []byte(b.String()))
+}
+
+// stepFailureMessage returns failure message and details of failed step,
+// failed validators are preferred, otherwise the error message in attachments is used.
+func stepFailureMessage(record *StepResult) (message string, details string) {
+	var lines []string
+	if sessionData, ok := record.Data.(*SessionData); ok {
+		for _, v := range sessionData.Validators {
+			if v.CheckResult != "fail" {
+				continue
+			}
+			line := fmt.Sprintf("assert %s %s %v, got %v", v.Check, v.Assert, v.Expect, v.CheckValue)
+			if v.Message != "" {
+				line = fmt.Sprintf("%s (%s)", line, v.Message)
+			}
+			lines = append(lines, line)
+			for _, violation := range v.Violations {
+				lines = append(lines, "  "+violation.String())
+			}
+		}
+	}
+	if record.Attachments != nil {
+		if errMsg := fmt.Sprint(record.Attachments); errMsg != "" {
+			lines = append(lines, errMsg)
+		}
+	}
+	if len(lines) == 0 {
+		return "step failed", ""
+	}
+	return lines[0], strings.Join(lines, "\n")
+}
+
+func writeReportFile(s *Summary, name string, content []byte) error {
+	reportsDir := s.ReportsDir()
+	if err := builtin.EnsureFolderExists(reportsDir); err != nil {
+		return err
+	}
+	reportPath := filepath.Join(reportsDir, name)
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return errors.Wrap(err, "create report file failed")
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	if _, err := writer.Write(content); err != nil {
+		return errors.Wrap(err, "write report failed")
+	}
+	if err := writer.Flush(); err != nil {
+		return errors.Wrap(err, "write report failed")
+	}
+	log.Info().Str("path", reportPath).Msgf("generate %s report", strings.TrimPrefix(filepath.Ext(name), "."))
+	return nil
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
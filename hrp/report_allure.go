@@ -0,0 +1,319 @@
+package hrp
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+)
+
+func init() {
+	RegisterReportWriter(&allureReportWriter{})
+}
+
+// allure result statuses
+const (
+	allureStatusPassed  = "passed"
+	allureStatusFailed  = "failed"
+	allureStatusBroken  = "broken"
+	allureStatusSkipped = "skipped"
+)
+
+type allureResult struct {
+	UUID          string               `json:"uuid"`
+	HistoryID     string               `json:"historyId"`
+	Name          string               `json:"name"`
+	FullName      string               `json:"fullName"`
+	Status        string               `json:"status"`
+	StatusDetails *allureStatusDetails `json:"statusDetails,omitempty"`
+	Stage         string               `json:"stage"`
+	Start         int64                `json:"start"`
+	Stop          int64                `json:"stop"`
+	Labels        []*allureLabel       `json:"labels"`
+	Steps         []*allureStep        `json:"steps"`
+	Attachments   []*allureAttachment  `json:"attachments"`
+}
+
+type allureContainer struct {
+	UUID     string   `json:"uuid"`
+	Name     string   `json:"name"`
+	Children []string `json:"children"`
+	Start    int64    `json:"start"`
+	Stop     int64    `json:"stop"`
+}
+
+type allureStep struct {
+	Name          string               `json:"name"`
+	Status        string               `json:"status"`
+	StatusDetails *allureStatusDetails `json:"statusDetails,omitempty"`
+	Stage         string               `json:"stage"`
+	Start         int64                `json:"start"`
+	Stop          int64                `json:"stop"`
+	Steps         []*allureStep        `json:"steps"`
+	Attachments   []*allureAttachment  `json:"attachments"`
+}
+
+type allureStatusDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+// allureReportWriter exports summary to Allure results, which could be rendered by allure command line.
+// results are written to allure dir of runner, default to allure-results in reports dir.
+type allureReportWriter struct{}
+
+func (w *allureReportWriter) Format() string { return "allure" }
+
+func (w *allureReportWriter) Write(s *Summary) error {
+	resultsDir := s.allureDir
+	if resultsDir == "" {
+		resultsDir = filepath.Join(s.ReportsDir(), "allure-results")
+	}
+	if err := builtin.EnsureFolderExists(resultsDir); err != nil {
+		return err
+	}
+
+	// one container per testcase, parameterized sessions of testcase are children of container
+	containers := make(map[string]*allureContainer)
+	var containerNames []string
+	for _, caseSummary := range s.Details {
+		result, err := w.convertCaseSummary(resultsDir, caseSummary)
+		if err != nil {
+			return err
+		}
+		if err := writeAllureFile(resultsDir, result.UUID+"-result.json", result); err != nil {
+			return err
+		}
+
+		container, ok := containers[caseSummary.Name]
+		if !ok {
+			container = &allureContainer{
+				UUID:  uuid.NewV4().String(),
+				Name:  caseSummary.Name,
+				Start: result.Start,
+			}
+			containers[caseSummary.Name] = container
+			containerNames = append(containerNames, caseSummary.Name)
+		}
+		container.Children = append(container.Children, result.UUID)
+		if result.Stop > container.Stop {
+			container.Stop = result.Stop
+		}
+	}
+	for _, name := range containerNames {
+		container := containers[name]
+		if err := writeAllureFile(resultsDir, container.UUID+"-container.json", container); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Str("path", resultsDir).Msg("generate allure results")
+	return nil
+}
+
+func (w *allureReportWriter) convertCaseSummary(resultsDir string, caseSummary *TestCaseSummary) (*allureResult, error) {
+	start := caseSummary.Time.StartAt.UnixMilli()
+	result := &allureResult{
+		UUID:      uuid.NewV4().String(),
+		HistoryID: allureHistoryID(caseSummary),
+		Name:      caseSummary.Name,
+		FullName:  caseSummary.Name,
+		Status:    allureStatusPassed,
+		Stage:     "finished",
+		Start:     start,
+		Stop:      start + int64(caseSummary.Time.Duration*1000),
+		Labels: []*allureLabel{
+			{Name: "suite", Value: caseSummary.Name},
+			{Name: "framework", Value: "httprunner"},
+			{Name: "language", Value: "go"},
+		},
+		Steps:       []*allureStep{},
+		Attachments: []*allureAttachment{},
+	}
+	if !caseSummary.Success {
+		result.Status = allureStatusFailed
+	}
+
+	for _, record := range caseSummary.Records {
+		step, err := w.convertStepResult(resultsDir, record)
+		if err != nil {
+			return nil, err
+		}
+		if step.Status != allureStatusPassed && step.Status != allureStatusSkipped && result.StatusDetails == nil {
+			result.StatusDetails = &allureStatusDetails{
+				Message: fmt.Sprintf("step %s %s: %s", record.Name, step.Status, step.StatusDetails.Message),
+			}
+		}
+		result.Steps = append(result.Steps, step)
+	}
+	return result, nil
+}
+
+func (w *allureReportWriter) convertStepResult(resultsDir string, record *StepResult) (*allureStep, error) {
+	start := record.StartTime * 1000
+	step := &allureStep{
+		Name:        record.Name,
+		Status:      allureStatusPassed,
+		Stage:       "finished",
+		Start:       start,
+		Stop:        start + record.Elapsed,
+		Steps:       []*allureStep{},
+		Attachments: []*allureAttachment{},
+	}
+
+	switch {
+	case record.Skipped:
+		step.Status = allureStatusSkipped
+		step.StatusDetails = &allureStatusDetails{Message: fmt.Sprint(record.Attachments)}
+	case !record.Success:
+		message, details := stepFailureMessage(record)
+		step.Status = allureStatusBroken
+		// assertion failure is marked as failed, other errors are marked as broken
+		if sessionData, ok := record.Data.(*SessionData); ok {
+			for _, v := range sessionData.Validators {
+				if v.CheckResult == "fail" {
+					step.Status = allureStatusFailed
+					break
+				}
+			}
+		}
+		step.StatusDetails = &allureStatusDetails{Message: message, Trace: details}
+	}
+
+	// request and response attachments
+	if sessionData, ok := record.Data.(*SessionData); ok {
+		if sessionData.ReqResps != nil {
+			for _, item := range []struct {
+				name    string
+				content interface{}
+			}{
+				{"request", sessionData.ReqResps.Request},
+				{"response", sessionData.ReqResps.Response},
+			} {
+				if item.content == nil {
+					continue
+				}
+				attachment, err := writeAllureJSONAttachment(resultsDir, item.name, item.content)
+				if err != nil {
+					return nil, err
+				}
+				step.Attachments = append(step.Attachments, attachment)
+			}
+		}
+		if len(sessionData.Validators) > 0 {
+			attachment, err := writeAllureJSONAttachment(resultsDir, "validators", sessionData.Validators)
+			if err != nil {
+				return nil, err
+			}
+			step.Attachments = append(step.Attachments, attachment)
+		}
+	}
+
+	// screenshots of UI steps
+	for _, screenshot := range getScreenshots(record.Attachments) {
+		attachment, err := copyAllureFileAttachment(resultsDir, screenshot)
+		if err != nil {
+			log.Warn().Err(err).Str("path", screenshot).Msg("copy screenshot to allure results failed, ignore")
+			continue
+		}
+		step.Attachments = append(step.Attachments, attachment)
+	}
+	return step, nil
+}
+
+// allureHistoryID identifies the same test across runs, parameterized sessions are distinguished by config variables.
+func allureHistoryID(caseSummary *TestCaseSummary) string {
+	key := caseSummary.Name
+	if caseSummary.InOut != nil && len(caseSummary.InOut.ConfigVars) > 0 {
+		vars, _ := json.Marshal(caseSummary.InOut.ConfigVars)
+		key += string(vars)
+	}
+	hash := md5.Sum([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// getScreenshots returns screenshot paths stored in UI step attachments.
+func getScreenshots(attachments interface{}) []string {
+	attachmentsMap, ok := attachments.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	switch screenshots := attachmentsMap["screenshots"].(type) {
+	case []string:
+		return screenshots
+	case []interface{}:
+		var paths []string
+		for _, path := range screenshots {
+			if p, ok := path.(string); ok {
+				paths = append(paths, p)
+			}
+		}
+		return paths
+	}
+	return nil
+}
+
+func writeAllureJSONAttachment(resultsDir, name string, content interface{}) (*allureAttachment, error) {
+	source := uuid.NewV4().String() + "-attachment.json"
+	if err := writeAllureFile(resultsDir, source, content); err != nil {
+		return nil, err
+	}
+	return &allureAttachment{
+		Name:   name,
+		Source: source,
+		Type:   "application/json",
+	}, nil
+}
+
+func copyAllureFileAttachment(resultsDir, path string) (*allureAttachment, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(path)
+	source := uuid.NewV4().String() + "-attachment" + ext
+	if err := os.WriteFile(filepath.Join(resultsDir, source), content, 0o644); err != nil {
+		return nil, errors.Wrap(err, "write allure attachment failed")
+	}
+	mimeType := mime.TypeByExtension(ext)
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return &allureAttachment{
+		Name:   filepath.Base(path),
+		Source: source,
+		Type:   strings.Split(mimeType, ";")[0],
+	}, nil
+}
+
+func writeAllureFile(resultsDir, name string, content interface{}) error {
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal allure result failed")
+	}
+	if err := os.WriteFile(filepath.Join(resultsDir, name), data, 0o644); err != nil {
+		return errors.Wrap(err, "write allure result failed")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package hrp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReportSummary(t *testing.T) *Summary {
+	s := newOutSummary()
+	caseSummary := newSummary()
+	caseSummary.Name = "demo case"
+	caseSummary.Success = false
+	caseSummary.Records = []*StepResult{
+		{Name: "login", StepType: stepTypeRequest, Success: true, Elapsed: 120},
+		{Name: "get user", StepType: stepTypeRequest, Success: false, Elapsed: 35,
+			Data: &SessionData{Validators: []*ValidationResult{
+				{
+					Validator:   Validator{Check: "status_code", Assert: "equals", Expect: 200},
+					CheckValue:  200,
+					CheckResult: "pass",
+				},
+				{
+					Validator:   Validator{Check: "body.name", Assert: "equals", Expect: "leo", Message: "check name"},
+					CheckValue:  "debugtalk",
+					CheckResult: "fail",
+				},
+			}},
+			Attachments: "step validation failed",
+		},
+		{Name: "logout", StepType: stepTypeRequest, Skipped: true, Attachments: "run_if condition is false: $logout"},
+	}
+	caseSummary.Stat = &TestStepStat{Total: 3, Successes: 1, Failures: 1, Skipped: 1}
+	caseSummary.RootDir = t.TempDir()
+	s.appendCaseSummary(caseSummary)
+	return s
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	s := newTestReportSummary(t)
+	if !assert.Nil(t, s.WriteReport("junit")) {
+		t.Fatal()
+	}
+	content, err := os.ReadFile(filepath.Join(s.ReportsDir(), "junit.xml"))
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	suites := &junitTestSuites{}
+	if !assert.Nil(t, xml.Unmarshal(content, suites)) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, 3, suites.Tests) || !assert.Equal(t, 1, suites.Failures) || !assert.Equal(t, 1, suites.Skipped) {
+		t.Fatal()
+	}
+	testCases := suites.TestSuites[0].TestCases
+	if !assert.Equal(t, "0.120", testCases[0].Time) || !assert.Nil(t, testCases[0].Failure) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "assert body.name equals leo, got debugtalk (check name)", testCases[1].Failure.Message) {
+		t.Fatal()
+	}
+	if !assert.NotNil(t, testCases[2].Skipped) {
+		t.Fatal()
+	}
+}
+
+func TestWriteTAPReport(t *testing.T) {
+	s := newTestReportSummary(t)
+	if !assert.Nil(t, s.WriteReport("tap")) {
+		t.Fatal()
+	}
+	content, err := os.ReadFile(filepath.Join(s.ReportsDir(), "report.tap"))
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	lines := strings.Split(string(content), "\n")
+	if !assert.Equal(t, []string{
+		"TAP version 13",
+		"1..3",
+		"ok 1 - demo case - login # time=120ms",
+		"not ok 2 - demo case - get user # time=35ms",
+	}, lines[:4]) {
+		t.Fatal()
+	}
+	if !assert.Contains(t, string(content), "ok 3 - demo case - logout # SKIP run_if condition is false: $logout") {
+		t.Fatal()
+	}
+
+	if !assert.Error(t, s.WriteReport("unknown")) {
+		t.Fatal()
+	}
+}
+
+func TestWriteAllureReport(t *testing.T) {
+	s := newTestReportSummary(t)
+	screenshot := filepath.Join(t.TempDir(), "screenshot.png")
+	if !assert.Nil(t, os.WriteFile(screenshot, []byte("png"), 0o644)) {
+		t.Fatal()
+	}
+	records := s.Details[0].Records
+	records[0].Attachments = map[string]interface{}{"screenshots": []string{screenshot}}
+	records[1].Data.(*SessionData).ReqResps = &ReqResps{
+		Request:  map[string]interface{}{"method": "GET", "url": "https://httpbin.org/get"},
+		Response: map[string]interface{}{"status_code": 200},
+	}
+
+	resultsDir := filepath.Join(t.TempDir(), "allure-results")
+	s.allureDir = resultsDir
+	if !assert.Nil(t, s.WriteReport("allure")) {
+		t.Fatal()
+	}
+
+	files, _ := filepath.Glob(filepath.Join(resultsDir, "*-result.json"))
+	if !assert.Len(t, files, 1) {
+		t.Fatal()
+	}
+	content, _ := os.ReadFile(files[0])
+	result := &allureResult{}
+	if !assert.Nil(t, json.Unmarshal(content, result)) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "demo case", result.Name) || !assert.Equal(t, allureStatusFailed, result.Status) {
+		t.Fatal()
+	}
+	if !assert.Len(t, result.Steps, 3) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, allureStatusPassed, result.Steps[0].Status) ||
+		!assert.Equal(t, allureStatusFailed, result.Steps[1].Status) ||
+		!assert.Equal(t, allureStatusSkipped, result.Steps[2].Status) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "assert body.name equals leo, got debugtalk (check name)", result.Steps[1].StatusDetails.Message) {
+		t.Fatal()
+	}
+
+	// screenshot is copied as attachment
+	if !assert.Len(t, result.Steps[0].Attachments, 1) || !assert.Equal(t, "image/png", result.Steps[0].Attachments[0].Type) {
+		t.Fatal()
+	}
+	if !assert.FileExists(t, filepath.Join(resultsDir, result.Steps[0].Attachments[0].Source)) {
+		t.Fatal()
+	}
+	// request, response and validators are attached
+	var names []string
+	for _, attachment := range result.Steps[1].Attachments {
+		names = append(names, attachment.Name)
+		if !assert.FileExists(t, filepath.Join(resultsDir, attachment.Source)) {
+			t.Fatal()
+		}
+	}
+	if !assert.Equal(t, []string{"request", "response", "validators"}, names) {
+		t.Fatal()
+	}
+
+	containers, _ := filepath.Glob(filepath.Join(resultsDir, "*-container.json"))
+	if !assert.Len(t, containers, 1) {
+		t.Fatal()
+	}
+	content, _ = os.ReadFile(containers[0])
+	container := &allureContainer{}
+	if !assert.Nil(t, json.Unmarshal(content, container)) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, []string{result.UUID}, container.Children) {
+		t.Fatal()
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/jmespath/go-jmespath"
@@ -16,10 +20,13 @@ import (
 
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
 	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/jsonschema"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/openapi"
 	"github.com/httprunner/httprunner/v4/hrp/pkg/uixt"
 )
 
-var fieldTags = []string{"proto", "status_code", "headers", "cookies", "body", textExtractorSubRegexp}
+var fieldTags = []string{"proto", "status_code", "headers", "cookies", "body", "events", "stop_reason",
+	"status_message", "trailers", textExtractorSubRegexp}
 
 type httpRespObjMeta struct {
 	Proto      string            `json:"proto"`
@@ -30,6 +37,24 @@ type httpRespObjMeta struct {
 }
 
 func newHttpResponseObject(t *testing.T, parser *Parser, resp *http.Response) (*responseObject, error) {
+	// read response body
+	respBodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse response body
+	var body interface{}
+	if err := json.Unmarshal(respBodyBytes, &body); err != nil {
+		// response body is not json, use raw body
+		body = string(respBodyBytes)
+	}
+
+	respObjMeta := newHttpRespObjMeta(resp, body)
+	return convertToResponseObject(t, parser, respObjMeta)
+}
+
+func newHttpRespObjMeta(resp *http.Response, body interface{}) httpRespObjMeta {
 	// prepare response headers
 	headers := make(map[string]string)
 	for k, v := range resp.Header {
@@ -44,28 +69,13 @@ func newHttpResponseObject(t *testing.T, parser *Parser, resp *http.Response) (*
 		cookies[cookie.Name] = cookie.Value
 	}
 
-	// read response body
-	respBodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	// parse response body
-	var body interface{}
-	if err := json.Unmarshal(respBodyBytes, &body); err != nil {
-		// response body is not json, use raw body
-		body = string(respBodyBytes)
-	}
-
-	respObjMeta := httpRespObjMeta{
+	return httpRespObjMeta{
 		Proto:      resp.Proto,
 		StatusCode: resp.StatusCode,
 		Headers:    headers,
 		Cookies:    cookies,
 		Body:       body,
 	}
-
-	return convertToResponseObject(t, parser, respObjMeta)
 }
 
 type wsCloseRespObject struct {
@@ -120,6 +130,12 @@ type responseObject struct {
 	parser            *Parser
 	respObjMeta       interface{}
 	validationResults []*ValidationResult
+	rootDir           string // project root dir, used to locate JSON schema files
+	snapshotDir       string // dir to store snapshots of current testcase
+	snapshotSuffix    string // appended to snapshot name, distinguishes parameters and foreach iterations
+	updateSnapshots   bool   // rewrite snapshots with current response
+	snapshotReadOnly  bool   // do not create snapshot files, used when probing response
+	graphql           bool   // response of GraphQL request, data and errors could be searched directly
 }
 
 const textExtractorSubRegexp string = `(.*)`
@@ -135,7 +151,7 @@ func (v *responseObject) searchField(field string, variablesMapping map[string]i
 		}
 	}
 	// search field using jmespath or regex if parsed field is still string and contains specified fieldTags
-	if parsedField, ok := result.(string); ok && checkSearchField(parsedField) {
+	if parsedField, ok := result.(string); ok && (checkSearchField(parsedField) || v.graphql && checkGraphQLField(parsedField)) {
 		if strings.Contains(field, textExtractorSubRegexp) {
 			result = v.searchRegexp(parsedField)
 		} else {
@@ -179,9 +195,14 @@ func (v *responseObject) Validate(iValidators []interface{}, variablesMapping ma
 		}
 
 		// parse expected value
-		expectValue, err := v.parser.Parse(validator.Expect, variablesMapping)
-		if err != nil {
-			return err
+		// inline JSON schema is not parsed since it may contain keywords like $ref
+		isJSONSchema := assertMethod == "assert_json_schema" || assertMethod == "json_schema"
+		expectValue := validator.Expect
+		if !isJSONSchema || isJSONSchemaPath(expectValue) {
+			expectValue, err = v.parser.Parse(validator.Expect, variablesMapping)
+			if err != nil {
+				return err
+			}
 		}
 		validResult := &ValidationResult{
 			Validator: Validator{
@@ -194,10 +215,28 @@ func (v *responseObject) Validate(iValidators []interface{}, variablesMapping ma
 			CheckResult: "fail",
 		}
 
+		// load JSON schema from file if expected value is schema path
+		assertExpect := expectValue
+		if isJSONSchema {
+			assertExpect, err = v.loadJSONSchema(expectValue)
+			if err != nil {
+				return err
+			}
+		}
+		// load stored snapshot, create it if not exists
+		if assertMethod == "snapshot" {
+			assertExpect, err = v.loadSnapshot(checkValue, expectValue)
+			if err != nil {
+				return err
+			}
+		}
+
 		// do assertion
-		result := assertFunc(v.t, checkValue, expectValue)
+		result := assertFunc(v.t, checkValue, assertExpect)
 		if result {
 			validResult.CheckResult = "pass"
+		} else if isJSONSchema {
+			validResult.Violations, _ = builtin.ValidateJSONSchema(checkValue, assertExpect)
 		}
 		v.validationResults = append(v.validationResults, validResult)
 		log.Info().
@@ -225,6 +264,162 @@ func (v *responseObject) Validate(iValidators []interface{}, variablesMapping ma
 	return nil
 }
 
+// loadJSONSchema loads JSON schema file relative to project root dir,
+// inline schema object or JSON string is returned as is.
+func (v *responseObject) loadJSONSchema(schema interface{}) (interface{}, error) {
+	if !isJSONSchemaPath(schema) {
+		return schema, nil
+	}
+	schemaPath := schema.(string)
+	if !filepath.IsAbs(schemaPath) {
+		schemaPath = filepath.Join(v.rootDir, schemaPath)
+	}
+	compiled, err := jsonschema.LoadFile(schemaPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "load JSON schema failed")
+	}
+	return compiled, nil
+}
+
+// ValidateOpenAPI validates request and response against the matching operation in OpenAPI spec,
+// the result is appended to validation results as an extra validator.
+func (v *responseObject) ValidateOpenAPI(spec *openapi.Spec, req *openapi.Request) error {
+	meta, ok := v.respObjMeta.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	statusCode, _ := strconv.Atoi(convertString(meta["status_code"]))
+	headers, _ := meta["headers"].(map[string]interface{})
+	contentType, _ := headers["Content-Type"].(string)
+	op, violations := spec.Validate(req, &openapi.Response{
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        meta["body"],
+	})
+
+	var operation string
+	if op != nil {
+		operation = fmt.Sprintf("%s %s", op.Method, op.Path)
+	}
+	validResult := &ValidationResult{
+		Validator: Validator{
+			Check:   "openapi",
+			Assert:  "openapi_contract",
+			Expect:  operation,
+			Message: "validate request and response against OpenAPI spec",
+		},
+		CheckValue:  fmt.Sprintf("%s %s %d", req.Method, req.Path, statusCode),
+		CheckResult: "pass",
+		Violations:  violations,
+	}
+	v.validationResults = append(v.validationResults, validResult)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	validResult.CheckResult = "fail"
+	v.t.Fail()
+	for _, violation := range violations {
+		log.Error().Str("operation", operation).
+			Str("path", violation.Path).
+			Str("keyword", violation.Keyword).
+			Msg(violation.Message)
+	}
+	return errors.New("openapi contract validation failed")
+}
+
+var snapshotMutex sync.Mutex
+
+// loadSnapshot loads the stored snapshot, expect is snapshot name or map with name and ignore paths.
+// snapshot file is created with actual value on first run or when updating snapshots.
+func (v *responseObject) loadSnapshot(actual, expect interface{}) (*builtin.Snapshot, error) {
+	snapshot := &builtin.Snapshot{}
+	switch e := expect.(type) {
+	case string:
+		snapshot.Name = e
+	case map[string]interface{}:
+		snapshot.Name = convertString(e["name"])
+		switch ignore := e["ignore"].(type) {
+		case []string:
+			snapshot.Ignore = ignore
+		case []interface{}:
+			for _, path := range ignore {
+				snapshot.Ignore = append(snapshot.Ignore, convertString(path))
+			}
+		}
+	default:
+		return nil, errors.Errorf("invalid snapshot expect value: %v", expect)
+	}
+	if snapshot.Name == "" {
+		return nil, errors.New("snapshot name is empty")
+	}
+	if v.snapshotDir == "" {
+		return nil, errors.New("snapshot dir is not set")
+	}
+
+	snapshotPath := filepath.Join(v.snapshotDir, sanitizeFileName(snapshot.Name+v.snapshotSuffix)+".json")
+	snapshotMutex.Lock()
+	defer snapshotMutex.Unlock()
+
+	if !v.updateSnapshots && builtin.IsFilePathExists(snapshotPath) {
+		content, err := os.ReadFile(snapshotPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "read snapshot failed")
+		}
+		if err := json.Unmarshal(content, &snapshot.Value); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal snapshot %s failed", snapshotPath)
+		}
+		return snapshot, nil
+	}
+
+	snapshot.Value = actual
+	if v.snapshotReadOnly {
+		return snapshot, nil
+	}
+	content, err := json.MarshalIndent(actual, "", "    ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal snapshot failed")
+	}
+	if err := os.MkdirAll(v.snapshotDir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "create snapshot dir failed")
+	}
+	if err := os.WriteFile(snapshotPath, content, 0o644); err != nil {
+		return nil, errors.Wrap(err, "write snapshot failed")
+	}
+	log.Info().Str("path", snapshotPath).Msg("save response snapshot")
+	return snapshot, nil
+}
+
+// sanitizeFileName replaces characters invalid in file name with underscore.
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>| `, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func isJSONSchemaPath(schema interface{}) bool {
+	schemaPath, ok := schema.(string)
+	return ok && !strings.HasPrefix(strings.TrimSpace(schemaPath), "{")
+}
+
+// check reports whether all validators pass without recording validation results or failing the test.
+func (v *responseObject) check(iValidators []interface{}, variablesMapping map[string]interface{}) bool {
+	probe := &responseObject{
+		t:                &testing.T{},
+		parser:           v.parser,
+		respObjMeta:      v.respObjMeta,
+		rootDir:          v.rootDir,
+		snapshotDir:      v.snapshotDir,
+		snapshotSuffix:   v.snapshotSuffix,
+		snapshotReadOnly: true,
+		graphql:          v.graphql,
+	}
+	return probe.Validate(iValidators, variablesMapping) == nil
+}
+
 func checkSearchField(expr string) bool {
 	for _, t := range fieldTags {
 		if strings.Contains(expr, t) {
@@ -234,8 +429,21 @@ func checkSearchField(expr string) bool {
 	return false
 }
 
+func checkGraphQLField(expr string) bool {
+	for _, t := range graphqlFieldTags {
+		if strings.Contains(expr, t) {
+			return true
+		}
+	}
+	return false
+}
+
 func (v *responseObject) searchJmespath(expr string) interface{} {
-	checkValue, err := jmespath.Search(expr, v.respObjMeta)
+	respObjMeta := v.respObjMeta
+	if v.graphql {
+		respObjMeta = v.graphqlRespObjMeta()
+	}
+	checkValue, err := jmespath.Search(expr, respObjMeta)
 	if err != nil {
 		log.Error().Str("expr", expr).Err(err).Msg("search jmespath failed")
 		return expr // jmespath not found, return the expression
@@ -0,0 +1,197 @@
+package hrp
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/env"
+)
+
+type ResponseMode string
+
+const (
+	ResponseModeBuffer  ResponseMode = "buffer"  // default, read whole body into memory and parse JSON
+	ResponseModeStream  ResponseMode = "stream"  // read body in chunks, only keep size, hashes and preview
+	ResponseModeFile    ResponseMode = "file"    // stream body to file, keep size, hashes, preview and saved path
+	ResponseModeDiscard ResponseMode = "discard" // read and drop body, only keep size
+)
+
+// defaultBodyPreviewSize is max bytes of body preview kept in streamed response object and report
+const defaultBodyPreviewSize = 1024
+
+// streamedRespObjMeta represents response of streamed body, body field is a truncated preview.
+type streamedRespObjMeta struct {
+	httpRespObjMeta
+	BodySize   int64  `json:"body_size"`
+	BodySHA256 string `json:"body_sha256,omitempty"`
+	BodyMD5    string `json:"body_md5,omitempty"`
+	BodyPath   string `json:"body_path,omitempty"`
+}
+
+// responseBodyOptions represents how to read response body of request step.
+type responseBodyOptions struct {
+	mode          ResponseMode
+	path          string // file path to save body in file mode
+	maxBufferSize int64  // max body bytes buffered in memory, body preview size for streamed modes
+}
+
+func (o *responseBodyOptions) isStreamed() bool {
+	return o.mode == ResponseModeStream || o.mode == ResponseModeFile || o.mode == ResponseModeDiscard
+}
+
+// newResponseBodyOptions returns response body options of step request, file path is resolved relative to rootDir.
+func newResponseBodyOptions(request *Request, stepName, rootDir string, parser *Parser,
+	variables map[string]interface{},
+) (*responseBodyOptions, error) {
+	options := &responseBodyOptions{
+		mode:          request.ResponseMode,
+		maxBufferSize: request.MaxBufferSize,
+	}
+	switch options.mode {
+	case "", ResponseModeBuffer:
+		options.mode = ResponseModeBuffer
+		return options, nil
+	case ResponseModeStream, ResponseModeDiscard:
+	case ResponseModeFile:
+		if request.ResponseFile == "" {
+			options.path = filepath.Join(rootDir, env.ResultsDir, "downloads",
+				fmt.Sprintf("%s_%d", sanitizeFileName(stepName), time.Now().UnixNano()))
+			break
+		}
+		path, err := parser.ParseString(request.ResponseFile, variables)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse response file path failed")
+		}
+		options.path = resolvePath(rootDir, convertString(path))
+	default:
+		return nil, errors.Errorf("unsupported response mode: %s", options.mode)
+	}
+	if options.maxBufferSize <= 0 {
+		options.maxBufferSize = defaultBodyPreviewSize
+	}
+	return options, nil
+}
+
+// limitedBody fails reading if body exceeds max buffer size, avoid reading huge body into memory.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func newLimitedBody(body io.ReadCloser, limit int64) *limitedBody {
+	return &limitedBody{ReadCloser: body, remaining: limit, limit: limit}
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, b.exceededError()
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, b.exceededError()
+	}
+	return n, err
+}
+
+func (b *limitedBody) exceededError() error {
+	return errors.Errorf("response body exceeds max_buffer_size %d bytes, "+
+		"set response_mode to stream, file or discard for large body", b.limit)
+}
+
+// newStreamedHttpResponseObject reads response body in chunks according to response mode,
+// body is hashed with sha256 and md5 and saved to file in file mode, only a truncated preview is kept.
+func newStreamedHttpResponseObject(t *testing.T, parser *Parser, resp *http.Response,
+	options *responseBodyOptions,
+) (*responseObject, int64, error) {
+	meta := streamedRespObjMeta{
+		httpRespObjMeta: newHttpRespObjMeta(resp, nil),
+	}
+
+	var writers []io.Writer
+	var sha256Hash, md5Hash hash.Hash
+	preview := &previewWriter{limit: options.maxBufferSize}
+	if options.mode != ResponseModeDiscard {
+		sha256Hash, md5Hash = sha256.New(), md5.New()
+		writers = append(writers, sha256Hash, md5Hash, preview)
+	}
+	if options.mode == ResponseModeFile {
+		if err := os.MkdirAll(filepath.Dir(options.path), 0o755); err != nil {
+			return nil, 0, errors.Wrap(err, "create response file dir failed")
+		}
+		file, err := os.Create(options.path)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "create response file failed")
+		}
+		defer file.Close()
+		writers = append(writers, file)
+		meta.BodyPath = options.path
+	}
+
+	size, err := io.Copy(io.MultiWriter(writers...), resp.Body)
+	if err != nil {
+		return nil, size, errors.Wrap(err, "read response body failed")
+	}
+	meta.BodySize = size
+	if sha256Hash != nil {
+		meta.BodySHA256 = hex.EncodeToString(sha256Hash.Sum(nil))
+		meta.BodyMD5 = hex.EncodeToString(md5Hash.Sum(nil))
+		meta.Body = preview.String(size)
+	}
+	log.Info().Str("mode", string(options.mode)).Int64("size", size).
+		Str("path", meta.BodyPath).Msg("read streamed response body")
+
+	respObj, err := convertToResponseObject(t, parser, meta)
+	return respObj, size, err
+}
+
+// previewWriter keeps the first limit bytes written.
+type previewWriter struct {
+	limit int64
+	buf   []byte
+}
+
+func (w *previewWriter) Write(p []byte) (int, error) {
+	if remaining := w.limit - int64(len(w.buf)); remaining > 0 {
+		if int64(len(p)) > remaining {
+			w.buf = append(w.buf, p[:remaining]...)
+		} else {
+			w.buf = append(w.buf, p...)
+		}
+	}
+	return len(p), nil
+}
+
+// String returns body preview, binary body is not converted to string.
+func (w *previewWriter) String(size int64) string {
+	preview := w.buf
+	truncated := int64(len(w.buf)) < size
+	// drop incomplete utf-8 character at the end of truncated preview
+	for i := 1; truncated && i < utf8.UTFMax && len(preview) > 1 && !utf8.Valid(preview); i++ {
+		preview = preview[:len(preview)-1]
+	}
+	if !utf8.Valid(preview) {
+		return fmt.Sprintf("(binary body, %d bytes)", size)
+	}
+	if truncated {
+		return fmt.Sprintf("%s...(truncated, %d bytes)", preview, size)
+	}
+	return string(preview)
+}
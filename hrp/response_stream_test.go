@@ -0,0 +1,121 @@
+package hrp
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewWriter(t *testing.T) {
+	w := &previewWriter{limit: 8}
+	w.Write([]byte("hello, "))
+	w.Write([]byte("世界"))
+	// truncated in the middle of multi-byte character
+	if !assert.Equal(t, "hello, ...(truncated, 13 bytes)", w.String(13)) {
+		t.Fatal()
+	}
+
+	w = &previewWriter{limit: 8}
+	w.Write([]byte{0xff, 0xfe, 0x00, 0x01})
+	if !assert.Equal(t, "(binary body, 4 bytes)", w.String(4)) {
+		t.Fatal()
+	}
+
+	w = &previewWriter{limit: 8}
+	w.Write([]byte("hello"))
+	if !assert.Equal(t, "hello", w.String(5)) {
+		t.Fatal()
+	}
+}
+
+func TestRunRequestWithResponseMode(t *testing.T) {
+	binary := make([]byte, 1<<20)
+	for i := range binary {
+		binary[i] = byte(i % 251)
+	}
+	sha256Sum := sha256.Sum256(binary)
+	md5Sum := md5.Sum(binary)
+	text := strings.Repeat("httprunner ", 100)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.Trim(r.URL.Path, "/") {
+		case "binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(binary)
+		case "text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(text))
+		}
+	}))
+	defer ts.Close()
+
+	responseFile := filepath.Join(t.TempDir(), "download.bin")
+	testcase := &TestCase{
+		Config: NewConfig("response mode").
+			SetBaseURL(ts.URL).
+			WithVariables(map[string]interface{}{"response_file": responseFile}),
+		TestSteps: []IStep{
+			NewStep("stream binary").
+				GET("/binary").
+				SetResponseMode(ResponseModeStream, 0).
+				Validate().
+				AssertEqual("status_code", 200, "check status code").
+				AssertEqual("body_size", 1<<20, "check body size").
+				AssertEqual("body_sha256", hex.EncodeToString(sha256Sum[:]), "check body sha256").
+				AssertEqual("body_md5", hex.EncodeToString(md5Sum[:]), "check body md5").
+				AssertEqual("body", "(binary body, 1048576 bytes)", "check body preview"),
+			NewStep("save binary to file").
+				GET("/binary").
+				SaveResponseFile("$response_file").
+				Validate().
+				AssertEqual("body_size", 1<<20, "check body size").
+				AssertEqual("body_path", responseFile, "check body path"),
+			NewStep("discard text").
+				GET("/text").
+				SetResponseMode(ResponseModeDiscard, 0).
+				Validate().
+				AssertEqual("body_size", len(text), "check body size").
+				AssertEqual("body", nil, "check body dropped"),
+			NewStep("stream text with preview").
+				GET("/text").
+				SetResponseMode(ResponseModeStream, 10).
+				Validate().
+				AssertEqual("body", "httprunner...(truncated, 1100 bytes)", "check body preview"),
+		},
+	}
+	caseRunner, _ := NewRunner(t).NewCaseRunner(testcase)
+	sessionRunner := caseRunner.NewSession()
+	if err := sessionRunner.Start(nil); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	if !assert.Equal(t, int64(1<<20), summary.Records[0].ContentSize) {
+		t.Fatal()
+	}
+	content, err := os.ReadFile(responseFile)
+	if !assert.Nil(t, err) || !assert.Equal(t, binary, content) {
+		t.Fatal()
+	}
+
+	// body exceeds max buffer size in buffer mode
+	testcase = &TestCase{
+		Config: NewConfig("max buffer size").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("buffer text").
+				GET("/text").
+				SetResponseMode(ResponseModeBuffer, 100),
+		},
+	}
+	err = NewRunner(t).Run(testcase)
+	if !assert.Error(t, err) || !assert.Contains(t, err.Error(), "exceeds max_buffer_size") {
+		t.Fatal()
+	}
+}
@@ -1,6 +1,7 @@
 package hrp
 
 import (
+	"context"
 	"crypto/tls"
 	_ "embed"
 	"fmt"
@@ -11,7 +12,10 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
@@ -21,12 +25,17 @@ import (
 	"github.com/jinzhu/copier"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/vektah/gqlparser/v2/ast"
 	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoregistry"
 
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
 	"github.com/httprunner/httprunner/v4/hrp/internal/code"
+	"github.com/httprunner/httprunner/v4/hrp/internal/env"
 	"github.com/httprunner/httprunner/v4/hrp/internal/sdk"
 	"github.com/httprunner/httprunner/v4/hrp/internal/version"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/openapi"
 	"github.com/httprunner/httprunner/v4/hrp/pkg/uixt"
 )
 
@@ -67,25 +76,37 @@ func NewRunner(t *testing.T) *HRPRunner {
 		wsDialer: &websocket.Dialer{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		},
-		caseTimeoutTimer: time.NewTimer(time.Hour * 2), // default case timeout to 2 hour
-		interruptSignal:  interruptSignal,
+		caseTimeout:     time.Hour * 2, // default case timeout to 2 hour
+		interruptSignal: interruptSignal,
+		concurrency:     1, // default to run testcases one by one
 	}
 }
 
 type HRPRunner struct {
-	t                *testing.T
-	failfast         bool
-	httpStatOn       bool
-	requestsLogOn    bool
-	pluginLogOn      bool
-	venv             string
-	saveTests        bool
-	genHTMLReport    bool
-	httpClient       *http.Client
-	http2Client      *http.Client
-	wsDialer         *websocket.Dialer
-	caseTimeoutTimer *time.Timer    // case timeout timer
-	interruptSignal  chan os.Signal // interrupt signal channel
+	t               *testing.T
+	failfast        bool
+	httpStatOn      bool
+	requestsLogOn   bool
+	pluginLogOn     bool
+	venv            string
+	saveTests       bool
+	genHTMLReport   bool
+	httpClient      *http.Client
+	http2Client     *http.Client
+	wsDialer        *websocket.Dialer
+	caseTimeout     time.Duration           // default testcase timeout, timer is started by each session runner
+	interruptSignal chan os.Signal          // interrupt signal channel
+	concurrency     int                     // max number of session runners running at the same time
+	updateSnapshots bool                    // rewrite response snapshots instead of comparing
+	reportFormats   []string                // report formats to generate, e.g. json, html, junit, tap
+	allureDir       string                  // dir to export allure results
+	clients         map[string]*httpClients // http clients for profiles, e.g. mutual TLS or proxies
+	clientsMutex    sync.Mutex
+	grpcConns       map[string]*grpc.ClientConn     // grpc connections shared by session runners
+	grpcFiles       map[string]*protoregistry.Files // grpc file descriptors of proto files or server reflection
+	grpcMutex       sync.Mutex
+	graphqlSchemas  map[string]*ast.Schema // graphql schemas loaded by introspection
+	graphqlMutex    sync.Mutex
 }
 
 // SetClientTransport configures transport of http client for high concurrency load testing
@@ -111,6 +132,13 @@ func (r *HRPRunner) SetClientTransport(maxConns int, disableKeepAlive bool, disa
 	return r
 }
 
+// SetUpdateSnapshots configures whether to rewrite response snapshots with current responses.
+func (r *HRPRunner) SetUpdateSnapshots(update bool) *HRPRunner {
+	log.Info().Bool("updateSnapshots", update).Msg("[init] SetUpdateSnapshots")
+	r.updateSnapshots = update
+	return r
+}
+
 // SetFailfast configures whether to stop running when one step fails.
 func (r *HRPRunner) SetFailfast(failfast bool) *HRPRunner {
 	log.Info().Bool("failfast", failfast).Msg("[init] SetFailfast")
@@ -172,7 +200,7 @@ func (r *HRPRunner) SetRequestTimeout(seconds float32) *HRPRunner {
 // SetCaseTimeout configures global testcase timeout in seconds.
 func (r *HRPRunner) SetCaseTimeout(seconds float32) *HRPRunner {
 	log.Info().Float32("timeout_seconds", seconds).Msg("[init] SetCaseTimeout")
-	r.caseTimeoutTimer = time.NewTimer(time.Duration(seconds*1000) * time.Millisecond)
+	r.caseTimeout = time.Duration(seconds*1000) * time.Millisecond
 	return r
 }
 
@@ -183,6 +211,18 @@ func (r *HRPRunner) SetSaveTests(saveTests bool) *HRPRunner {
 	return r
 }
 
+// SetConcurrency configures the max number of testcases (including parameters iterations)
+// running at the same time, each one runs in its own session runner.
+func (r *HRPRunner) SetConcurrency(n int) *HRPRunner {
+	log.Info().Int("concurrency", n).Msg("[init] SetConcurrency")
+	if n < 1 {
+		log.Warn().Int("concurrency", n).Msg("concurrency should be positive, set to 1")
+		n = 1
+	}
+	r.concurrency = n
+	return r
+}
+
 // GenHTMLReport configures whether to gen html report of api tests.
 func (r *HRPRunner) GenHTMLReport() *HRPRunner {
 	log.Info().Bool("genHTMLReport", true).Msg("[init] SetgenHTMLReport")
@@ -190,6 +230,22 @@ func (r *HRPRunner) GenHTMLReport() *HRPRunner {
 	return r
 }
 
+// SetReportFormats configures report formats to generate after running, e.g. json, html, junit, tap.
+// custom formats could be added with RegisterReportWriter.
+func (r *HRPRunner) SetReportFormats(formats ...string) *HRPRunner {
+	log.Info().Strs("formats", formats).Msg("[init] SetReportFormats")
+	r.reportFormats = formats
+	return r
+}
+
+// SetAllureDir configures dir to export allure results after running,
+// the results could be rendered by allure command line, e.g. allure serve <dir>.
+func (r *HRPRunner) SetAllureDir(dir string) *HRPRunner {
+	log.Info().Str("allureDir", dir).Msg("[init] SetAllureDir")
+	r.allureDir = dir
+	return r
+}
+
 // Run starts to execute one or multiple testcases.
 func (r *HRPRunner) Run(testcases ...ITestCase) (err error) {
 	log.Info().Str("hrp_version", version.VERSION).Msg("start running")
@@ -223,8 +279,18 @@ func (r *HRPRunner) Run(testcases ...ITestCase) (err error) {
 		})
 	}()
 
-	var runErr error
-	// run testcase one by one
+	// close grpc connections shared by sessions
+	defer r.closeGRPCConns()
+
+	// check report formats before running
+	for _, format := range r.getReportFormats() {
+		if _, err := getReportWriter(format); err != nil {
+			return err
+		}
+	}
+
+	// init case runners in sequential order
+	caseRunners := make([]*CaseRunner, 0, len(testCases))
 	for _, testcase := range testCases {
 		// each testcase has its own case runner
 		caseRunner, err := r.NewCaseRunner(testcase)
@@ -232,6 +298,7 @@ func (r *HRPRunner) Run(testcases ...ITestCase) (err error) {
 			log.Error().Err(err).Msg("[Run] init case runner failed")
 			return err
 		}
+		caseRunners = append(caseRunners, caseRunner)
 
 		// release UI driver session
 		defer func() {
@@ -239,58 +306,132 @@ func (r *HRPRunner) Run(testcases ...ITestCase) (err error) {
 				client.Driver.DeleteSession()
 			}
 		}()
+	}
 
-		for it := caseRunner.parametersIterator; it.HasNext(); {
-			// case runner can run multiple times with different parameters
-			// each run has its own session runner
-			sessionRunner := caseRunner.NewSession()
-			err1 := sessionRunner.Start(it.Next())
-			if err1 != nil {
-				log.Error().Err(err1).Msg("[Run] run testcase failed")
-				runErr = err1
-			}
-			caseSummary, err2 := sessionRunner.GetSummary()
-			s.appendCaseSummary(caseSummary)
-			if err2 != nil {
-				log.Error().Err(err2).Msg("[Run] get summary failed")
-				if err1 != nil {
-					runErr = errors.Wrap(err1, err2.Error())
-				} else {
-					runErr = err2
-				}
-			}
+	// run sessions with a bounded pool of workers
+	sessions := r.runSessions(caseRunners)
 
-			if runErr != nil && r.failfast {
-				break
-			}
+	// merge session results in the original order of testcases and parameters
+	var runErr error
+	for _, session := range sessions {
+		if session.summary == nil {
+			// skipped due to failfast setting
+			continue
+		}
+		s.appendCaseSummary(session.summary)
+		if session.err != nil {
+			runErr = session.err
 		}
 	}
 	s.Time.Duration = time.Since(s.Time.StartAt).Seconds()
 
-	// save summary
-	if r.saveTests {
-		if err := s.genSummary(); err != nil {
+	// save summary and generate reports
+	s.allureDir = r.allureDir
+	for _, format := range r.getReportFormats() {
+		if err = s.WriteReport(format); err != nil {
 			return err
 		}
 	}
 
-	// generate HTML report
+	return runErr
+}
+
+// getReportFormats returns deduplicated report formats,
+// json, html and allure are included if saveTests, genHTMLReport and allureDir are set.
+func (r *HRPRunner) getReportFormats() []string {
+	formats := append([]string{}, r.reportFormats...)
+	if r.saveTests {
+		formats = append(formats, "json")
+	}
 	if r.genHTMLReport {
-		if err := s.genHTMLReport(); err != nil {
-			return err
+		formats = append(formats, "html")
+	}
+	if r.allureDir != "" {
+		formats = append(formats, "allure")
+	}
+
+	var result []string
+	visited := make(map[string]bool)
+	for _, format := range formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "" || visited[format] {
+			continue
 		}
+		visited[format] = true
+		result = append(result, format)
 	}
+	return result
+}
 
-	return runErr
+// sessionTask represents one run of testcase with specified parameters.
+type sessionTask struct {
+	caseIndex       int
+	caseRunner      *CaseRunner
+	parameters      map[string]interface{}
+	parametersIndex int // index of sequential parameters starting from 1, 0 if not parameterized
+	summary         *TestCaseSummary
+	err             error
+}
+
+// runSessions runs all parameters iterations of the case runners with r.concurrency workers.
+// tasks are returned in dispatch order, which is the order of testcases and parameters.
+func (r *HRPRunner) runSessions(caseRunners []*CaseRunner) []*sessionTask {
+	concurrency := r.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// failed[i] is set when failfast is on and one session of caseRunners[i] failed,
+	// remaining parameters of the testcase will be skipped.
+	failed := make([]int32, len(caseRunners))
+
+	taskChan := make(chan *sessionTask)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskChan {
+				if r.failfast && atomic.LoadInt32(&failed[task.caseIndex]) == 1 {
+					continue
+				}
+				task.summary, task.err = task.caseRunner.runSession(task.parameters, task.parametersIndex)
+				if task.err != nil {
+					atomic.StoreInt32(&failed[task.caseIndex], 1)
+				}
+			}
+		}()
+	}
+
+	var tasks []*sessionTask
+	for i, caseRunner := range caseRunners {
+		// parameters iterator is not thread safe, iterate in dispatcher only
+		for it := caseRunner.parametersIterator; it.HasNext(); {
+			if r.failfast && atomic.LoadInt32(&failed[i]) == 1 {
+				break
+			}
+			task := &sessionTask{
+				caseIndex:  i,
+				caseRunner: caseRunner,
+			}
+			task.parameters, task.parametersIndex = it.next()
+			tasks = append(tasks, task)
+			taskChan <- task
+		}
+	}
+	close(taskChan)
+	wg.Wait()
+	return tasks
 }
 
 // NewCaseRunner creates a new case runner for testcase.
 // each testcase has its own case runner
 func (r *HRPRunner) NewCaseRunner(testcase *TestCase) (*CaseRunner, error) {
 	caseRunner := &CaseRunner{
-		testCase:  testcase,
-		hrpRunner: r,
-		parser:    newParser(),
+		testCase:     testcase,
+		hrpRunner:    r,
+		parser:       newParser(),
+		oauth2Tokens: newOAuth2TokenCache(),
 	}
 
 	// init parser plugin
@@ -301,6 +442,9 @@ func (r *HRPRunner) NewCaseRunner(testcase *TestCase) (*CaseRunner, error) {
 	if plugin != nil {
 		caseRunner.parser.plugin = plugin
 		caseRunner.rootDir = filepath.Dir(plugin.Path())
+	} else {
+		// locate project root dir by proj.json
+		caseRunner.rootDir, _ = GetProjectRootDirPath(testcase.Config.Path)
 	}
 
 	// parse testcase config
@@ -308,13 +452,26 @@ func (r *HRPRunner) NewCaseRunner(testcase *TestCase) (*CaseRunner, error) {
 		return nil, errors.Wrap(err, "parse testcase config failed")
 	}
 
-	// set request timeout in seconds
+	// load OpenAPI spec for contract validation
+	if testcase.Config.OpenAPI != "" {
+		specPath := testcase.Config.OpenAPI
+		if !filepath.IsAbs(specPath) {
+			specPath = filepath.Join(caseRunner.rootDir, specPath)
+		}
+		caseRunner.openapiSpec, err = openapi.LoadFile(specPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "load openapi spec failed")
+		}
+		log.Info().Str("path", specPath).Msg("load openapi spec for contract validation")
+	}
+
+	// set request timeout and testcase timeout in seconds, which are applied when each session starts
 	if testcase.Config.RequestTimeout != 0 {
-		r.SetRequestTimeout(testcase.Config.RequestTimeout)
+		caseRunner.requestTimeout = time.Duration(testcase.Config.RequestTimeout*1000) * time.Millisecond
 	}
-	// set testcase timeout in seconds
+	caseRunner.caseTimeout = r.caseTimeout
 	if testcase.Config.CaseTimeout != 0 {
-		r.SetCaseTimeout(testcase.Config.CaseTimeout)
+		caseRunner.caseTimeout = time.Duration(testcase.Config.CaseTimeout*1000) * time.Millisecond
 	}
 
 	// load plugin info to testcase config
@@ -346,7 +503,17 @@ type CaseRunner struct {
 	parsedConfig       *TConfig
 	parametersIterator *ParametersIterator
 	rootDir            string                     // project root dir
+	openapiSpec        *openapi.Spec              // OpenAPI spec for contract validation
 	uiClients          map[string]*uixt.DriverExt // UI automation clients for iOS and Android, key is udid/serial
+	requestTimeout     time.Duration              // request timeout of testcase, 0 means timeout of HTTP clients
+	caseTimeout        time.Duration              // testcase timeout of each session
+	oauth2Tokens       *oauth2TokenCache          // OAuth2 access tokens shared by sessions of testcase
+}
+
+// snapshotDir returns the dir to store response snapshots of testcase.
+func (r *CaseRunner) snapshotDir() string {
+	return filepath.Join(r.rootDir, env.ResultsDirName, "snapshots",
+		sanitizeFileName(r.parsedConfig.Name))
 }
 
 // parseConfig parses testcase config, stores to parsedConfig.
@@ -382,6 +549,12 @@ func (r *CaseRunner) parseConfig() error {
 	}
 	r.parsedConfig.BaseURL = convertString(parsedBaseURL)
 
+	// parse config TLS settings
+	r.parsedConfig.TLS, err = cfg.TLS.parse(r.parser, parsedVariables)
+	if err != nil {
+		return err
+	}
+
 	// merge config environment variables with base_url
 	// priority: env base_url > base_url
 	if cfg.Environs != nil {
@@ -464,6 +637,27 @@ func (r *CaseRunner) parseConfig() error {
 	return nil
 }
 
+// runSession runs testcase once with given parameters in a new session runner.
+func (r *CaseRunner) runSession(parameters map[string]interface{}, parametersIndex int) (*TestCaseSummary, error) {
+	// case runner can run multiple times with different parameters
+	// each run has its own session runner
+	sessionRunner := r.NewSession()
+	sessionRunner.setParametersIndex(parametersIndex)
+	err1 := sessionRunner.Start(parameters)
+	if err1 != nil {
+		log.Error().Err(err1).Msg("[Run] run testcase failed")
+	}
+	caseSummary, err2 := sessionRunner.GetSummary()
+	if err2 != nil {
+		log.Error().Err(err2).Msg("[Run] get summary failed")
+		if err1 != nil {
+			return caseSummary, errors.Wrap(err1, err2.Error())
+		}
+		return caseSummary, err2
+	}
+	return caseSummary, err1
+}
+
 // each boomer task initiates a new session
 // in order to avoid data racing
 func (r *CaseRunner) NewSession() *SessionRunner {
@@ -489,6 +683,9 @@ type SessionRunner struct {
 	inheritWsConnMap  map[string]*websocket.Conn // inherit all websocket connections
 	pongResponseChan  chan string                // channel used to receive pong response message
 	closeResponseChan chan *wsCloseRespObject    // channel used to receive close response message
+	cookieJar         http.CookieJar             // cookies of session running concurrently, nil to share cookies of runner
+	caseTimeoutTimer  *time.Timer                // testcase timeout timer, started when session starts
+	snapshotSuffix    string                     // distinguishes snapshots of parameters and foreach iterations, e.g. _params_1_foreach_2
 }
 
 func (r *SessionRunner) resetSession() {
@@ -501,6 +698,12 @@ func (r *SessionRunner) resetSession() {
 	r.inheritWsConnMap = make(map[string]*websocket.Conn)
 	r.pongResponseChan = make(chan string, 1)
 	r.closeResponseChan = make(chan *wsCloseRespObject, 1)
+	r.snapshotSuffix = ""
+	// sessions running concurrently have their own cookies, otherwise cookies are shared by testcases of runner
+	r.cookieJar = nil
+	if r.caseRunner.hrpRunner.concurrency > 1 {
+		r.cookieJar, _ = cookiejar.New(nil)
+	}
 }
 
 func (r *SessionRunner) inheritConnection(src *SessionRunner) {
@@ -512,6 +715,18 @@ func (r *SessionRunner) inheritConnection(src *SessionRunner) {
 	for k, v := range src.inheritWsConnMap {
 		r.inheritWsConnMap[k] = v
 	}
+	// referenced testcase shares cookies with its caller
+	r.cookieJar = src.cookieJar
+	// snapshots of referenced testcase are distinguished by parameters and foreach iteration of its caller
+	r.snapshotSuffix = src.snapshotSuffix
+}
+
+// setParametersIndex sets index of sequential parameters used by session, starting from 1,
+// which is included in default snapshot path to avoid collision between parameterized sessions.
+func (r *SessionRunner) setParametersIndex(index int) {
+	if index > 0 {
+		r.snapshotSuffix = fmt.Sprintf("_params_%d", index)
+	}
 }
 
 // Start runs the test steps in sequential order.
@@ -526,6 +741,10 @@ func (r *SessionRunner) Start(givenVars map[string]interface{}) error {
 	// update config variables with given variables
 	r.InitWithParameters(givenVars)
 
+	// each session has its own timer, thus sessions running concurrently time out independently
+	r.caseTimeoutTimer = time.NewTimer(r.caseRunner.caseTimeout)
+	defer r.caseTimeoutTimer.Stop()
+
 	defer func() {
 		// close session resource after all steps done or fast fail
 		r.releaseResources()
@@ -534,7 +753,7 @@ func (r *SessionRunner) Start(givenVars map[string]interface{}) error {
 	// run step in sequential order
 	for _, step := range r.caseRunner.testCase.TestSteps {
 		select {
-		case <-r.caseRunner.hrpRunner.caseTimeoutTimer.C:
+		case <-r.caseTimeoutTimer.C:
 			log.Warn().Msg("timeout in session runner")
 			return errors.Wrap(code.TimeoutError, "session runner timeout")
 		case <-r.caseRunner.hrpRunner.interruptSignal:
@@ -552,33 +771,33 @@ func (r *SessionRunner) Start(givenVars map[string]interface{}) error {
 			log.Info().Str("step", stepName).Str("type", stepType).Msg("run step start")
 			stepStartTime := time.Now()
 
-			// run times of step
-			loopTimes := step.Struct().Loops
-			if loopTimes < 0 {
-				log.Warn().Int("loops", loopTimes).Msg("loop times should be positive, set to 1")
-				loopTimes = 1
-			} else if loopTimes == 0 {
-				loopTimes = 1
-			} else if loopTimes > 1 {
-				log.Info().Int("loops", loopTimes).Msg("run step with specified loop times")
-			}
-
-			// run step with specified loop times
+			// check step conditions, skip step if skip_if is true or run_if is false
 			var stepResult *StepResult
-			for i := 1; i <= loopTimes; i++ {
-				var loopIndex string
-				if loopTimes > 1 {
-					log.Info().Int("index", i).Msg("start running step in loop")
-					loopIndex = fmt.Sprintf("_loop_%d", i)
+			skipReason, err := r.checkStepCondition(step.Struct())
+			if err != nil {
+				stepResult = &StepResult{
+					Name:        stepName,
+					StepType:    step.Type(),
+					StartTime:   time.Now().Unix(),
+					Success:     false,
+					Attachments: err.Error(),
 				}
-
-				// run step
-				startTime := time.Now().Unix()
-				stepResult, err = step.Run(r)
-				stepResult.Name = stepName + loopIndex
-				stepResult.StartTime = startTime
-
 				r.updateSummary(stepResult)
+			} else if skipReason != "" {
+				log.Info().Str("step", stepName).
+					Str("type", stepType).
+					Str("reason", skipReason).
+					Msg("skip step")
+				r.updateSummary(&StepResult{
+					Name:        stepName,
+					StepType:    step.Type(),
+					StartTime:   time.Now().Unix(),
+					Skipped:     true,
+					Attachments: skipReason,
+				})
+				continue
+			} else {
+				stepResult, err = r.runStepWithLoops(step, stepName)
 			}
 
 			// update extracted variables
@@ -620,6 +839,185 @@ func (r *SessionRunner) Start(givenVars map[string]interface{}) error {
 	return nil
 }
 
+// runStepWithLoops runs step with specified loop times, returns the result of the last loop.
+func (r *SessionRunner) runStepWithLoops(step IStep, stepName string) (stepResult *StepResult, err error) {
+	if step.Struct().Foreach != nil {
+		return r.runStepForeach(step, stepName)
+	}
+
+	// run times of step
+	loopTimes := step.Struct().Loops
+	if loopTimes < 0 {
+		log.Warn().Int("loops", loopTimes).Msg("loop times should be positive, set to 1")
+		loopTimes = 1
+	} else if loopTimes == 0 {
+		loopTimes = 1
+	} else if loopTimes > 1 {
+		log.Info().Int("loops", loopTimes).Msg("run step with specified loop times")
+	}
+
+	// run step with specified loop times
+	for i := 1; i <= loopTimes; i++ {
+		var loopIndex string
+		if loopTimes > 1 {
+			log.Info().Int("index", i).Msg("start running step in loop")
+			loopIndex = fmt.Sprintf("_loop_%d", i)
+		}
+
+		// run step
+		startTime := time.Now().Unix()
+		stepResult, err = step.Run(r)
+		stepResult.Name = stepName + loopIndex
+		stepResult.StartTime = startTime
+
+		r.updateSummary(stepResult)
+	}
+	return stepResult, err
+}
+
+// runStepForeach runs step once for each item of foreach list, the item is bound to session variable
+// during the iteration. ExportVars of all iterations are collected into lists in the returned result.
+func (r *SessionRunner) runStepForeach(step IStep, stepName string) (stepResult *StepResult, err error) {
+	foreach := step.Struct().Foreach
+	if step.Struct().Loops > 1 {
+		log.Warn().Int("loops", step.Struct().Loops).Msg("loops is ignored for foreach step")
+	}
+
+	items, err := r.parseForeachItems(step.Struct())
+	if err != nil {
+		stepResult = &StepResult{
+			Name:        stepName,
+			StepType:    step.Type(),
+			StartTime:   time.Now().Unix(),
+			Success:     false,
+			Attachments: err.Error(),
+		}
+		r.updateSummary(stepResult)
+		return stepResult, err
+	}
+	as := foreach.As
+	if as == "" {
+		as = "item"
+	}
+	log.Info().Int("items", len(items)).Str("as", as).Msg("run step for each item")
+
+	// restore session variable and snapshot suffix after all iterations done
+	prevValue, existed := r.sessionVariables[as]
+	prevSnapshotSuffix := r.snapshotSuffix
+	defer func() {
+		if existed {
+			r.sessionVariables[as] = prevValue
+		} else {
+			delete(r.sessionVariables, as)
+		}
+		r.snapshotSuffix = prevSnapshotSuffix
+	}()
+
+	var exportVarsList []map[string]interface{}
+	for i, item := range items {
+		log.Info().Int("index", i+1).Interface(as, item).Msg("start running step for item")
+		r.sessionVariables[as] = item
+		r.snapshotSuffix = fmt.Sprintf("%s_foreach_%d", prevSnapshotSuffix, i+1)
+
+		startTime := time.Now().Unix()
+		iterResult, iterErr := step.Run(r)
+		iterResult.Name = fmt.Sprintf("%s_foreach_%d", stepName, i+1)
+		iterResult.StartTime = startTime
+		r.updateSummary(iterResult)
+
+		stepResult = iterResult
+		exportVarsList = append(exportVarsList, iterResult.ExportVars)
+		if iterErr == nil {
+			continue
+		}
+		err = iterErr
+		if errors.Is(err, code.InterruptError) || errors.Is(err, code.TimeoutError) ||
+			r.caseRunner.hrpRunner.failfast {
+			break
+		}
+	}
+
+	// collect export variables into lists, keep aligned with items
+	collected := make(map[string]interface{})
+	for _, exportVars := range exportVarsList {
+		for k := range exportVars {
+			collected[k] = make([]interface{}, 0, len(exportVarsList))
+		}
+	}
+	for k := range collected {
+		values := collected[k].([]interface{})
+		for _, exportVars := range exportVarsList {
+			values = append(values, exportVars[k])
+		}
+		collected[k] = values
+	}
+
+	if stepResult == nil {
+		// empty foreach items
+		return &StepResult{Name: stepName, StepType: step.Type(), Success: true}, nil
+	}
+	result := *stepResult
+	result.Name = stepName
+	result.ExportVars = collected
+	return &result, err
+}
+
+// parseForeachItems parses foreach items with step variables, the parsed value should be a list.
+func (r *SessionRunner) parseForeachItems(step *TStep) ([]interface{}, error) {
+	stepVariables, err := r.ParseStepVariables(step.Variables)
+	if err != nil {
+		return nil, err
+	}
+	parsedItems, err := r.caseRunner.parser.Parse(step.Foreach.Items, stepVariables)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse foreach items failed")
+	}
+
+	itemsValue := reflect.ValueOf(parsedItems)
+	if itemsValue.Kind() != reflect.Slice && itemsValue.Kind() != reflect.Array {
+		return nil, errors.Errorf("foreach items should be a list, got %T: %v", parsedItems, parsedItems)
+	}
+	items := make([]interface{}, itemsValue.Len())
+	for i := 0; i < itemsValue.Len(); i++ {
+		items[i] = itemsValue.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// checkStepCondition evaluates skip_if and run_if expressions of step with step variables,
+// returns the reason if step should be skipped, otherwise returns empty string.
+func (r *SessionRunner) checkStepCondition(step *TStep) (skipReason string, err error) {
+	if step.SkipIf == "" && step.RunIf == "" {
+		return "", nil
+	}
+
+	stepVariables, err := r.ParseStepVariables(step.Variables)
+	if err != nil {
+		return "", err
+	}
+
+	if step.SkipIf != "" {
+		value, err := r.caseRunner.parser.ParseString(step.SkipIf, stepVariables)
+		if err != nil {
+			return "", errors.Wrap(err, "parse skip_if condition failed")
+		}
+		if isTruthy(value) {
+			return fmt.Sprintf("skip_if condition is true: %s", step.SkipIf), nil
+		}
+	}
+
+	if step.RunIf != "" {
+		value, err := r.caseRunner.parser.ParseString(step.RunIf, stepVariables)
+		if err != nil {
+			return "", errors.Wrap(err, "parse run_if condition failed")
+		}
+		if !isTruthy(value) {
+			return fmt.Sprintf("run_if condition is false: %s", step.RunIf), nil
+		}
+	}
+	return "", nil
+}
+
 // ParseStepVariables merges step variables with config variables and session variables
 func (r *SessionRunner) ParseStepVariables(stepVariables map[string]interface{}) (map[string]interface{}, error) {
 	// override variables
@@ -725,7 +1123,9 @@ func (r *SessionRunner) addSingleStepResult(stepResult *StepResult) {
 	// update summary
 	r.summary.Records = append(r.summary.Records, stepResult)
 	r.summary.Stat.Total += 1
-	if stepResult.Success {
+	if stepResult.Skipped {
+		r.summary.Stat.Skipped += 1
+	} else if stepResult.Success {
 		r.summary.Stat.Successes += 1
 	} else {
 		r.summary.Stat.Failures += 1
@@ -734,6 +1134,58 @@ func (r *SessionRunner) addSingleStepResult(stepResult *StepResult) {
 	}
 }
 
+// sleep waits for the duration in step, e.g. retry interval, and returns error early
+// if testcase timeout or interrupted, which is passed on for the session runner to stop.
+func (r *SessionRunner) sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	ctx, stop := r.newStepContext(context.Background())
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return stop()
+}
+
+// newStepContext derives context which is canceled if testcase timeout or interrupted,
+// stop releases the context and returns the timeout or interrupt error to stop the session runner.
+func (r *SessionRunner) newStepContext(parent context.Context) (ctx context.Context, stop func() error) {
+	ctx, cancel := context.WithCancel(parent)
+
+	// case timer is not started when steps are run by boomer
+	var caseTimeout <-chan time.Time
+	if r.caseTimeoutTimer != nil {
+		caseTimeout = r.caseTimeoutTimer.C
+	}
+	interruptSignal := r.caseRunner.hrpRunner.interruptSignal
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+		case <-caseTimeout:
+			// fire again for the session runner to stop
+			r.caseTimeoutTimer.Reset(0)
+			err = errors.Wrap(code.TimeoutError, "session runner timeout")
+			cancel()
+		case sig := <-interruptSignal:
+			select {
+			case interruptSignal <- sig:
+			default:
+			}
+			err = errors.Wrap(code.InterruptError, "session runner interrupted")
+			cancel()
+		}
+	}()
+	return ctx, func() error {
+		cancel()
+		<-done
+		return err
+	}
+}
+
 // releaseResources releases resources used by session runner
 func (r *SessionRunner) releaseResources() {
 	// close websocket connections
@@ -1,9 +1,13 @@
 package hrp
 
 import (
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -247,3 +251,218 @@ func TestLoadTestCases(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestRunSessionsConcurrently(t *testing.T) {
+	testcase := &TestCase{
+		Config: NewConfig("TestCase").
+			WithParameters(map[string]interface{}{
+				"index": []interface{}{1, 2, 3, 4},
+			}).
+			ExportVars("index"),
+		TestSteps: []IStep{
+			NewStep("thinkTime").SetThinkTime(0.5),
+		},
+	}
+
+	r := NewRunner(t).SetConcurrency(4)
+	caseRunner, err := r.NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	startTime := time.Now()
+	tasks := r.runSessions([]*CaseRunner{caseRunner})
+	duration := time.Since(startTime)
+	if duration > 1500*time.Millisecond {
+		t.Fatalf("sessions should run concurrently, actual duration: %v", duration)
+	}
+
+	// session results keep the order of parameters
+	if !assert.Equal(t, 4, len(tasks)) {
+		t.Fatal()
+	}
+	for i, task := range tasks {
+		if !assert.Nil(t, task.err) {
+			t.Fatal()
+		}
+		if !assert.EqualValues(t, i+1, task.summary.InOut.ExportVars["index"]) {
+			t.Fatal()
+		}
+	}
+}
+
+func TestRunSessionsWithCaseTimeout(t *testing.T) {
+	newTestCase := func(name string, caseTimeout float32) *TestCase {
+		return &TestCase{
+			Config: NewConfig(name).SetCaseTimeout(caseTimeout),
+			TestSteps: []IStep{
+				NewStep("thinkTime").SetThinkTime(0.3),
+				NewStep("thinkTime").SetThinkTime(0.3),
+				NewStep("thinkTime").SetThinkTime(0.3),
+			},
+		}
+	}
+
+	r := NewRunner(t).SetConcurrency(2)
+	var caseRunners []*CaseRunner
+	for _, testcase := range []*TestCase{newTestCase("timeout", 0.5), newTestCase("no timeout", 10)} {
+		caseRunner, err := r.NewCaseRunner(testcase)
+		if !assert.Nil(t, err) {
+			t.Fatal()
+		}
+		caseRunners = append(caseRunners, caseRunner)
+	}
+
+	// each session applies case timeout of its own testcase
+	tasks := r.runSessions(caseRunners)
+	if !assert.Equal(t, 2, len(tasks)) {
+		t.Fatal()
+	}
+	if !assert.ErrorIs(t, tasks[0].err, code.TimeoutError) {
+		t.Fatal()
+	}
+	if !assert.Nil(t, tasks[1].err) {
+		t.Fatal()
+	}
+}
+
+func TestRunSessionsWithCookies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("user"); err == nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "user", Value: r.URL.Query().Get("user")})
+	}))
+	defer ts.Close()
+
+	testcase := &TestCase{
+		Config: NewConfig("TestCase").
+			SetBaseURL(ts.URL).
+			WithParameters(map[string]interface{}{
+				"user": []interface{}{"foo", "bar"},
+			}),
+		TestSteps: []IStep{
+			NewStep("set cookie").
+				GET("/?user=$user").
+				Validate().
+				AssertEqual("status_code", 200, "cookie should not be shared between sessions"),
+		},
+	}
+
+	// sessions running concurrently have their own cookies
+	r := NewRunner(t).SetConcurrency(2)
+	caseRunner, err := r.NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	for _, task := range r.runSessions([]*CaseRunner{caseRunner}) {
+		if !assert.Nil(t, task.err) {
+			t.Fatal()
+		}
+	}
+
+	// cookies are shared by sessions running in sequence
+	r = NewRunner(nil)
+	caseRunner, err = r.NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	tasks := r.runSessions([]*CaseRunner{caseRunner})
+	if !assert.Nil(t, tasks[0].err) || !assert.Error(t, tasks[1].err) {
+		t.Fatal()
+	}
+}
+
+func TestRunCaseWithStepConditions(t *testing.T) {
+	testcase := &TestCase{
+		Config: NewConfig("TestCase").
+			WithVariables(map[string]interface{}{
+				"is_prod": true,
+			}),
+		TestSteps: []IStep{
+			NewStep("skipped by skip_if").
+				SkipIf("$is_prod").
+				SetThinkTime(0.1),
+			NewStep("run by run_if").
+				RunIf("$run").
+				WithVariables(map[string]interface{}{"run": true}).
+				SetThinkTime(0.1),
+			NewStep("skipped by run_if").
+				RunIf("$run").
+				WithVariables(map[string]interface{}{"run": false}).
+				SetThinkTime(0.1),
+		},
+	}
+	caseRunner, err := NewRunner(t).NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	sessionRunner := caseRunner.NewSession()
+	if !assert.Nil(t, sessionRunner.Start(nil)) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	if !assert.True(t, summary.Success) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, &TestStepStat{Total: 3, Successes: 1, Skipped: 2}, summary.Stat) {
+		t.Fatal()
+	}
+	if !assert.True(t, summary.Records[0].Skipped) || !assert.False(t, summary.Records[1].Skipped) {
+		t.Fatal()
+	}
+}
+
+func TestRunCaseWithForeach(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.TrimSuffix(r.URL.Path, "/") == "/orders" {
+			w.Write([]byte(`{"ids": [101, 102, 103]}`))
+			return
+		}
+		fmt.Fprintf(w, `{"path": "%s", "status": "paid"}`, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	testcase := &TestCase{
+		Config: NewConfig("TestCase").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("list orders").
+				GET("/orders").
+				Extract().
+				WithJmesPath("body.ids", "ids"),
+			NewStep("get order").
+				Foreach("$ids", "order_id").
+				GET("/orders/$order_id").
+				Extract().
+				WithJmesPath("body.path", "path").
+				Validate().
+				AssertEqual("body.status", "paid", "check order status"),
+		},
+	}
+	caseRunner, err := NewRunner(t).NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	sessionRunner := caseRunner.NewSession()
+	if !assert.Nil(t, sessionRunner.Start(nil)) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	if !assert.Equal(t, 4, summary.Stat.Total) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "get order_foreach_3", summary.Records[3].Name) {
+		t.Fatal()
+	}
+	if !assert.Equal(t,
+		[]interface{}{"/orders/101/", "/orders/102/", "/orders/103/"},
+		sessionRunner.sessionVariables["path"]) {
+		t.Fatal()
+	}
+	// foreach variable is not leaked into session variables
+	if !assert.NotContains(t, sessionRunner.sessionVariables, "order_id") {
+		t.Fatal()
+	}
+}
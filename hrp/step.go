@@ -10,6 +10,8 @@ const (
 	stepTypeRendezvous  StepType = "rendezvous"
 	stepTypeThinkTime   StepType = "thinktime"
 	stepTypeWebSocket   StepType = "websocket"
+	stepTypeSSE         StepType = "sse"
+	stepTypeGRPC        StepType = "grpc"
 	stepTypeAndroid     StepType = "android"
 	stepTypeIOS         StepType = "ios"
 )
@@ -19,6 +21,7 @@ type StepResult struct {
 	StartTime   int64                  `json:"start_time" yaml:"time"`                             // step start time
 	StepType    StepType               `json:"step_type" yaml:"step_type"`                         // step type, testcase/request/transaction/rendezvous
 	Success     bool                   `json:"success" yaml:"success"`                             // step execution result
+	Skipped     bool                   `json:"skipped,omitempty" yaml:"skipped,omitempty"`         // step is skipped by skip_if/run_if condition
 	Elapsed     int64                  `json:"elapsed_ms" yaml:"elapsed_ms"`                       // step execution time in millisecond(ms)
 	HttpStat    map[string]int64       `json:"httpstat,omitempty" yaml:"httpstat,omitempty"`       // httpstat in millisecond(ms)
 	Data        interface{}            `json:"data,omitempty" yaml:"data,omitempty"`               // session data or slice of step data
@@ -38,6 +41,8 @@ type TStep struct {
 	Rendezvous    *Rendezvous            `json:"rendezvous,omitempty" yaml:"rendezvous,omitempty"`
 	ThinkTime     *ThinkTime             `json:"think_time,omitempty" yaml:"think_time,omitempty"`
 	WebSocket     *WebSocketAction       `json:"websocket,omitempty" yaml:"websocket,omitempty"`
+	SSE           *SSEAction             `json:"sse,omitempty" yaml:"sse,omitempty"`
+	GRPC          *GRPCRequest           `json:"grpc,omitempty" yaml:"grpc,omitempty"`
 	Android       *MobileStep            `json:"android,omitempty" yaml:"android,omitempty"`
 	IOS           *MobileStep            `json:"ios,omitempty" yaml:"ios,omitempty"`
 	Variables     map[string]interface{} `json:"variables,omitempty" yaml:"variables,omitempty"`
@@ -47,12 +52,23 @@ type TStep struct {
 	Validators    []interface{}          `json:"validate,omitempty" yaml:"validate,omitempty"`
 	Export        []string               `json:"export,omitempty" yaml:"export,omitempty"`
 	Loops         int                    `json:"loops,omitempty" yaml:"loops,omitempty"`
+	SkipIf        string                 `json:"skip_if,omitempty" yaml:"skip_if,omitempty"`       // skip step if expression is true
+	RunIf         string                 `json:"run_if,omitempty" yaml:"run_if,omitempty"`         // run step only if expression is true
+	Retry         *RequestRetry          `json:"retry,omitempty" yaml:"retry,omitempty"`           // retry policy for request step
+	WaitUntil     *RequestWaitUntil      `json:"wait_until,omitempty" yaml:"wait_until,omitempty"` // polling policy for request step
+	Foreach       *TForeach              `json:"foreach,omitempty" yaml:"foreach,omitempty"`       // run step for each item of list
+}
+
+// TForeach represents the list to iterate over for data-driven step.
+type TForeach struct {
+	Items interface{} `json:"items" yaml:"items"`               // list or expression evaluated to list, e.g. "${ids}"
+	As    string      `json:"as,omitempty" yaml:"as,omitempty"` // variable name bound to each item, default "item"
 }
 
 // IStep represents interface for all types for teststeps, includes:
 // StepRequest, StepRequestWithOptionalArgs, StepRequestValidation, StepRequestExtraction,
 // StepTestCaseWithOptionalArgs,
-// StepTransaction, StepRendezvous, StepWebSocket.
+// StepTransaction, StepRendezvous, StepWebSocket, StepSSE, StepGRPC.
 type IStep interface {
 	Name() string
 	Type() StepType
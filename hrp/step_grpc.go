@@ -0,0 +1,489 @@
+package hrp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+)
+
+// GRPCRequest represents gRPC call of unary or streaming method, messages are converted from and to JSON
+// with service descriptors loaded from proto files or server reflection.
+type GRPCRequest struct {
+	Target      string            `json:"target,omitempty" yaml:"target,omitempty"`             // server address, e.g. localhost:50051, grpcs://example.com, base_url is used if empty
+	Method      string            `json:"method" yaml:"method"`                                 // full method name, e.g. helloworld.Greeter/SayHello
+	ProtoFiles  []string          `json:"proto_files,omitempty" yaml:"proto_files,omitempty"`   // .proto files or descriptor sets, server reflection is used if empty
+	ImportPaths []string          `json:"import_paths,omitempty" yaml:"import_paths,omitempty"` // import paths to resolve imports of proto files
+	Metadata    map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Body        interface{}       `json:"body,omitempty" yaml:"body,omitempty"`       // request message in JSON, list of messages for client streaming
+	Timeout     float64           `json:"timeout,omitempty" yaml:"timeout,omitempty"` // deadline in seconds, request timeout is used if not set
+	TLS         *TLSConfig        `json:"tls,omitempty" yaml:"tls,omitempty"`         // TLS settings overriding config for grpcs target
+}
+
+// grpcRespObjMeta represents response of gRPC step, body is list of messages for server streaming.
+type grpcRespObjMeta struct {
+	StatusCode    int               `json:"status_code"` // gRPC status code, 0 for OK
+	StatusMessage string            `json:"status_message"`
+	Headers       map[string]string `json:"headers"`
+	Trailers      map[string]string `json:"trailers"`
+	Body          interface{}       `json:"body"`
+}
+
+// StepGRPC implements IStep interface.
+type StepGRPC struct {
+	step *TStep
+}
+
+func (s *StepGRPC) Name() string {
+	if s.step.Name != "" {
+		return s.step.Name
+	}
+	return fmt.Sprintf("grpc %s", s.step.GRPC.Method)
+}
+
+func (s *StepGRPC) Type() StepType {
+	return stepTypeGRPC
+}
+
+func (s *StepGRPC) Struct() *TStep {
+	return s.step
+}
+
+func (s *StepGRPC) Run(r *SessionRunner) (*StepResult, error) {
+	return runStepGRPC(r, s.step)
+}
+
+// Invoke sets full method name to call, e.g. helloworld.Greeter/SayHello
+func (s *StepGRPC) Invoke(method string) *StepGRPC {
+	s.step.GRPC.Method = method
+	return s
+}
+
+// WithTarget sets server address, e.g. localhost:50051, grpcs://example.com
+func (s *StepGRPC) WithTarget(target string) *StepGRPC {
+	s.step.GRPC.Target = target
+	return s
+}
+
+// WithProtoFiles sets .proto files or descriptor sets to resolve method, server reflection is used if not set.
+func (s *StepGRPC) WithProtoFiles(protoFiles ...string) *StepGRPC {
+	s.step.GRPC.ProtoFiles = protoFiles
+	return s
+}
+
+func (s *StepGRPC) WithImportPaths(importPaths ...string) *StepGRPC {
+	s.step.GRPC.ImportPaths = importPaths
+	return s
+}
+
+func (s *StepGRPC) WithMetadata(md map[string]string) *StepGRPC {
+	s.step.GRPC.Metadata = md
+	return s
+}
+
+// WithBody sets request message, body should be list of messages for client streaming method.
+func (s *StepGRPC) WithBody(body interface{}) *StepGRPC {
+	s.step.GRPC.Body = body
+	return s
+}
+
+// WithTimeout sets call deadline in seconds, request timeout of config or runner is used if not set.
+func (s *StepGRPC) WithTimeout(timeout float64) *StepGRPC {
+	s.step.GRPC.Timeout = timeout
+	return s
+}
+
+// Validate switches to step validation.
+func (s *StepGRPC) Validate() *StepRequestValidation {
+	return &StepRequestValidation{
+		step: s.step,
+	}
+}
+
+// Extract switches to step extraction.
+func (s *StepGRPC) Extract() *StepRequestExtraction {
+	s.step.Extract = make(map[string]string)
+	return &StepRequestExtraction{
+		step: s.step,
+	}
+}
+
+// splitGRPCMethod splits full method name into service and method name,
+// e.g. /pkg.Service/Method, pkg.Service/Method and pkg.Service.Method
+func splitGRPCMethod(fullMethod string) (service, method string, err error) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	i := strings.LastIndex(fullMethod, "/")
+	if i < 0 {
+		i = strings.LastIndex(fullMethod, ".")
+	}
+	if i <= 0 || i == len(fullMethod)-1 {
+		return "", "", errors.Errorf("invalid grpc method: %s", fullMethod)
+	}
+	return fullMethod[:i], fullMethod[i+1:], nil
+}
+
+func runStepGRPC(r *SessionRunner, step *TStep) (stepResult *StepResult, err error) {
+	stepResult = &StepResult{
+		Name:        step.Name,
+		StepType:    stepTypeGRPC,
+		Success:     false,
+		ContentSize: 0,
+	}
+
+	// merge step variables with session variables
+	stepVariables, err := r.ParseStepVariables(step.Variables)
+	if err != nil {
+		err = errors.Wrap(err, "parse step variables failed")
+		return
+	}
+
+	defer func() {
+		// update testcase summary
+		if err != nil {
+			stepResult.Attachments = err.Error()
+		}
+	}()
+
+	sessionData := newSessionData()
+	parser := r.caseRunner.parser
+	config := r.caseRunner.parsedConfig
+	rootDir := r.caseRunner.rootDir
+	hrpRunner := r.caseRunner.hrpRunner
+
+	// prepare target and method
+	target, err := parser.ParseString(step.GRPC.Target, stepVariables)
+	if err != nil {
+		return stepResult, errors.Wrap(err, "parse grpc target failed")
+	}
+	if convertString(target) == "" {
+		target = stepVariables["base_url"]
+	}
+	if convertString(target) == "" {
+		return stepResult, errors.New("missing grpc target")
+	}
+	address, secure, err := parseGRPCTarget(convertString(target))
+	if err != nil {
+		return
+	}
+	fullMethod, err := parser.ParseString(step.GRPC.Method, stepVariables)
+	if err != nil {
+		return stepResult, errors.Wrap(err, "parse grpc method failed")
+	}
+	serviceName, methodName, err := splitGRPCMethod(convertString(fullMethod))
+	if err != nil {
+		return
+	}
+
+	stepTLS, err := step.GRPC.TLS.parse(parser, stepVariables)
+	if err != nil {
+		return
+	}
+	profile := &clientProfile{
+		verify: config.Verify,
+		tls:    mergeTLSConfig(config.TLS, stepTLS),
+	}
+	conn, err := hrpRunner.getGRPCConn(address, secure, profile, rootDir)
+	if err != nil {
+		return
+	}
+
+	// resolve method descriptor
+	var protoFiles, importPaths []string
+	for _, paths := range []struct {
+		src []string
+		dst *[]string
+	}{{step.GRPC.ProtoFiles, &protoFiles}, {step.GRPC.ImportPaths, &importPaths}} {
+		for _, path := range paths.src {
+			parsed, err := parser.ParseString(path, stepVariables)
+			if err != nil {
+				return stepResult, errors.Wrap(err, "parse grpc proto path failed")
+			}
+			*paths.dst = append(*paths.dst, resolvePath(rootDir, convertString(parsed)))
+		}
+	}
+	files, err := hrpRunner.getGRPCFiles(conn, serviceName, protoFiles, importPaths)
+	if err != nil {
+		return
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return stepResult, errors.Wrapf(err, "grpc service %s not found", serviceName)
+	}
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return stepResult, errors.Errorf("%s is not a grpc service", serviceName)
+	}
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(methodName))
+	if methodDesc == nil {
+		return stepResult, errors.Errorf("grpc method %s not found in service %s", methodName, serviceName)
+	}
+	types := dynamicpb.NewTypes(files)
+
+	// prepare metadata and request messages
+	md := make(map[string]string)
+	if len(step.GRPC.Metadata) > 0 {
+		parsedMD, err := parser.Parse(step.GRPC.Metadata, stepVariables)
+		if err != nil {
+			return stepResult, errors.Wrap(err, "parse grpc metadata failed")
+		}
+		for key, value := range parsedMD.(map[string]interface{}) {
+			md[strings.ToLower(key)] = convertString(value)
+		}
+	}
+	body, err := parser.Parse(step.GRPC.Body, stepVariables)
+	if err != nil {
+		return stepResult, errors.Wrap(err, "parse grpc body failed")
+	}
+	requests, err := newGRPCRequestMessages(methodDesc, types, body)
+	if err != nil {
+		return
+	}
+
+	fullMethodName := fmt.Sprintf("/%s/%s", serviceName, methodName)
+	requestMap := map[string]interface{}{
+		"target":   address,
+		"method":   fullMethodName,
+		"metadata": md,
+		"body":     body,
+	}
+
+	// add request object to step variables, could be used in setup hooks
+	stepVariables["hrp_step_name"] = step.Name
+	stepVariables["hrp_step_request"] = requestMap
+
+	// deal with setup hooks
+	for _, setupHook := range step.SetupHooks {
+		_, err = parser.Parse(setupHook, stepVariables)
+		if err != nil {
+			return stepResult, errors.Wrap(err, "run setup hooks failed")
+		}
+	}
+
+	// deadline falls back to request timeout of testcase config or runner
+	timeout := r.caseRunner.requestTimeout
+	if step.GRPC.Timeout > 0 {
+		timeout = time.Duration(step.GRPC.Timeout*1000) * time.Millisecond
+	} else if timeout == 0 {
+		timeout = hrpRunner.httpClient.Timeout
+	}
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.New(md))
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if hrpRunner.requestsLogOn {
+		fmt.Printf("-------------------- grpc call: %s --------------------\n", fullMethodName)
+		printGRPCMessage("request", requestMap)
+	}
+	log.Info().Str("target", address).Str("method", fullMethodName).
+		Bool("clientStreaming", methodDesc.IsStreamingClient()).
+		Bool("serverStreaming", methodDesc.IsStreamingServer()).Msg("call grpc method")
+	start := time.Now()
+	result := invokeGRPC(ctx, conn, methodDesc, fullMethodName, requests)
+	stepResult.Elapsed = time.Since(start).Milliseconds()
+
+	meta, contentSize, err := newGRPCRespObjMeta(result, methodDesc, types)
+	if err != nil {
+		return
+	}
+	stepResult.ContentSize = contentSize
+	if hrpRunner.requestsLogOn {
+		printGRPCMessage("response", meta)
+	}
+
+	respObj, err := convertToResponseObject(hrpRunner.t, parser, meta)
+	if err != nil {
+		err = errors.Wrap(err, "init ResponseObject error")
+		return
+	}
+
+	// add response object to step variables, could be used in teardown hooks
+	stepVariables["hrp_step_response"] = respObj.respObjMeta
+
+	// deal with teardown hooks
+	for _, teardownHook := range step.TeardownHooks {
+		_, err = parser.Parse(teardownHook, stepVariables)
+		if err != nil {
+			return stepResult, errors.Wrap(err, "run teardown hooks failed")
+		}
+	}
+
+	sessionData.ReqResps.Request = requestMap
+	sessionData.ReqResps.Response = builtin.FormatResponse(respObj.respObjMeta)
+
+	// extract variables from response
+	extractMapping := respObj.Extract(step.Extract, stepVariables)
+	stepResult.ExportVars = extractMapping
+
+	// override step variables with extracted variables
+	stepVariables = mergeVariables(stepVariables, extractMapping)
+
+	// validate response
+	err = respObj.Validate(step.Validators, stepVariables)
+	sessionData.Validators = respObj.validationResults
+	if err == nil {
+		sessionData.Success = true
+		stepResult.Success = true
+	}
+	stepResult.Data = sessionData
+
+	return stepResult, err
+}
+
+// newGRPCRequestMessages converts JSON body to request messages,
+// body of client streaming method is list of messages and others are single message.
+func newGRPCRequestMessages(method protoreflect.MethodDescriptor, types *dynamicpb.Types,
+	body interface{},
+) ([]proto.Message, error) {
+	var items []interface{}
+	switch v := body.(type) {
+	case nil:
+		if !method.IsStreamingClient() {
+			items = []interface{}{map[string]interface{}{}}
+		}
+	case []interface{}:
+		if !method.IsStreamingClient() {
+			return nil, errors.Errorf("grpc method %s is not client streaming, body should not be list", method.FullName())
+		}
+		items = v
+	default:
+		items = []interface{}{v}
+	}
+
+	messages := make([]proto.Message, 0, len(items))
+	for _, item := range items {
+		content, err := json.Marshal(item)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal grpc request body failed")
+		}
+		msg := dynamicpb.NewMessage(method.Input())
+		if err := (protojson.UnmarshalOptions{Resolver: types}).Unmarshal(content, msg); err != nil {
+			return nil, errors.Wrapf(err, "convert grpc request body to %s failed", method.Input().FullName())
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// grpcResult stores response messages, metadata and status of gRPC call.
+type grpcResult struct {
+	header    metadata.MD
+	trailer   metadata.MD
+	responses []proto.Message
+	status    *status.Status
+}
+
+// invokeGRPC calls unary or streaming method with generic stream, all request messages are sent before receiving.
+func invokeGRPC(ctx context.Context, conn *grpc.ClientConn, method protoreflect.MethodDescriptor,
+	fullMethod string, requests []proto.Message,
+) *grpcResult {
+	result := &grpcResult{}
+	streamDesc := &grpc.StreamDesc{
+		StreamName:    string(method.Name()),
+		ServerStreams: method.IsStreamingServer(),
+		ClientStreams: method.IsStreamingClient(),
+	}
+	stream, err := conn.NewStream(ctx, streamDesc, fullMethod)
+	if err != nil {
+		result.status = status.Convert(err)
+		return result
+	}
+	for _, req := range requests {
+		// io.EOF means stream is closed by server, status is returned by RecvMsg
+		if err := stream.SendMsg(req); err != nil {
+			if err != io.EOF {
+				result.status = status.Convert(err)
+				return result
+			}
+			break
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		result.status = status.Convert(err)
+		return result
+	}
+	for {
+		resp := dynamicpb.NewMessage(method.Output())
+		err := stream.RecvMsg(resp)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.status = status.Convert(err)
+			break
+		}
+		result.responses = append(result.responses, resp)
+		if !streamDesc.ServerStreams {
+			break
+		}
+	}
+	if result.status == nil {
+		result.status = status.New(0, "")
+	}
+	result.header, _ = stream.Header()
+	result.trailer = stream.Trailer()
+	return result
+}
+
+func newGRPCRespObjMeta(result *grpcResult, method protoreflect.MethodDescriptor, types *dynamicpb.Types,
+) (*grpcRespObjMeta, int64, error) {
+	meta := &grpcRespObjMeta{
+		StatusCode:    int(result.status.Code()),
+		StatusMessage: result.status.Message(),
+		Headers:       grpcMetadataToMap(result.header),
+		Trailers:      grpcMetadataToMap(result.trailer),
+	}
+	var contentSize int64
+	messages := make([]interface{}, 0, len(result.responses))
+	for _, resp := range result.responses {
+		contentSize += int64(proto.Size(resp))
+		content, err := protojson.MarshalOptions{EmitUnpopulated: true, Resolver: types}.Marshal(resp)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "convert grpc response to json failed")
+		}
+		var msg interface{}
+		if err := json.Unmarshal(content, &msg); err != nil {
+			return nil, 0, errors.Wrap(err, "unmarshal grpc response json failed")
+		}
+		messages = append(messages, msg)
+	}
+	if method.IsStreamingServer() {
+		meta.Body = messages
+	} else if len(messages) > 0 {
+		meta.Body = messages[0]
+	}
+	return meta, contentSize, nil
+}
+
+func grpcMetadataToMap(md metadata.MD) map[string]string {
+	m := make(map[string]string)
+	for key, values := range md {
+		if len(values) > 0 {
+			m[key] = values[0]
+		}
+	}
+	return m
+}
+
+func printGRPCMessage(title string, message interface{}) {
+	fmt.Printf("==================== %s ====================\n", title)
+	content, _ := json.MarshalIndent(message, "", "    ")
+	fmt.Println(string(content))
+	fmt.Println("--------------------------------------------------")
+}
@@ -0,0 +1,352 @@
+package hrp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/protoparse"
+)
+
+var greeterProtoPath, _ = filepath.Abs("../examples/data/grpc/greeter.proto")
+
+// newGreeterServer starts gRPC server of examples/data/grpc/greeter.proto with server reflection,
+// messages are handled with dynamic messages.
+func newGreeterServer(t *testing.T) (addr string, calls *int32, stop func()) {
+	parser := &protoparse.Parser{ImportPaths: []string{filepath.Dir(greeterProtoPath)}}
+	fds, err := parser.ParseFiles("greeter.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: fds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, _ := files.FindDescriptorByName("hrp.examples.greeter.Greeter")
+	method := desc.(protoreflect.ServiceDescriptor).Methods().ByName("SayHello")
+	reqDesc, replyDesc := method.Input(), method.Output()
+
+	calls = new(int32)
+	newReply := func(message string, index int) *dynamicpb.Message {
+		reply := dynamicpb.NewMessage(replyDesc)
+		reply.Set(replyDesc.Fields().ByName("message"), protoreflect.ValueOfString(message))
+		reply.Set(replyDesc.Fields().ByName("index"), protoreflect.ValueOfInt32(int32(index)))
+		return reply
+	}
+	recvRequest := func(stream grpc.ServerStream) (name string, count int64, err error) {
+		req := dynamicpb.NewMessage(reqDesc)
+		if err := stream.RecvMsg(req); err != nil {
+			return "", 0, err
+		}
+		return req.Get(reqDesc.Fields().ByName("name")).String(), req.Get(reqDesc.Fields().ByName("count")).Int(), nil
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "hrp.examples.greeter.Greeter",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "SayHello",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error,
+				_ grpc.UnaryServerInterceptor,
+			) (interface{}, error) {
+				atomic.AddInt32(calls, 1)
+				req := dynamicpb.NewMessage(reqDesc)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				name := req.Get(reqDesc.Fields().ByName("name")).String()
+				switch name {
+				case "":
+					return nil, status.Error(codes.InvalidArgument, "name is required")
+				case "slow":
+					select {
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					case <-time.After(time.Second):
+					}
+				}
+				md, _ := metadata.FromIncomingContext(ctx)
+				grpc.SetHeader(ctx, metadata.Pairs("x-request-id", strings.Join(md.Get("x-request-id"), ",")))
+				grpc.SetTrailer(ctx, metadata.Pairs("x-greeting-count", "1"))
+				return newReply("Hello "+name, 1), nil
+			},
+		}},
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    "ListGreetings",
+				ServerStreams: true,
+				Handler: func(_ interface{}, stream grpc.ServerStream) error {
+					name, count, err := recvRequest(stream)
+					if err != nil {
+						return err
+					}
+					for i := 1; i <= int(count); i++ {
+						if err := stream.SendMsg(newReply(fmt.Sprintf("Hello %s #%d", name, i), i)); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				StreamName:    "CollectNames",
+				ClientStreams: true,
+				Handler: func(_ interface{}, stream grpc.ServerStream) error {
+					var names []string
+					for {
+						name, _, err := recvRequest(stream)
+						if err == io.EOF {
+							break
+						}
+						if err != nil {
+							return err
+						}
+						names = append(names, name)
+					}
+					return stream.SendMsg(newReply("Hello "+strings.Join(names, ", "), len(names)))
+				},
+			},
+			{
+				StreamName:    "Chat",
+				ServerStreams: true,
+				ClientStreams: true,
+				Handler: func(_ interface{}, stream grpc.ServerStream) error {
+					for i := 1; ; i++ {
+						name, _, err := recvRequest(stream)
+						if err == io.EOF {
+							return nil
+						}
+						if err != nil {
+							return err
+						}
+						if err := stream.SendMsg(newReply("Hello "+name, i)); err != nil {
+							return err
+						}
+					}
+				},
+			},
+		},
+		Metadata: "greeter.proto",
+	}, struct{}{})
+	rpb.RegisterServerReflectionServer(server, reflection.NewServer(reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: files,
+	}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(ln)
+	return ln.Addr().String(), calls, server.Stop
+}
+
+func TestParseGRPCTarget(t *testing.T) {
+	testData := []struct {
+		target  string
+		address string
+		secure  bool
+	}{
+		{"localhost:50051", "localhost:50051", false},
+		{"grpc://localhost:50051", "localhost:50051", false},
+		{"http://localhost", "localhost:80", false},
+		{"grpcs://example.com", "example.com:443", true},
+		{"https://example.com:8443", "example.com:8443", true},
+	}
+	for _, data := range testData {
+		address, secure, err := parseGRPCTarget(data.target)
+		if !assert.Nil(t, err) || !assert.Equal(t, data.address, address) || !assert.Equal(t, data.secure, secure) {
+			t.Fatal()
+		}
+	}
+	if _, _, err := parseGRPCTarget("ws://localhost"); !assert.Error(t, err) {
+		t.Fatal()
+	}
+
+	for _, method := range []string{"/pkg.Service/Method", "pkg.Service/Method", "pkg.Service.Method"} {
+		service, name, err := splitGRPCMethod(method)
+		if !assert.Nil(t, err) || !assert.Equal(t, "pkg.Service", service) || !assert.Equal(t, "Method", name) {
+			t.Fatal()
+		}
+	}
+	if _, _, err := splitGRPCMethod("Method"); !assert.Error(t, err) {
+		t.Fatal()
+	}
+}
+
+func TestRunStepGRPC(t *testing.T) {
+	addr, _, stop := newGreeterServer(t)
+	defer stop()
+
+	testcase := &TestCase{
+		Config: NewConfig("grpc").
+			SetBaseURL("grpc://" + addr).
+			WithVariables(map[string]interface{}{"user": "hrp", "proto_file": greeterProtoPath}),
+		TestSteps: []IStep{
+			NewStep("unary with proto files").
+				GRPC().
+				Invoke("hrp.examples.greeter.Greeter/SayHello").
+				WithProtoFiles("$proto_file").
+				WithMetadata(map[string]string{"X-Request-Id": "req-1"}).
+				WithBody(map[string]interface{}{"name": "$user"}).
+				Extract().
+				WithJmesPath("body.message", "greeting").
+				Validate().
+				AssertEqual("status_code", 0, "check status code").
+				AssertEqual("body.message", "Hello hrp", "check message").
+				AssertEqual("body.index", 1, "check index").
+				AssertEqual("headers.\"x-request-id\"", "req-1", "check header metadata").
+				AssertEqual("trailers.\"x-greeting-count\"", "1", "check trailer metadata"),
+			NewStep("server streaming with reflection").
+				GRPC().
+				WithTarget(addr).
+				Invoke("hrp.examples.greeter.Greeter.ListGreetings").
+				WithBody(map[string]interface{}{"name": "$greeting", "count": 3}).
+				Validate().
+				AssertLengthEqual("body", 3, "check messages count").
+				AssertEqual("body[2].message", "Hello Hello hrp #3", "check last message"),
+			NewStep("client streaming").
+				GRPC().
+				Invoke("/hrp.examples.greeter.Greeter/CollectNames").
+				WithBody([]interface{}{
+					map[string]interface{}{"name": "a"},
+					map[string]interface{}{"name": "b"},
+				}).
+				Validate().
+				AssertEqual("body.message", "Hello a, b", "check message").
+				AssertEqual("body.index", 2, "check names count"),
+			NewStep("bidirectional streaming").
+				GRPC().
+				Invoke("hrp.examples.greeter.Greeter/Chat").
+				WithBody([]interface{}{
+					map[string]interface{}{"name": "a"},
+					map[string]interface{}{"name": "b"},
+				}).
+				Validate().
+				AssertLengthEqual("body", 2, "check messages count").
+				AssertEqual("body[1].message", "Hello b", "check message"),
+			NewStep("status error").
+				GRPC().
+				Invoke("hrp.examples.greeter.Greeter/SayHello").
+				Validate().
+				AssertEqual("status_code", int(codes.InvalidArgument), "check status code").
+				AssertEqual("status_message", "name is required", "check status message"),
+			NewStep("deadline exceeded").
+				GRPC().
+				Invoke("hrp.examples.greeter.Greeter/SayHello").
+				WithBody(map[string]interface{}{"name": "slow"}).
+				WithTimeout(0.1).
+				Validate().
+				AssertEqual("status_code", int(codes.DeadlineExceeded), "check status code"),
+		},
+	}
+	caseRunner, err := NewRunner(t).NewCaseRunner(testcase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionRunner := caseRunner.NewSession()
+	if err := sessionRunner.Start(nil); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	record := summary.Records[0]
+	if !assert.Equal(t, stepTypeGRPC, record.StepType) ||
+		!assert.Equal(t, "Hello hrp", record.ExportVars["greeting"]) ||
+		!assert.Greater(t, record.ContentSize, int64(0)) {
+		t.Fatal()
+	}
+
+	// deadline falls back to request timeout of config
+	testcase = &TestCase{
+		Config: NewConfig("grpc").SetBaseURL("grpc://" + addr).SetRequestTimeout(0.1),
+		TestSteps: []IStep{
+			NewStep("deadline exceeded by request timeout").
+				GRPC().
+				Invoke("hrp.examples.greeter.Greeter/SayHello").
+				WithBody(map[string]interface{}{"name": "slow"}).
+				Validate().
+				AssertEqual("status_code", int(codes.DeadlineExceeded), "check status code"),
+		},
+	}
+	runner := NewRunner(t)
+	if err := runner.Run(testcase); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	// connections are closed after running
+	if !assert.Empty(t, runner.grpcConns) {
+		t.Fatal()
+	}
+
+	// unknown method
+	testcase = &TestCase{
+		Config: NewConfig("grpc").SetBaseURL("grpc://" + addr),
+		TestSteps: []IStep{
+			NewStep("unknown method").
+				GRPC().
+				Invoke("hrp.examples.greeter.Greeter/Unknown"),
+		},
+	}
+	err = NewRunner(t).Run(testcase)
+	if !assert.Error(t, err) || !assert.Contains(t, err.Error(), "grpc method Unknown not found") {
+		t.Fatal()
+	}
+}
+
+func TestBoomerGRPC(t *testing.T) {
+	addr, calls, stop := newGreeterServer(t)
+	defer stop()
+
+	testcase := &TestCase{
+		Config: NewConfig("grpc load").SetBaseURL("grpc://" + addr),
+		TestSteps: []IStep{
+			NewStep("say hello").
+				GRPC().
+				Invoke("hrp.examples.greeter.Greeter/SayHello").
+				WithBody(map[string]interface{}{"name": "boomer"}).
+				Validate().
+				AssertEqual("body.message", "Hello boomer", "check message"),
+		},
+	}
+	b := NewStandaloneBoomer(2, 2)
+	done := make(chan struct{})
+	go func() {
+		b.Run(testcase)
+		close(done)
+	}()
+	time.Sleep(2 * time.Second)
+	b.Quit()
+	if !assert.Greater(t, atomic.LoadInt32(calls), int32(0)) {
+		t.Fatal()
+	}
+
+	// connections are closed after load testing stopped
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("boomer not stopped")
+	}
+	b.hrpRunner.grpcMutex.Lock()
+	defer b.hrpRunner.grpcMutex.Unlock()
+	if !assert.Empty(t, b.hrpRunner.grpcConns) {
+		t.Fatal()
+	}
+}
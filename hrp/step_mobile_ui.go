@@ -652,7 +652,7 @@ func runStepMobileUI(s *SessionRunner, step *TStep) (stepResult *StepResult, err
 	// run actions
 	for _, action := range actions {
 		select {
-		case <-s.caseRunner.hrpRunner.caseTimeoutTimer.C:
+		case <-s.caseTimeoutTimer.C:
 			log.Warn().Msg("timeout in mobile UI runner")
 			return stepResult, errors.Wrap(code.TimeoutError, "mobile UI runner timeout")
 		case <-s.caseRunner.hrpRunner.interruptSignal:
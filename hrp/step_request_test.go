@@ -1,10 +1,20 @@
 package hrp
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/code"
 )
 
 var (
@@ -212,3 +222,431 @@ func TestRunCaseWithTimeout(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestRunCaseWithStepTimeoutOverConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	newTestCase := func(stepTimeout time.Duration) *TestCase {
+		return &TestCase{
+			Config: NewConfig("TestCase").
+				SetRequestTimeout(0.2).
+				SetBaseURL(ts.URL),
+			TestSteps: []IStep{
+				NewStep("delay").
+					GET("/delay").
+					SetTimeout(stepTimeout).
+					Validate().
+					AssertEqual("status_code", 200, "check status code"),
+			},
+		}
+	}
+
+	// step timeout could be longer than request timeout of config
+	if err := NewRunner(t).Run(newTestCase(2 * time.Second)); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if err := NewRunner(t).Run(newTestCase(0)); !assert.Error(t, err) {
+		t.FailNow()
+	}
+}
+
+func TestRunRequestWithRetry(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail for the first two attempts
+		if atomic.AddInt32(&count, 1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer ts.Close()
+
+	testcase := &TestCase{
+		Config: NewConfig("retry on status").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("get with retry").
+				GET("/get").
+				Retry(3, 100*time.Millisecond, WithRetryBackoff(2), WithRetryOnStatus(502)).
+				Validate().
+				AssertEqual("status_code", 200, "check status code").
+				AssertEqual("body.status", "ok", "check body"),
+		},
+	}
+	sessionRunner, err := NewRunner(t).NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	start := time.Now()
+	err = sessionRunner.NewSession().Start(nil)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	// backoff intervals: 100ms + 200ms
+	if !assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond) {
+		t.Fatal()
+	}
+	if !assert.EqualValues(t, 3, atomic.LoadInt32(&count)) {
+		t.Fatal()
+	}
+
+	// retry until validators pass, exhausted retries should fail the step
+	atomic.StoreInt32(&count, 0)
+	testcase = &TestCase{
+		Config: NewConfig("retry until").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("get with retry").
+				GET("/get").
+				Retry(1, 0).
+				Validate().
+				AssertEqual("status_code", 200, "check status code"),
+		},
+	}
+	sessionRunner, err = NewRunner(nil).NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	session := sessionRunner.NewSession()
+	err = session.Start(nil)
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+	if !assert.EqualValues(t, 2, atomic.LoadInt32(&count)) {
+		t.Fatal()
+	}
+	summary, _ := session.GetSummary()
+	if !assert.Len(t, summary.Records[0].Data.(*SessionData).Retries, 1) {
+		t.Fatal()
+	}
+}
+
+func TestRunRequestRetryWithNetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	// requests of all attempts are kept in summary when the final attempt failed
+	testcase := &TestCase{
+		Config: NewConfig("retry on network error").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("get with retry").
+				GET("/get").
+				Retry(2, 10*time.Millisecond),
+		},
+	}
+	caseRunner, err := NewRunner(nil).NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	session := caseRunner.NewSession()
+	if !assert.Error(t, session.Start(nil)) {
+		t.Fatal()
+	}
+	summary, _ := session.GetSummary()
+	sessionData := summary.Records[0].Data.(*SessionData)
+	if !assert.Len(t, sessionData.Retries, 2) ||
+		!assert.Contains(t, sessionData.ReqResps.Response, "error") {
+		t.Fatal()
+	}
+
+	// retry interval is interrupted by case timeout
+	testcase = &TestCase{
+		Config: NewConfig("retry with case timeout").SetBaseURL(ts.URL).SetCaseTimeout(0.2),
+		TestSteps: []IStep{
+			NewStep("get with retry").
+				GET("/get").
+				Retry(3, 10*time.Second),
+		},
+	}
+	start := time.Now()
+	err = NewRunner(nil).Run(testcase)
+	if !assert.True(t, errors.Is(err, code.TimeoutError)) || !assert.Less(t, time.Since(start), 5*time.Second) {
+		t.Fatal()
+	}
+}
+
+func TestRunRequestWaitUntil(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&count, 1) <= 2 {
+			w.Write([]byte(`{"status": "running"}`))
+			return
+		}
+		w.Write([]byte(`{"status": "done"}`))
+	}))
+	defer ts.Close()
+
+	testcase := &TestCase{
+		Config: NewConfig("wait until job done").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("poll job status").
+				GET("/job").
+				WaitUntil(nil, 2*time.Second, 50*time.Millisecond).
+				Validate().
+				AssertEqual("body.status", "done", "check job status"),
+		},
+	}
+	err := NewRunner(t).Run(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	if !assert.EqualValues(t, 3, atomic.LoadInt32(&count)) {
+		t.Fatal()
+	}
+
+	// job never reaches expected status, timeout
+	testcase = &TestCase{
+		Config: NewConfig("wait until timeout").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("poll job status").
+				GET("/job").
+				WaitUntil([]Validator{
+					{Check: "body.status", Assert: "equals", Expect: "unknown"},
+				}, 200*time.Millisecond, 50*time.Millisecond),
+		},
+	}
+	err = NewRunner(nil).Run(testcase)
+	if !assert.True(t, errors.Is(err, code.TimeoutError)) {
+		t.Fatal()
+	}
+}
+
+func TestRunRequestAssertJSONSchema(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"users": [{"id": 1, "name": "leo"}, {"id": "2"}]}`))
+	}))
+	defer ts.Close()
+
+	schemaPath := filepath.Join(t.TempDir(), "user.json")
+	err := os.WriteFile(schemaPath, []byte(`{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {"id": {"type": "integer"}, "name": {"type": "string"}}
+	}`), 0o644)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	testcase := &TestCase{
+		Config: NewConfig("json schema").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("list users").
+				GET("/users").
+				Validate().
+				AssertJSONSchema("body", map[string]interface{}{
+					"type":     "object",
+					"required": []string{"users"},
+					"properties": map[string]interface{}{
+						"users": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/user"}},
+					},
+					"$defs": map[string]interface{}{"user": map[string]interface{}{"type": "object"}},
+				}, "check body schema").
+				AssertJSONSchema("body.users[0]", schemaPath, "check first user").
+				AssertJSONSchema("body.users[1]", schemaPath, "check second user"),
+		},
+	}
+	sessionRunner, err := NewRunner(nil).NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	session := sessionRunner.NewSession()
+	err = session.Start(nil)
+	if !assert.Error(t, err) {
+		t.Fatal()
+	}
+	summary, _ := session.GetSummary()
+	validators := summary.Records[0].Data.(*SessionData).Validators
+	if !assert.Len(t, validators, 3) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "pass", validators[1].CheckResult) || !assert.Equal(t, "fail", validators[2].CheckResult) {
+		t.Fatal()
+	}
+	var violations []string
+	for _, v := range validators[2].Violations {
+		violations = append(violations, v.String())
+	}
+	if !assert.ElementsMatch(t, []string{
+		"#: missing properties: 'name'",
+		"#/id: expected integer, but got string",
+	}, violations) {
+		t.Fatal()
+	}
+}
+
+func TestRunRequestWithOpenAPI(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/v1/pets/2") {
+			// drift from spec: id is string and name is missing
+			w.Write([]byte(`{"id": "2"}`))
+			return
+		}
+		w.Write([]byte(`{"id": 1, "name": "kitty"}`))
+	}))
+	defer ts.Close()
+
+	specPath, _ := filepath.Abs("pkg/openapi/testdata/petstore_v3.yaml")
+	testcase := &TestCase{
+		Config: NewConfig("openapi contract").
+			SetBaseURL(ts.URL).
+			SetOpenAPI(specPath),
+		TestSteps: []IStep{
+			NewStep("get pet").
+				GET("/v1/pets/1").
+				Validate().
+				AssertEqual("status_code", 200, "check status code"),
+			NewStep("get pet with drift").
+				GET("/v1/pets/2"),
+		},
+	}
+	caseRunner, err := NewRunner(nil).SetFailfast(false).NewCaseRunner(testcase)
+	if !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	session := caseRunner.NewSession()
+	if !assert.Nil(t, session.Start(nil)) {
+		t.Fatal()
+	}
+	summary, _ := session.GetSummary()
+	if !assert.False(t, summary.Success) {
+		t.Fatal()
+	}
+
+	validators := summary.Records[0].Data.(*SessionData).Validators
+	if !assert.Len(t, validators, 2) || !assert.Equal(t, "pass", validators[1].CheckResult) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "GET /pets/{petId}", validators[1].Expect) {
+		t.Fatal()
+	}
+
+	validators = summary.Records[1].Data.(*SessionData).Validators
+	if !assert.Len(t, validators, 1) || !assert.Equal(t, "fail", validators[0].CheckResult) {
+		t.Fatal()
+	}
+	var violations []string
+	for _, v := range validators[0].Violations {
+		violations = append(violations, v.Path+" "+v.Keyword)
+	}
+	if !assert.ElementsMatch(t, []string{"response.body# required", "response.body#/id type"}, violations) {
+		t.Fatal()
+	}
+}
+
+func TestRunRequestAssertSnapshot(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		n := atomic.AddInt32(&count, 1)
+		if r.URL.Query().Get("drift") == "true" {
+			w.Write([]byte(`{"id": 1, "name": "changed"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"id": %d, "name": "leo", "created_at": "%s"}`, n, time.Now().Format(time.RFC3339Nano))
+	}))
+	defer ts.Close()
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "proj.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	newTestCase := func(drift string) *TestCase {
+		config := NewConfig("snapshot case").SetBaseURL(ts.URL)
+		config.Path = projectDir
+		return &TestCase{
+			Config: config,
+			TestSteps: []IStep{
+				NewStep("get user").
+					GET("/user").
+					WithParams(map[string]interface{}{"drift": drift}).
+					Validate().
+					AssertSnapshot("body", "user", "id", "created_at"),
+			},
+		}
+	}
+
+	// first run creates snapshot, second run compares with it
+	for i := 0; i < 2; i++ {
+		if !assert.Nil(t, NewRunner(t).Run(newTestCase("false"))) {
+			t.Fatal()
+		}
+	}
+	snapshotPath := filepath.Join(projectDir, "results", "snapshots", "snapshot_case", "user.json")
+	if !assert.FileExists(t, snapshotPath) {
+		t.Fatal()
+	}
+
+	// response drifts from snapshot
+	if !assert.Error(t, NewRunner(nil).Run(newTestCase("true"))) {
+		t.Fatal()
+	}
+
+	// update snapshots
+	if !assert.Nil(t, NewRunner(t).SetUpdateSnapshots(true).Run(newTestCase("true"))) {
+		t.Fatal()
+	}
+	content, _ := os.ReadFile(snapshotPath)
+	if !assert.Contains(t, string(content), "changed") {
+		t.Fatal()
+	}
+}
+
+func TestRunRequestAssertSnapshotWithParameters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"user": "%s", "item": "%s"}`, r.URL.Query().Get("user"), r.URL.Query().Get("item"))
+	}))
+	defer ts.Close()
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "proj.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := NewConfig("snapshot parameters").
+		SetBaseURL(ts.URL).
+		WithParameters(map[string]interface{}{
+			"user": []interface{}{"leo", "debugtalk"},
+		})
+	config.Path = projectDir
+	testcase := &TestCase{
+		Config: config,
+		TestSteps: []IStep{
+			NewStep("get user").
+				GET("/user").
+				WithParams(map[string]interface{}{"user": "$user"}).
+				Validate().
+				AssertSnapshot("body", "user"),
+			NewStep("get item").
+				Foreach([]interface{}{"a", "b"}, "item").
+				GET("/item").
+				WithParams(map[string]interface{}{"user": "$user", "item": "$item"}).
+				Validate().
+				AssertSnapshot("body", "item"),
+		},
+	}
+
+	// parameterized sessions running concurrently do not share snapshots, second run compares with them
+	for i := 0; i < 2; i++ {
+		if !assert.Nil(t, NewRunner(t).SetConcurrency(2).Run(testcase)) {
+			t.Fatal()
+		}
+	}
+	snapshotDir := filepath.Join(projectDir, "results", "snapshots", "snapshot_parameters")
+	for name, expected := range map[string]string{
+		"user_params_1.json":           "leo",
+		"user_params_2.json":           "debugtalk",
+		"item_params_1_foreach_1.json": `"a"`,
+		"item_params_2_foreach_2.json": `"b"`,
+	} {
+		content, err := os.ReadFile(filepath.Join(snapshotDir, name))
+		if !assert.Nil(t, err) || !assert.Contains(t, string(content), expected) {
+			t.Fatal(name)
+		}
+	}
+}
@@ -0,0 +1,508 @@
+package hrp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
+	"github.com/httprunner/httprunner/v4/hrp/internal/json"
+)
+
+// SSE stop reasons, exposed as stop_reason in response object
+const (
+	sseStopMaxEvents = "max_events" // collected max events
+	sseStopUntil     = "until"      // matching event arrived
+	sseStopEOF       = "eof"        // stream closed by server
+	sseStopTimeout   = "timeout"    // timeout reached
+)
+
+// SSEAction represents Server-Sent Events step, the event stream is read until
+// max events are collected, a matching event arrives, the stream is closed or timeout.
+type SSEAction struct {
+	Method    HTTPMethod             `json:"method,omitempty" yaml:"method,omitempty"` // default GET
+	URL       string                 `json:"url" yaml:"url"`
+	Params    map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	Headers   map[string]string      `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Cookies   map[string]string      `json:"cookies,omitempty" yaml:"cookies,omitempty"`
+	Body      interface{}            `json:"body,omitempty" yaml:"body,omitempty"`
+	Verify    bool                   `json:"verify,omitempty" yaml:"verify,omitempty"`
+	TLS       *TLSConfig             `json:"tls,omitempty" yaml:"tls,omitempty"`               // TLS settings overriding config, e.g. client certificate
+	Auth      *AuthConfig            `json:"auth,omitempty" yaml:"auth,omitempty"`             // auth scheme overriding config
+	Proxies   map[string]string      `json:"proxies,omitempty" yaml:"proxies,omitempty"`       // proxies merged into config proxies
+	MaxEvents int64                  `json:"max_events,omitempty" yaml:"max_events,omitempty"` // stop after max events collected, 0 means no limit
+	Until     *SSEUntil              `json:"until,omitempty" yaml:"until,omitempty"`           // stop when matching event arrives
+	Timeout   int64                  `json:"timeout,omitempty" yaml:"timeout,omitempty"`       // stream timeout in milliseconds, default 30s
+}
+
+// request converts SSE action to request, which is prepared in the same way as request step.
+func (s *SSEAction) request() *Request {
+	method := s.Method
+	if method == "" {
+		method = httpGET
+	}
+	return &Request{
+		Method:  method,
+		URL:     s.URL,
+		Params:  s.Params,
+		Headers: s.Headers,
+		Cookies: s.Cookies,
+		Body:    s.Body,
+		Verify:  s.Verify,
+		TLS:     s.TLS,
+		Auth:    s.Auth,
+		Proxies: s.Proxies,
+	}
+}
+
+// SSEUntil represents the event to stop reading stream, all specified fields should match.
+type SSEUntil struct {
+	Event string `json:"event,omitempty" yaml:"event,omitempty"` // event type equals
+	Data  string `json:"data,omitempty" yaml:"data,omitempty"`   // event data contains
+}
+
+func (s *SSEUntil) match(event *sseEvent) bool {
+	if s == nil || (s.Event == "" && s.Data == "") {
+		return false
+	}
+	if s.Event != "" && s.Event != event.Event {
+		return false
+	}
+	return s.Data == "" || strings.Contains(event.rawData, s.Data)
+}
+
+func (s *SSEAction) GetTimeout() int64 {
+	if s.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return s.Timeout
+}
+
+// StepSSE implements IStep interface.
+type StepSSE struct {
+	step *TStep
+}
+
+func (s *StepSSE) Name() string {
+	if s.step.Name != "" {
+		return s.step.Name
+	}
+	return fmt.Sprintf("sse %s", s.step.SSE.URL)
+}
+
+func (s *StepSSE) Type() StepType {
+	return stepTypeSSE
+}
+
+func (s *StepSSE) Struct() *TStep {
+	return s.step
+}
+
+func (s *StepSSE) Run(r *SessionRunner) (*StepResult, error) {
+	return runStepSSE(r, s.step)
+}
+
+// GET opens event stream with GET method.
+func (s *StepSSE) GET(url string) *StepSSE {
+	s.step.SSE.Method = httpGET
+	s.step.SSE.URL = url
+	return s
+}
+
+// POST opens event stream with POST method, e.g. streaming completion APIs.
+func (s *StepSSE) POST(url string) *StepSSE {
+	s.step.SSE.Method = httpPOST
+	s.step.SSE.URL = url
+	return s
+}
+
+func (s *StepSSE) WithParams(params map[string]interface{}) *StepSSE {
+	s.step.SSE.Params = params
+	return s
+}
+
+func (s *StepSSE) WithHeaders(headers map[string]string) *StepSSE {
+	s.step.SSE.Headers = headers
+	return s
+}
+
+func (s *StepSSE) WithCookies(cookies map[string]string) *StepSSE {
+	s.step.SSE.Cookies = cookies
+	return s
+}
+
+func (s *StepSSE) WithBody(body interface{}) *StepSSE {
+	s.step.SSE.Body = body
+	return s
+}
+
+// SetVerify sets whether to verify SSL for event stream request.
+func (s *StepSSE) SetVerify(verify bool) *StepSSE {
+	s.step.SSE.Verify = verify
+	return s
+}
+
+// SetTLS sets TLS settings for event stream request, which override TLS settings in config.
+func (s *StepSSE) SetTLS(tlsConfig *TLSConfig) *StepSSE {
+	s.step.SSE.TLS = tlsConfig
+	return s
+}
+
+// SetAuth sets auth scheme for event stream request, which overrides auth in config.
+func (s *StepSSE) SetAuth(auth *AuthConfig) *StepSSE {
+	s.step.SSE.Auth = auth
+	return s
+}
+
+// SetProxies sets proxies for event stream request, overriding config proxies with the same keys.
+func (s *StepSSE) SetProxies(proxies map[string]string) *StepSSE {
+	s.step.SSE.Proxies = proxies
+	return s
+}
+
+// WithMaxEvents stops reading stream after max events collected.
+func (s *StepSSE) WithMaxEvents(maxEvents int64) *StepSSE {
+	s.step.SSE.MaxEvents = maxEvents
+	return s
+}
+
+// WithTimeout sets stream timeout in milliseconds.
+func (s *StepSSE) WithTimeout(timeout int64) *StepSSE {
+	s.step.SSE.Timeout = timeout
+	return s
+}
+
+// Until stops reading stream when event type equals event and event data contains data,
+// empty event or data matches any.
+func (s *StepSSE) Until(event, data string) *StepSSE {
+	s.step.SSE.Until = &SSEUntil{
+		Event: event,
+		Data:  data,
+	}
+	return s
+}
+
+// Validate switches to step validation.
+func (s *StepSSE) Validate() *StepRequestValidation {
+	return &StepRequestValidation{
+		step: s.step,
+	}
+}
+
+// Extract switches to step extraction.
+func (s *StepSSE) Extract() *StepRequestExtraction {
+	s.step.Extract = make(map[string]string)
+	return &StepRequestExtraction{
+		step: s.step,
+	}
+}
+
+// sseEvent represents one dispatched event of event stream, data is parsed as JSON if possible.
+type sseEvent struct {
+	ID      string      `json:"id,omitempty"`
+	Event   string      `json:"event"`
+	Data    interface{} `json:"data"`
+	Retry   int64       `json:"retry,omitempty"`
+	Latency int64       `json:"latency_ms"` // elapsed since request sent in millisecond(ms)
+	rawData string
+}
+
+// sseRespObjMeta represents response of SSE step, events could be extracted by events[0].data
+type sseRespObjMeta struct {
+	httpRespObjMeta
+	Events     []*sseEvent `json:"events"`
+	StopReason string      `json:"stop_reason"`
+}
+
+func runStepSSE(r *SessionRunner, step *TStep) (stepResult *StepResult, err error) {
+	stepResult = &StepResult{
+		Name:        step.Name,
+		StepType:    stepTypeSSE,
+		Success:     false,
+		ContentSize: 0,
+	}
+
+	// merge step variables with session variables
+	stepVariables, err := r.ParseStepVariables(step.Variables)
+	if err != nil {
+		err = errors.Wrap(err, "parse step variables failed")
+		return
+	}
+
+	defer func() {
+		// update testcase summary
+		if err != nil {
+			stepResult.Attachments = err.Error()
+		}
+	}()
+
+	sessionData := newSessionData()
+	parser := r.caseRunner.parser
+
+	rb, err := prepareRequest(r, step.SSE.request(), stepVariables, sessionData)
+	if err != nil {
+		return
+	}
+	if rb.req.Header.Get("Accept") == "" {
+		rb.req.Header.Set("Accept", "text/event-stream")
+	}
+	if rb.req.Header.Get("Cache-Control") == "" {
+		rb.req.Header.Set("Cache-Control", "no-cache")
+	}
+	rb.updateHeaders()
+	// stream lasts until step timeout, client timeout should not interrupt reading
+	rb.client.Timeout = 0
+
+	until, err := parseSSEUntil(parser, step.SSE.Until, stepVariables)
+	if err != nil {
+		return
+	}
+
+	// add request object to step variables, could be used in setup hooks
+	stepVariables["hrp_step_name"] = step.Name
+	stepVariables["hrp_step_request"] = rb.requestMap
+
+	// deal with setup hooks
+	for _, setupHook := range step.SetupHooks {
+		_, err = parser.Parse(setupHook, stepVariables)
+		if err != nil {
+			return stepResult, errors.Wrap(err, "run setup hooks failed")
+		}
+	}
+
+	if r.caseRunner.hrpRunner.requestsLogOn {
+		if err = printRequest(rb.req); err != nil {
+			return stepResult, err
+		}
+	}
+
+	// stream is closed if testcase timeout or interrupted
+	ctx, stop := r.newStepContext(context.Background())
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(step.SSE.GetTimeout())*time.Millisecond)
+	defer cancel()
+	rb.req = rb.req.WithContext(ctx)
+
+	log.Info().Int64("timeout(ms)", step.SSE.GetTimeout()).Int64("maxEvents", step.SSE.MaxEvents).
+		Str("url", rb.req.URL.String()).Msg("open sse stream")
+	start := time.Now()
+	resp, err := rb.do(r, stepVariables, sessionData)
+	if err != nil {
+		if stopErr := stop(); stopErr != nil {
+			return stepResult, stopErr
+		}
+		return stepResult, errors.Wrap(err, "open sse stream failed")
+	}
+	defer resp.Body.Close()
+	httpStat := map[string]int64{
+		"ResponseHeader": time.Since(start).Milliseconds(),
+	}
+
+	err = decodeResponseBody(resp)
+	if err != nil {
+		return stepResult, errors.Wrap(err, "decode response body failed")
+	}
+	if r.caseRunner.hrpRunner.requestsLogOn {
+		if err = printResponseWithBody(resp, false); err != nil {
+			return stepResult, err
+		}
+	}
+
+	body := &countingReader{reader: resp.Body}
+	meta := sseRespObjMeta{
+		httpRespObjMeta: newHttpRespObjMeta(resp, nil),
+		Events:          []*sseEvent{},
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		// not an event stream, e.g. error response, keep body for validation
+		var respBody []byte
+		respBody, err = io.ReadAll(body)
+		if err != nil && ctx.Err() == nil {
+			return stepResult, errors.Wrap(err, "read response body failed")
+		}
+		meta.Body = parseSSEData(string(respBody))
+		meta.StopReason = sseStopEOF
+	} else {
+		meta.Events, meta.StopReason, err = readSSEEvents(ctx, body, step.SSE.MaxEvents, until, start)
+		if err != nil {
+			return stepResult, errors.Wrap(err, "read sse events failed")
+		}
+	}
+	if err = stop(); err != nil {
+		return stepResult, err
+	}
+	for i, event := range meta.Events {
+		httpStat[fmt.Sprintf("Event%d", i+1)] = event.Latency
+		if r.caseRunner.hrpRunner.requestsLogOn {
+			fmt.Printf("event: %s\r\ndata: %s\r\nlatency: %dms\r\n\r\n", event.Event, event.rawData, event.Latency)
+		}
+	}
+	httpStat["Total"] = time.Since(start).Milliseconds()
+	log.Info().Int("events", len(meta.Events)).Str("stopReason", meta.StopReason).Msg("close sse stream")
+
+	stepResult.Elapsed = httpStat["Total"]
+	stepResult.HttpStat = httpStat
+	stepResult.ContentSize = body.size
+
+	respObj, err := convertToResponseObject(r.caseRunner.hrpRunner.t, parser, meta)
+	if err != nil {
+		err = errors.Wrap(err, "init ResponseObject error")
+		return
+	}
+
+	// add response object to step variables, could be used in teardown hooks
+	stepVariables["hrp_step_response"] = respObj.respObjMeta
+
+	// deal with teardown hooks
+	for _, teardownHook := range step.TeardownHooks {
+		_, err = parser.Parse(teardownHook, stepVariables)
+		if err != nil {
+			return stepResult, errors.Wrap(err, "run teardown hooks failed")
+		}
+	}
+
+	sessionData.ReqResps.Request = rb.requestMap
+	sessionData.ReqResps.Response = builtin.FormatResponse(respObj.respObjMeta)
+
+	// extract variables from response
+	extractMapping := respObj.Extract(step.Extract, stepVariables)
+	stepResult.ExportVars = extractMapping
+
+	// override step variables with extracted variables
+	stepVariables = mergeVariables(stepVariables, extractMapping)
+
+	// validate response
+	err = respObj.Validate(step.Validators, stepVariables)
+	sessionData.Validators = respObj.validationResults
+	if err == nil {
+		sessionData.Success = true
+		stepResult.Success = true
+	}
+	stepResult.Data = sessionData
+
+	return stepResult, err
+}
+
+func parseSSEUntil(parser *Parser, until *SSEUntil, variables map[string]interface{}) (*SSEUntil, error) {
+	if until == nil {
+		return nil, nil
+	}
+	parsed := *until
+	for _, field := range []*string{&parsed.Event, &parsed.Data} {
+		if *field == "" {
+			continue
+		}
+		value, err := parser.ParseString(*field, variables)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse sse until failed")
+		}
+		*field = convertString(value)
+	}
+	return &parsed, nil
+}
+
+// readSSEEvents reads events from stream in text/event-stream format,
+// ref: https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+func readSSEEvents(ctx context.Context, body io.Reader, maxEvents int64, until *SSEUntil,
+	start time.Time,
+) (events []*sseEvent, stopReason string, err error) {
+	events = []*sseEvent{}
+	reader := bufio.NewReader(body)
+	var eventType, lastEventID string
+	var data []string
+	var retry int64
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil {
+			// incomplete event at the end of stream is discarded
+			if ctx.Err() != nil {
+				return events, sseStopTimeout, nil
+			}
+			if readErr == io.EOF {
+				return events, sseStopEOF, nil
+			}
+			return events, "", readErr
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		// dispatch event on blank line
+		if line == "" {
+			if len(data) == 0 {
+				eventType = ""
+				continue
+			}
+			event := &sseEvent{
+				ID:      lastEventID,
+				Event:   eventType,
+				Retry:   retry,
+				Latency: time.Since(start).Milliseconds(),
+				rawData: strings.Join(data, "\n"),
+			}
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			event.Data = parseSSEData(event.rawData)
+			events = append(events, event)
+			eventType, data, retry = "", nil, 0
+
+			if until.match(event) {
+				return events, sseStopUntil, nil
+			}
+			if maxEvents > 0 && int64(len(events)) >= maxEvents {
+				return events, sseStopMaxEvents, nil
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comment line, e.g. keep-alive
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				lastEventID = value
+			}
+		case "retry":
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+				retry = v
+			}
+		}
+	}
+}
+
+// parseSSEData parses event data as JSON, raw string is used if it is not JSON.
+func parseSSEData(data string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return data
+	}
+	return v
+}
+
+// countingReader counts bytes read from stream.
+type countingReader struct {
+	reader io.Reader
+	size   int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.size += int64(n)
+	return n, err
+}
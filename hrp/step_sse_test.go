@@ -0,0 +1,159 @@
+package hrp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/httprunner/httprunner/v4/hrp/internal/code"
+)
+
+func TestReadSSEEvents(t *testing.T) {
+	stream := ": keep-alive\r\n" +
+		"id: 1\r\nretry: 3000\r\ndata: line1\r\ndata: line2\r\n\r\n" +
+		"event: update\ndata:{\"count\": 1}\n\n" +
+		"event: empty\n\n" +
+		"data: incomplete"
+	events, stopReason, err := readSSEEvents(context.Background(), strings.NewReader(stream), 0, nil, time.Now())
+	if !assert.Nil(t, err) || !assert.Equal(t, sseStopEOF, stopReason) || !assert.Len(t, events, 2) {
+		t.Fatal()
+	}
+	if !assert.Equal(t, "1", events[0].ID) || !assert.Equal(t, "message", events[0].Event) ||
+		!assert.Equal(t, "line1\nline2", events[0].Data) || !assert.Equal(t, int64(3000), events[0].Retry) {
+		t.Fatal()
+	}
+	// last event id is kept for following events
+	if !assert.Equal(t, "1", events[1].ID) || !assert.Equal(t, "update", events[1].Event) ||
+		!assert.Equal(t, map[string]interface{}{"count": float64(1)}, events[1].Data) {
+		t.Fatal()
+	}
+
+	events, stopReason, _ = readSSEEvents(context.Background(), strings.NewReader(stream), 1, nil, time.Now())
+	if !assert.Equal(t, sseStopMaxEvents, stopReason) || !assert.Len(t, events, 1) {
+		t.Fatal()
+	}
+	events, stopReason, _ = readSSEEvents(context.Background(), strings.NewReader(stream), 0,
+		&SSEUntil{Data: "count"}, time.Now())
+	if !assert.Equal(t, sseStopUntil, stopReason) || !assert.Len(t, events, 2) {
+		t.Fatal()
+	}
+}
+
+func TestRunStepSSE(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "unauthorized"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "id: %d\ndata: {\"seq\": %d, \"prompt\": \"%s\"}\n\n", i, i, r.URL.Query().Get("prompt"))
+			flusher.Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+		fmt.Fprint(w, "event: done\ndata: [DONE]\n\n")
+		flusher.Flush()
+		// keep stream open until client disconnects
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	testcase := &TestCase{
+		Config: NewConfig("sse").
+			SetBaseURL(ts.URL).
+			SetAuth(NewBearerAuth("token")),
+		TestSteps: []IStep{
+			NewStep("until done event").
+				SSE().
+				GET("/stream").
+				WithParams(map[string]interface{}{"prompt": "hello"}).
+				Until("done", "").
+				Extract().
+				WithJmesPath("events[0].data.prompt", "prompt").
+				Validate().
+				AssertEqual("status_code", 200, "check status code").
+				AssertEqual("stop_reason", "until", "check stop reason").
+				AssertLengthEqual("events", 4, "check events count").
+				AssertEqual("events[2].data.seq", 3, "check event data").
+				AssertEqual("events[3].data", "[DONE]", "check done event"),
+			NewStep("max events").
+				SSE().
+				GET("/stream").
+				WithMaxEvents(2).
+				Validate().
+				AssertEqual("stop_reason", "max_events", "check stop reason").
+				AssertEqual("events[-1].id", "2", "check last event id"),
+			NewStep("timeout").
+				SSE().
+				GET("/stream").
+				WithTimeout(500).
+				Validate().
+				AssertEqual("stop_reason", "timeout", "check stop reason").
+				AssertLengthEqual("events", 4, "check events count"),
+		},
+	}
+	caseRunner, _ := NewRunner(t).NewCaseRunner(testcase)
+	sessionRunner := caseRunner.NewSession()
+	if err := sessionRunner.Start(nil); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+	summary, _ := sessionRunner.GetSummary()
+	record := summary.Records[0]
+	if !assert.Equal(t, stepTypeSSE, record.StepType) ||
+		!assert.Equal(t, "hello", record.ExportVars["prompt"]) {
+		t.Fatal()
+	}
+	for _, key := range []string{"ResponseHeader", "Event1", "Event4", "Total"} {
+		if !assert.Contains(t, record.HttpStat, key) {
+			t.Fatal()
+		}
+	}
+	if !assert.GreaterOrEqual(t, record.HttpStat["Event4"], record.HttpStat["Event1"]) {
+		t.Fatal()
+	}
+
+	// error response is not event stream
+	testcase = &TestCase{
+		Config: NewConfig("sse unauthorized").SetBaseURL(ts.URL),
+		TestSteps: []IStep{
+			NewStep("unauthorized").
+				SSE().
+				GET("/stream").
+				Validate().
+				AssertEqual("status_code", 401, "check status code").
+				AssertEqual("body.error", "unauthorized", "check error body").
+				AssertLengthEqual("events", 0, "check no events"),
+		},
+	}
+	if err := NewRunner(t).Run(testcase); !assert.Nil(t, err) {
+		t.Fatal()
+	}
+
+	// stream is closed when testcase timeout, step timeout is not reached
+	testcase = &TestCase{
+		Config: NewConfig("sse case timeout").
+			SetBaseURL(ts.URL).
+			SetAuth(NewBearerAuth("token")),
+		TestSteps: []IStep{
+			NewStep("long stream").
+				SSE().
+				GET("/stream").
+				WithTimeout(10000),
+		},
+	}
+	start := time.Now()
+	err := NewRunner(nil).SetCaseTimeout(0.5).Run(testcase)
+	if !assert.True(t, errors.Is(err, code.TimeoutError)) || !assert.Less(t, time.Since(start), 5*time.Second) {
+		t.Fatal()
+	}
+}
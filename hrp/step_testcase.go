@@ -26,6 +26,16 @@ func (s *StepTestCaseWithOptionalArgs) Export(names ...string) *StepTestCaseWith
 	return s
 }
 
+// Foreach runs the referenced testcase once for each item of items,
+// each item is bound to variable named as in the iteration.
+func (s *StepTestCaseWithOptionalArgs) Foreach(items interface{}, as string) *StepTestCaseWithOptionalArgs {
+	s.step.Foreach = &TForeach{
+		Items: items,
+		As:    as,
+	}
+	return s
+}
+
 func (s *StepTestCaseWithOptionalArgs) Name() string {
 	if s.step.Name != "" {
 		return s.step.Name
@@ -15,6 +15,7 @@ import (
 	"github.com/httprunner/httprunner/v4/hrp/internal/builtin"
 	"github.com/httprunner/httprunner/v4/hrp/internal/env"
 	"github.com/httprunner/httprunner/v4/hrp/internal/version"
+	"github.com/httprunner/httprunner/v4/hrp/pkg/jsonschema"
 )
 
 func newOutSummary() *Summary {
@@ -41,6 +42,8 @@ type Summary struct {
 	Platform *Platform          `json:"platform" yaml:"platform"`
 	Details  []*TestCaseSummary `json:"details" yaml:"details"`
 	rootDir  string
+	// report options of runner
+	allureDir string // dir to export allure results, default to allure-results in reports dir
 }
 
 func (s *Summary) appendCaseSummary(caseSummary *TestCaseSummary) {
@@ -54,6 +57,7 @@ func (s *Summary) appendCaseSummary(caseSummary *TestCaseSummary) {
 	}
 	s.Stat.TestSteps.Successes += caseSummary.Stat.Successes
 	s.Stat.TestSteps.Failures += caseSummary.Stat.Failures
+	s.Stat.TestSteps.Skipped += caseSummary.Stat.Skipped
 	s.Details = append(s.Details, caseSummary)
 	s.Success = s.Success && caseSummary.Success
 
@@ -129,6 +133,7 @@ type TestStepStat struct {
 	Total     int `json:"total" yaml:"total"`
 	Successes int `json:"successes" yaml:"successes"`
 	Failures  int `json:"failures" yaml:"failures"`
+	Skipped   int `json:"skipped" yaml:"skipped"`
 }
 
 type TestCaseTime struct {
@@ -170,6 +175,8 @@ func newSessionData() *SessionData {
 type SessionData struct {
 	Success    bool                `json:"success" yaml:"success"`
 	ReqResps   *ReqResps           `json:"req_resps" yaml:"req_resps"`
+	Retries    []*ReqResps         `json:"retries,omitempty" yaml:"retries,omitempty"` // requests and responses of failed attempts before ReqResps
+	OAuth2     []*ReqResps         `json:"oauth2,omitempty" yaml:"oauth2,omitempty"`   // OAuth2 token endpoint exchanges made for the step
 	Address    *Address            `json:"address,omitempty" yaml:"address,omitempty"` // TODO
 	Validators []*ValidationResult `json:"validators,omitempty" yaml:"validators,omitempty"`
 }
@@ -188,8 +195,9 @@ type Address struct {
 
 type ValidationResult struct {
 	Validator
-	CheckValue  interface{} `json:"check_value" yaml:"check_value"`
-	CheckResult string      `json:"check_result" yaml:"check_result"`
+	CheckValue  interface{}             `json:"check_value" yaml:"check_value"`
+	CheckResult string                  `json:"check_result" yaml:"check_result"`
+	Violations  []*jsonschema.Violation `json:"violations,omitempty" yaml:"violations,omitempty"` // JSON schema violations
 }
 
 func newSummary() *TestCaseSummary {
@@ -114,6 +114,20 @@ func (tc *TCase) MakeCompat() (err error) {
 		// 3. deal with extract expr including hyphen
 		convertExtract(step.Extract)
 
+		// deal with retry/wait_until validators compatibility
+		if step.Retry != nil {
+			err = convertCompatValidator(step.Retry.Until)
+			if err != nil {
+				return err
+			}
+		}
+		if step.WaitUntil != nil {
+			err = convertCompatValidator(step.WaitUntil.Validators)
+			if err != nil {
+				return err
+			}
+		}
+
 		// 4. deal with mobile step compatibility
 		if step.Android != nil {
 			convertCompatMobileStep(step.Android)
@@ -274,6 +288,14 @@ func (tc *TCase) toTestCase() (*TestCase, error) {
 			testCase.TestSteps = append(testCase.TestSteps, &StepWebSocket{
 				step: step,
 			})
+		} else if step.SSE != nil {
+			testCase.TestSteps = append(testCase.TestSteps, &StepSSE{
+				step: step,
+			})
+		} else if step.GRPC != nil {
+			testCase.TestSteps = append(testCase.TestSteps, &StepGRPC{
+				step: step,
+			})
 		} else if step.IOS != nil {
 			testCase.TestSteps = append(testCase.TestSteps, &StepMobile{
 				step: step,
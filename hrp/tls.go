@@ -0,0 +1,170 @@
+package hrp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// TLSConfig represents TLS settings of HTTPS requests, e.g. custom CA bundle and client certificate for mutual TLS.
+// file paths are relative to project root dir.
+type TLSConfig struct {
+	CACert     string `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty"`         // PEM CA bundle to verify server certificate, verification is enabled if specified
+	ClientCert string `json:"client_cert,omitempty" yaml:"client_cert,omitempty"` // PEM client certificate, or PKCS#12 (.p12/.pfx) bundle
+	ClientKey  string `json:"client_key,omitempty" yaml:"client_key,omitempty"`   // PEM client private key, not required for PKCS#12 bundle
+	Password   string `json:"password,omitempty" yaml:"password,omitempty"`       // password of PKCS#12 bundle
+	ServerName string `json:"server_name,omitempty" yaml:"server_name,omitempty"` // server name to verify certificate and send in SNI
+}
+
+// mergeTLSConfig merges step TLS settings into config TLS settings, step settings take precedence.
+func mergeTLSConfig(config, step *TLSConfig) *TLSConfig {
+	if config == nil && step == nil {
+		return nil
+	}
+	merged := &TLSConfig{}
+	for _, c := range []*TLSConfig{config, step} {
+		if c == nil {
+			continue
+		}
+		if c.CACert != "" {
+			merged.CACert = c.CACert
+		}
+		if c.ClientCert != "" {
+			// client certificate and key are paired
+			merged.ClientCert = c.ClientCert
+			merged.ClientKey = c.ClientKey
+			merged.Password = c.Password
+		}
+		if c.ServerName != "" {
+			merged.ServerName = c.ServerName
+		}
+	}
+	return merged
+}
+
+// parse parses variables and functions in TLS settings, e.g. password: ${ENV(P12_PASSWORD)}
+func (c *TLSConfig) parse(parser *Parser, variables map[string]interface{}) (*TLSConfig, error) {
+	if c == nil {
+		return nil, nil
+	}
+	parsed := *c
+	for _, field := range []*string{
+		&parsed.CACert, &parsed.ClientCert, &parsed.ClientKey, &parsed.Password, &parsed.ServerName,
+	} {
+		if *field == "" {
+			continue
+		}
+		value, err := parser.ParseString(*field, variables)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse tls config failed")
+		}
+		*field = convertString(value)
+	}
+	return &parsed, nil
+}
+
+// isEmpty returns true if TLS settings do not differ from default.
+func (c *TLSConfig) isEmpty() bool {
+	return c == nil || *c == TLSConfig{}
+}
+
+// load creates tls.Config with file paths resolved relative to rootDir,
+// server certificate is verified if verify is true or CA bundle is specified.
+func (c *TLSConfig) load(rootDir string, verify bool) (*tls.Config, error) {
+	if c == nil {
+		c = &TLSConfig{}
+	}
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !verify && c.CACert == "",
+		ServerName:         c.ServerName,
+	}
+
+	if c.CACert != "" {
+		content, err := os.ReadFile(resolvePath(rootDir, c.CACert))
+		if err != nil {
+			return nil, errors.Wrap(err, "read ca cert failed")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, errors.Errorf("no valid PEM certificate found in ca cert %s", c.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCert != "" {
+		cert, err := c.loadClientCert(rootDir)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// loadClientCert loads client certificate from PEM files or PKCS#12 bundle.
+func (c *TLSConfig) loadClientCert(rootDir string) (tls.Certificate, error) {
+	certPath := resolvePath(rootDir, c.ClientCert)
+	content, err := os.ReadFile(certPath)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "read client cert failed")
+	}
+
+	ext := strings.ToLower(filepath.Ext(certPath))
+	if ext == ".p12" || ext == ".pfx" || (c.ClientKey == "" && !isPEM(content)) {
+		privateKey, cert, caCerts, err := pkcs12.DecodeChain(content, c.Password)
+		if err != nil {
+			return tls.Certificate{}, errors.Wrapf(err, "decode pkcs12 client cert %s failed", c.ClientCert)
+		}
+		tlsCert := tls.Certificate{
+			Certificate: [][]byte{cert.Raw},
+			PrivateKey:  privateKey,
+			Leaf:        cert,
+		}
+		for _, caCert := range caCerts {
+			tlsCert.Certificate = append(tlsCert.Certificate, caCert.Raw)
+		}
+		return tlsCert, nil
+	}
+
+	// PEM certificate and private key, private key may be in the same file with certificate
+	keyContent := content
+	if c.ClientKey != "" {
+		keyContent, err = os.ReadFile(resolvePath(rootDir, c.ClientKey))
+		if err != nil {
+			return tls.Certificate{}, errors.Wrap(err, "read client key failed")
+		}
+	}
+	tlsCert, err := tls.X509KeyPair(content, keyContent)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(err, "load client cert %s failed", c.ClientCert)
+	}
+	return tlsCert, nil
+}
+
+func isPEM(content []byte) bool {
+	block, _ := pem.Decode(content)
+	return block != nil
+}
+
+func resolvePath(rootDir, path string) string {
+	if filepath.IsAbs(path) || rootDir == "" {
+		return path
+	}
+	return filepath.Join(rootDir, path)
+}
+
+func resolvePathIfSet(rootDir, path string) string {
+	if path == "" {
+		return ""
+	}
+	return resolvePath(rootDir, path)
+}
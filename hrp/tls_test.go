@@ -0,0 +1,128 @@
+package hrp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMTLSServer(t *testing.T, tlsDir string) *httptest.Server {
+	serverCert, err := tls.LoadX509KeyPair(
+		filepath.Join(tlsDir, "server.pem"), filepath.Join(tlsDir, "server.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := os.ReadFile(filepath.Join(tlsDir, "ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caCert)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	ts.StartTLS()
+	return ts
+}
+
+func TestRunRequestWithMutualTLS(t *testing.T) {
+	tlsDir, _ := filepath.Abs("../examples/data/tls")
+	ts := newMTLSServer(t, tlsDir)
+	defer ts.Close()
+
+	stepMTLS := func(name string) *StepRequestValidation {
+		return NewStep(name).
+			GET("/").
+			Validate().
+			AssertEqual("status_code", 200, "check status code").
+			AssertEqual("body", "hrp client", "check client cert")
+	}
+	testcases := []struct {
+		name    string
+		config  *TConfig
+		steps   []IStep
+		success bool
+	}{
+		{
+			name:    "missing client cert",
+			config:  NewConfig("mtls").SetBaseURL(ts.URL),
+			steps:   []IStep{stepMTLS("request")},
+			success: false,
+		},
+		{
+			name: "PEM client cert in config",
+			config: NewConfig("mtls").SetBaseURL(ts.URL).SetTLS(&TLSConfig{
+				CACert:     filepath.Join(tlsDir, "ca.pem"),
+				ClientCert: filepath.Join(tlsDir, "client.pem"),
+				ClientKey:  filepath.Join(tlsDir, "client.key"),
+			}),
+			steps:   []IStep{stepMTLS("request")},
+			success: true,
+		},
+		{
+			name: "PKCS#12 client cert in step",
+			config: NewConfig("mtls").SetBaseURL(ts.URL).SetTLS(&TLSConfig{
+				CACert: filepath.Join(tlsDir, "ca.pem"),
+			}),
+			steps: []IStep{
+				NewStep("request").
+					WithVariables(map[string]interface{}{"p12_password": "hrp"}).
+					GET("/").
+					SetTLS(&TLSConfig{
+						ClientCert: filepath.Join(tlsDir, "client.p12"),
+						Password:   "$p12_password",
+					}).
+					Validate().
+					AssertEqual("body", "hrp client", "check client cert"),
+			},
+			success: true,
+		},
+		{
+			name: "verify server cert without CA bundle",
+			config: NewConfig("mtls").SetBaseURL(ts.URL).SetVerifySSL(true).SetTLS(&TLSConfig{
+				ClientCert: filepath.Join(tlsDir, "client.p12"),
+				Password:   "hrp",
+			}),
+			steps:   []IStep{stepMTLS("request")},
+			success: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		testcase := &TestCase{
+			Config:    tc.config,
+			TestSteps: tc.steps,
+		}
+		err := NewRunner(t).Run(testcase)
+		if !assert.Equal(t, tc.success, err == nil, tc.name) {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMergeTLSConfig(t *testing.T) {
+	config := &TLSConfig{CACert: "ca.pem", ClientCert: "client.pem", ClientKey: "client.key"}
+	step := &TLSConfig{ClientCert: "client.p12", Password: "hrp"}
+	if !assert.Equal(t, &TLSConfig{
+		CACert:     "ca.pem",
+		ClientCert: "client.p12",
+		Password:   "hrp",
+	}, mergeTLSConfig(config, step)) {
+		t.Fatal()
+	}
+	if !assert.Nil(t, mergeTLSConfig(nil, nil)) {
+		t.Fatal()
+	}
+}